@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nikzadkhani/axe/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the PR status cache",
+	Long: `Axe caches PR lookups per branch tip so repeat scans only ask GitHub
+about branches that changed. Merged PRs are cached permanently, closed PRs
+for 30 days, and open or missing PRs for 10 minutes.
+
+The cache lives in .git/axe/cache inside each repository.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached PR lookups",
+	RunE:  runCacheClear,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show what the PR cache contains",
+	RunE:  runCacheStats,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+}
+
+// openRepoCache opens the PR cache of the repository selected by --repo
+func openRepoCache(cmd *cobra.Command) (*cache.Store, error) {
+	repoPath, _ := cmd.Flags().GetString("repo")
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		return nil, err
+	}
+
+	gitDir, err := gitClient.GetGitDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.Open(cache.DefaultPath(gitDir), cache.DefaultTTLs())
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	formatter := newFormatter(cmd)
+
+	store, err := openRepoCache(cmd)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	entries := store.Stats().Entries
+	if err := store.Clear(); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	formatter.PrintSuccess(fmt.Sprintf("Cleared %d cached PR lookup(s)", entries))
+	return nil
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	formatter := newFormatter(cmd)
+
+	store, err := openRepoCache(cmd)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	stats := store.Stats()
+	formatter.PrintHeader("🪓 PR cache")
+	formatter.PrintInfo(fmt.Sprintf("Location: %s", stats.Path))
+	formatter.PrintInfo(fmt.Sprintf("Entries: %d (%d expired)", stats.Entries, stats.Expired))
+	formatter.PrintInfo(fmt.Sprintf("Size: %d bytes", stats.Size))
+
+	states := make([]string, 0, len(stats.ByState))
+	for state := range stats.ByState {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	for _, state := range states {
+		formatter.PrintBranch(fmt.Sprintf("%s: %d", state, stats.ByState[state]))
+	}
+
+	return nil
+}
@@ -3,12 +3,17 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/nikzadkhani/axe/pkg/branch"
 	"github.com/nikzadkhani/axe/pkg/git"
-	"github.com/nikzadkhani/axe/pkg/github"
 	"github.com/nikzadkhani/axe/pkg/output"
 	"github.com/nikzadkhani/axe/pkg/progress"
+	"github.com/nikzadkhani/axe/pkg/script"
+	"github.com/nikzadkhani/axe/pkg/tui"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +26,19 @@ but are still taking up space locally.
 
 This command checks GitHub for merged PRs before swinging the axe.
 Branches are force-deleted since squash-merged commits don't show up
-in git's history.`,
+in git's history.
+
+With --include closed, branches whose PR was closed without merging are
+chopped too, with --include gone, branches that were deleted on the
+remote but have no PR, and with --include reverted, branches whose merge
+was reverted on the default branch. They are confirmed separately, and a
+recovery point is saved under refs/axe/recovery/ before any of them are
+deleted.
+
+With --wait, branches whose PR is in a merge queue or has auto-merge
+enabled are polled until the PR merges, up to --wait-timeout, and chopped
+along with the merged branches. PRs still queued when it runs out are left
+alone.`,
 	RunE: runClean,
 }
 
@@ -29,32 +46,80 @@ func init() {
 	rootCmd.AddCommand(cleanCmd)
 	cleanCmd.Flags().BoolP("dry-run", "n", false, "Show what would be chopped without actually chopping")
 	cleanCmd.Flags().BoolP("force", "f", false, "Skip confirmation and start chopping")
+	cleanCmd.Flags().BoolP("interactive", "i", false, "Choose which branches to chop in a full-screen picker")
+	cleanCmd.Flags().Bool("confirm-each-repo", false, "With --recursive, confirm once per repository instead of once overall")
+	cleanCmd.Flags().StringSlice("include", nil, "Also chop branches in these extra categories (closed, gone, reverted)")
+	cleanCmd.Flags().String("closed-older-than", "", "With --include closed, only chop branches whose PR closed longer ago than this (e.g. 60d)")
+	cleanCmd.Flags().String("emit-script", "", "Write the git commands that would chop the branches as a script for this shell ("+strings.Join(script.Shells, ", ")+") instead of chopping them")
+	cleanCmd.Flags().Bool("wait", false, "Wait for PRs in a merge queue or with auto-merge enabled to merge, then chop them too")
+	cleanCmd.Flags().String("wait-timeout", "30m", "With --wait, stop waiting for queued PRs after this long (e.g. 45m, 2h)")
+	cleanCmd.Flags().Bool("per-branch", false, "Chop branches one at a time instead of in one transaction, so one failure doesn't stop the rest")
+	addLookupFlags(cleanCmd)
+	addFilterFlags(cleanCmd)
+	addRecursiveFlags(cleanCmd)
 }
 
 func runClean(cmd *cobra.Command, args []string) error {
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	force, _ := cmd.Flags().GetBool("force")
+	interactive, _ := cmd.Flags().GetBool("interactive")
 	repoPath, _ := cmd.Flags().GetString("repo")
 
 	if repoPath == "" {
 		repoPath = "."
 	}
 
-	// Create dependencies
-	gitClient := git.NewDefaultClient()
-	githubClient := github.NewDefaultClient()
-	branchService := branch.NewService(gitClient, githubClient)
-
-	// Create formatter based on --no-color flag
-	noColor, _ := cmd.Flags().GetBool("no-color")
-	var formatter output.Formatter
-	if noColor {
-		formatter = output.NewPlainFormatter(os.Stdout)
-	} else {
-		formatter = output.NewColoredFormatter(os.Stdout)
+	include, err := includeFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	wait, _ := cmd.Flags().GetBool("wait")
+	waitTimeout, err := waitTimeoutFlag(cmd, wait)
+	if err != nil {
+		return err
+	}
+
+	emitShell, _ := cmd.Flags().GetString("emit-script")
+	if emitShell != "" {
+		if err := script.ValidateShell(emitShell); err != nil {
+			return err
+		}
+		if interactive {
+			return fmt.Errorf("--emit-script can't be combined with --interactive")
+		}
+	}
+
+	if recursive, _ := cmd.Flags().GetBool("recursive"); recursive {
+		if interactive {
+			return fmt.Errorf("--interactive can't be combined with --recursive")
+		}
+		if include.any() {
+			return fmt.Errorf("--include can't be combined with --recursive")
+		}
+		if emitShell != "" {
+			return fmt.Errorf("--emit-script can't be combined with --recursive")
+		}
+		if wait {
+			return fmt.Errorf("--wait can't be combined with --recursive")
+		}
+		return runCleanRecursive(cmd, repoPath)
 	}
 
-	reporter := progress.NewSpinnerReporter(os.Stdout)
+	// The script goes to stdout, so everything else goes to stderr
+	out := os.Stdout
+	if emitShell != "" {
+		out = os.Stderr
+	}
+
+	// Create dependencies
+	formatter := newFormatterTo(cmd, out)
+	reporter := progress.NewSpinnerReporter(out)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
 
 	// Validate repository
 	if err := gitClient.ValidateRepository(repoPath); err != nil {
@@ -62,59 +127,220 @@ func runClean(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	branchService, saveCache, err := newBranchService(cmd, gitClient, repoPath, formatter)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	defer saveCache()
+
 	// Get merged branches
-	mergedBranches, err := branchService.GetMergedBranches(repoPath, reporter)
+	mergedBranches, keptBranches, err := branchService.GetMergedAndKeptBranches(repoPath, reporter)
 	if err != nil {
 		formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
 		return err
 	}
 
+	// Queued PRs that merge in time join the merged branches
+	if wait {
+		var skip []string
+		for _, mb := range mergedBranches {
+			skip = append(skip, mb.Name)
+		}
+		queued, err := branchService.GetQueuedBranches(repoPath, skip, reporter)
+		if err != nil {
+			formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+			return err
+		}
+		if len(queued) > 0 {
+			landed, left := branchService.WaitForQueued(repoPath, queued, waitTimeout, reporter)
+			mergedBranches = append(mergedBranches, landed...)
+			printUnlandedBranches(formatter, left)
+		}
+	}
+
+	// Extract branch names
+	var branchNames []string
+	for _, mb := range mergedBranches {
+		branchNames = append(branchNames, mb.Name)
+	}
+
+	// Branches already found merged are never offered again as unmerged
+	var extraBranches []branch.BranchStatus
+	if include.closed {
+		closed, err := branchService.GetClosedBranches(repoPath, include.closedOlderThan, branchNames, reporter)
+		if err != nil {
+			formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+			return err
+		}
+		extraBranches = append(extraBranches, closed...)
+	}
+	if include.gone {
+		skip := slices.Clone(branchNames)
+		for _, bs := range extraBranches {
+			skip = append(skip, bs.Name)
+		}
+		gone, err := branchService.GetGoneBranches(repoPath, skip, reporter)
+		if err != nil {
+			formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+			return err
+		}
+		extraBranches = append(extraBranches, gone...)
+	}
+	if include.reverted {
+		skip := slices.Clone(branchNames)
+		for _, bs := range extraBranches {
+			skip = append(skip, bs.Name)
+		}
+		reverted, err := branchService.GetRevertedBranches(repoPath, skip, reporter)
+		if err != nil {
+			formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+			return err
+		}
+		extraBranches = append(extraBranches, reverted...)
+	}
+
+	if emitShell != "" {
+		return emitScript(emitShell, repoPath, mergedBranches, extraBranches, formatter)
+	}
+
 	fmt.Println() // Add spacing after spinner
 
-	if len(mergedBranches) == 0 {
+	printKeptBranches(formatter, keptBranches)
+	printServiceWarnings(formatter, branchService)
+	result := outcome{failedLookups: printFailedLookups(formatter, branchService)}
+
+	if len(mergedBranches) == 0 && len(extraBranches) == 0 {
 		formatter.PrintInfo("No branches to chop! All clean 🪓")
-		return nil
+		return finish(cmd, result)
 	}
 
-	// Display what will be chopped
-	formatter.PrintHeader(fmt.Sprintf("🪓 Found %d branch(es) ready to chop:", len(mergedBranches)))
-	for _, mb := range mergedBranches {
-		formatter.PrintBranch(mb.Name)
+	var extraNames []string
+	for _, bs := range extraBranches {
+		extraNames = append(extraNames, bs.Name)
 	}
-	fmt.Println()
 
-	if dryRun {
-		formatter.PrintWarning("Dry run - no branches were chopped")
-		return nil
+	// The picker replaces both the listing and the confirmation prompt
+	if interactive && !dryRun && !tui.IsTerminal(os.Stdin) {
+		formatter.PrintWarning("Input is not a terminal, falling back to the confirmation prompt")
+		interactive = false
 	}
 
-	// Confirm deletion unless force flag is set
-	if !force {
-		fmt.Print("🪓 Chop these branches? [y/N]: ")
-		var response string
-		fmt.Scanln(&response)
-		if response != "y" && response != "Y" {
+	if interactive && !dryRun {
+		selected, ok, err := pickBranches(gitClient, repoPath, mergedBranches, extraBranches)
+		if err != nil {
+			formatter.PrintError(err.Error())
+			return err
+		}
+		if !ok || len(selected) == 0 {
+			formatter.PrintInfo("Cancelled. No branches were chopped.")
+			return finish(cmd, result)
+		}
+		var pickedExtra []string
+		branchNames = nil
+		for _, name := range selected {
+			if slices.Contains(extraNames, name) {
+				pickedExtra = append(pickedExtra, name)
+			} else {
+				branchNames = append(branchNames, name)
+			}
+		}
+		extraNames = pickedExtra
+		if !force && len(extraNames) > 0 && !confirmTyped(fmt.Sprintf("⚠️  %d selected branch(es) were never merged. Type \"yes\" to chop them too: ", len(extraNames)), "yes") {
+			extraNames = nil
+		}
+		if len(branchNames) == 0 && len(extraNames) == 0 {
+			formatter.PrintInfo("Cancelled. No branches were chopped.")
+			return finish(cmd, result)
+		}
+	} else {
+		// Display what will be chopped
+		if len(mergedBranches) > 0 {
+			formatter.PrintHeader(fmt.Sprintf("🪓 Found %d branch(es) ready to chop:", len(mergedBranches)))
+			for _, mb := range mergedBranches {
+				formatter.PrintBranch(mb.Name)
+			}
+			fmt.Println()
+		}
+		if len(extraBranches) > 0 {
+			printUnmergedBranches(formatter, extraBranches)
+			formatter.PrintWarning("Their changes aren't on the default branch. A recovery point is saved before they are chopped.")
+			fmt.Println()
+		}
+
+		if dryRun {
+			formatter.PrintWarning("Dry run - no branches were chopped")
+			return finish(cmd, result)
+		}
+
+		// Confirm deletion unless force flag is set. Closed branches need
+		// their own, stronger confirmation.
+		if !force && len(branchNames) > 0 && !confirm("🪓 Chop these branches? [y/N]: ") {
+			branchNames = nil
+		}
+		if !force && len(extraNames) > 0 && !confirmTyped(fmt.Sprintf("⚠️  Type \"yes\" to also chop %d unmerged branch(es): ", len(extraNames)), "yes") {
+			extraNames = nil
+		}
+		if len(branchNames) == 0 && len(extraNames) == 0 {
 			formatter.PrintInfo("Cancelled. No branches were chopped.")
-			return nil
+			return finish(cmd, result)
 		}
 	}
 
-	// Extract branch names
-	var branchNames []string
+	// Unmerged work is only ever deleted behind a recovery point
+	var recoveryPrefix string
+	if len(extraNames) > 0 {
+		recoveryPrefix, err = branchService.CreateRecoveryPoint(repoPath, extraNames)
+		if err != nil {
+			formatter.PrintError(fmt.Sprintf("%v; not chopping unmerged branches", err))
+			result.failed += len(extraNames)
+			extraNames = nil
+		} else {
+			branchNames = append(branchNames, extraNames...)
+		}
+	}
+	if len(branchNames) == 0 {
+		return finish(cmd, result)
+	}
+
+	// Delete branches, guarded by the tips they were scanned at
+	scanned := make(map[string]git.Branch)
 	for _, mb := range mergedBranches {
-		branchNames = append(branchNames, mb.Name)
+		scanned[mb.Name] = mb.Branch
+	}
+	for _, bs := range extraBranches {
+		scanned[bs.Name] = bs.Branch
+	}
+	var toDelete []git.Branch
+	for _, name := range branchNames {
+		toDelete = append(toDelete, scanned[name])
 	}
+	deleted, failed := branchService.DeleteBranches(repoPath, toDelete, reporter)
 
-	// Delete branches
-	deleted, failed := branchService.DeleteBranches(repoPath, branchNames, reporter)
+	printChopResults(formatter, deleted, failed)
+	result.failed += len(failed)
 
-	// Display results
+	if recoveryPrefix != "" {
+		formatter.PrintInfo(fmt.Sprintf("Recovery point saved under %s. To restore a branch run:", recoveryPrefix))
+		for _, name := range extraNames {
+			if slices.Contains(deleted, name) {
+				formatter.PrintInfo(fmt.Sprintf("  git branch %s %s/%s", name, recoveryPrefix, name))
+			}
+		}
+	}
+
+	return finish(cmd, result)
+}
+
+// printChopResults lists the branches that were chopped and those that
+// failed, followed by a summary
+func printChopResults(formatter output.Formatter, deleted []string, failed []branch.DeleteFailure) {
 	fmt.Println()
 	for _, branch := range deleted {
 		formatter.PrintSuccess(fmt.Sprintf("Chopped: %s", branch))
 	}
-	for _, branch := range failed {
-		formatter.PrintError(fmt.Sprintf("Failed to chop: %s", branch))
+	for _, f := range failed {
+		formatter.PrintError(fmt.Sprintf("Failed to chop: %s (%s)", f.Branch, f.Reason))
 	}
 
 	fmt.Println()
@@ -123,6 +349,182 @@ func runClean(cmd *cobra.Command, args []string) error {
 	} else {
 		formatter.PrintSuccess(fmt.Sprintf("🪓 Chopped %d branch(es)!", len(deleted)))
 	}
+}
+
+// includeOptions are the opt-in categories of unmerged branches to chop
+type includeOptions struct {
+	closed          bool
+	closedOlderThan time.Duration
+	gone            bool
+	reverted        bool
+}
+
+func (o includeOptions) any() bool {
+	return o.closed || o.gone || o.reverted
+}
+
+// includeFlags parses --include and --closed-older-than
+func includeFlags(cmd *cobra.Command) (includeOptions, error) {
+	var opts includeOptions
+	include, _ := cmd.Flags().GetStringSlice("include")
+	for _, category := range include {
+		switch category {
+		case "closed":
+			opts.closed = true
+		case "gone":
+			opts.gone = true
+		case "reverted":
+			opts.reverted = true
+		default:
+			return opts, fmt.Errorf("unknown --include category %q (valid: closed, gone, reverted)", category)
+		}
+	}
+
+	olderThan, _ := cmd.Flags().GetString("closed-older-than")
+	if olderThan == "" {
+		return opts, nil
+	}
+	if !opts.closed {
+		return opts, fmt.Errorf("--closed-older-than requires --include closed")
+	}
+	age, err := branch.ParseAge(olderThan)
+	if err != nil {
+		return opts, err
+	}
+	opts.closedOlderThan = age
+	return opts, nil
+}
+
+// printKeptBranches lists merged branches that a keep marker or label
+// protects, with the reason, so it's clear why they aren't chopped
+func printKeptBranches(formatter output.Formatter, kept []branch.MergedBranch) {
+	if len(kept) == 0 {
+		return
+	}
+	formatter.PrintInfo(fmt.Sprintf("🛡️  Keeping %d merged branch(es):", len(kept)))
+	for _, mb := range kept {
+		formatter.PrintInfo(fmt.Sprintf("  %s (%s)", mb.Name, mb.Kept))
+	}
+}
+
+// waitTimeoutFlag parses --wait-timeout, which only applies with --wait
+func waitTimeoutFlag(cmd *cobra.Command, wait bool) (time.Duration, error) {
+	timeout, _ := cmd.Flags().GetString("wait-timeout")
+	if !wait {
+		if cmd.Flags().Changed("wait-timeout") {
+			return 0, fmt.Errorf("--wait-timeout requires --wait")
+		}
+		return 0, nil
+	}
+	return branch.ParseAge(timeout)
+}
 
+// printUnlandedBranches lists the queued branches that didn't merge while
+// chop waited, and why, so it's clear why they weren't chopped
+func printUnlandedBranches(formatter output.Formatter, branches []branch.BranchStatus) {
+	if len(branches) == 0 {
+		return
+	}
+	formatter.PrintWarning(fmt.Sprintf("⏳ %d queued branch(es) weren't merged in time and are kept:", len(branches)))
+	for _, bs := range branches {
+		reason := bs.Status
+		if bs.Status == "queued" {
+			reason = "still queued"
+		}
+		formatter.PrintInfo(fmt.Sprintf("  %s (%s)", bs.Name, reason))
+	}
+}
+
+// printUnmergedBranches lists opt-in unmerged branches grouped by category
+func printUnmergedBranches(formatter output.Formatter, branches []branch.BranchStatus) {
+	sections := []struct {
+		status string
+		label  string
+	}{
+		{"closed", "whose PR was closed WITHOUT merging"},
+		{"gone", "deleted on the remote WITHOUT a PR"},
+		{"merged-reverted", "whose merge was REVERTED"},
+	}
+	for _, section := range sections {
+		var matching []branch.BranchStatus
+		for _, bs := range branches {
+			if bs.Status == section.status {
+				matching = append(matching, bs)
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+		formatter.PrintWarning(fmt.Sprintf("⚠️  Found %d branch(es) %s:", len(matching), section.label))
+		for _, bs := range matching {
+			if bs.PR != nil {
+				formatter.PrintBranchWithPR(bs.Name, bs.PR)
+			} else {
+				formatter.PrintBranch(bs.Name)
+			}
+		}
+	}
+}
+
+// pickBranches lets the user choose branches in the interactive picker.
+// Merged branches start selected, unmerged ones must be picked explicitly.
+func pickBranches(gitClient git.Client, repoPath string, mergedBranches []branch.MergedBranch, extraBranches []branch.BranchStatus) ([]string, bool, error) {
+	items := make([]tui.Item, 0, len(mergedBranches)+len(extraBranches))
+	for _, mb := range mergedBranches {
+		items = append(items, tui.Item{Name: mb.Name, Status: "merged", PR: mb.PR, LastCommit: mb.CommitDate, Selected: true})
+	}
+	for _, bs := range extraBranches {
+		items = append(items, tui.Item{Name: bs.Name, Status: bs.Status, PR: bs.PR, LastCommit: bs.CommitDate})
+	}
+
+	// Show the branch's own commits when the default branch is known
+	defaultBranch, _ := gitClient.GetDefaultBranch(repoPath)
+	load := func(name string) ([]git.Commit, error) {
+		return gitClient.GetCommits(repoPath, name, defaultBranch, 20)
+	}
+
+	return tui.NewPicker(items, load).Run(os.Stdin, os.Stdout)
+}
+
+// emitScript writes the git commands that would chop the branches to stdout.
+// Unmerged branches are saved to a recovery point first, as chop does.
+func emitScript(shell, repoPath string, mergedBranches []branch.MergedBranch, extraBranches []branch.BranchStatus, formatter output.Formatter) error {
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	now := time.Now()
+	var deletions []script.Deletion
+	for _, mb := range mergedBranches {
+		deletions = append(deletions, script.Deletion{Branch: mb.Branch, PR: mb.PR, MergedBy: mb.MergedBy})
+	}
+	recoveryPoint := branch.RecoveryPoint(now)
+	for _, bs := range extraBranches {
+		deletions = append(deletions, script.Deletion{Branch: bs.Branch, PR: bs.PR, RecoveryRef: recoveryPoint + "/" + bs.Name})
+	}
+
+	if err := script.Write(os.Stdout, shell, absPath, deletions, now); err != nil {
+		formatter.PrintError(fmt.Sprintf("Failed to write script: %v", err))
+		return err
+	}
+	formatter.PrintSuccess(fmt.Sprintf("Wrote a %s script to chop %d branch(es)", shell, len(deletions)))
 	return nil
 }
+
+// confirm asks a yes/no question and reports whether the user answered yes
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	var response string
+	fmt.Scanln(&response)
+	return response == "y" || response == "Y"
+}
+
+// confirmTyped asks the user to type word exactly to proceed
+func confirmTyped(prompt, word string) bool {
+	fmt.Print(prompt)
+	var response string
+	fmt.Scanln(&response)
+	return response == word
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, so scripts can tell outcomes apart. When several apply, the
+// first one in this order wins: error, partial failure, failed lookups,
+// candidates found.
+const (
+	// ExitClean means the command did everything it set out to do
+	ExitClean = 0
+	// ExitError means the command failed
+	ExitError = 1
+	// ExitCandidates means --check found branches ready to chop
+	ExitCandidates = 2
+	// ExitPartialFailure means some branches or repositories failed while
+	// the rest succeeded
+	ExitPartialFailure = 3
+	// ExitLookupFailures means some PR lookups failed, so branches may be
+	// missing from the results
+	ExitLookupFailures = 4
+)
+
+// exitStatus ends axe with an exit code once the command has reported why
+type exitStatus struct {
+	code int
+}
+
+func (e *exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// outcome is what a command found and what went wrong, which decides its
+// exit code
+type outcome struct {
+	// check makes finding candidates an exit code of its own
+	check      bool
+	candidates int
+	// failed counts branches or repositories that failed
+	failed int
+	// failedLookups counts PR lookups that failed
+	failedLookups int
+}
+
+func (o outcome) code() int {
+	switch {
+	case o.failed > 0:
+		return ExitPartialFailure
+	case o.failedLookups > 0:
+		return ExitLookupFailures
+	case o.check && o.candidates > 0:
+		return ExitCandidates
+	default:
+		return ExitClean
+	}
+}
+
+// finish ends a command with the exit code for its outcome. The command has
+// already reported everything, so cobra prints nothing more.
+func finish(cmd *cobra.Command, o outcome) error {
+	code := o.code()
+	if code == ExitClean {
+		return nil
+	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitStatus{code: code}
+}
+
+// printServiceWarnings prints what the branch service couldn't check
+func printServiceWarnings(formatter output.Formatter, service *branch.Service) {
+	for _, warning := range service.Warnings() {
+		formatter.PrintWarning(warning)
+	}
+}
+
+// printFailedLookups warns about branches whose PR couldn't be looked up and
+// returns how many there were
+func printFailedLookups(formatter output.Formatter, service *branch.Service) int {
+	failed := service.FailedLookups()
+	if len(failed) == 0 {
+		return 0
+	}
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	formatter.PrintWarning(fmt.Sprintf("Couldn't look up PRs for %d branch(es), so the results may be incomplete: %s (%v)",
+		len(names), strings.Join(names, ", "), failed[names[0]]))
+	return len(names)
+}
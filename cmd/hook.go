@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nikzadkhani/axe/pkg/hook"
+	"github.com/nikzadkhani/axe/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Run axe from git hooks after pulls and merges",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install [post-merge|post-checkout|reference-transaction]...",
+	Short: "Add axe to the repository's git hooks",
+	Long: `Add axe to git hook scripts, so it runs after git pull. The default
+post-merge hook runs after every pull that merges; post-checkout also covers
+git pull --rebase, and reference-transaction runs after every fetch.
+
+With the default report policy the hook runs axe branches --quiet, which
+prints one line when branches are ready to chop. The chop policy runs
+axe chop --force instead.
+
+Hooks are written to core.hooksPath, or .git/hooks when it isn't set. An
+existing hook script keeps what it runs, and axe's lines are added after it
+between markers, so installing again only updates them. Repositories whose
+hooks are managed by husky or lefthook are left alone, and axe prints what
+to add to their config instead.`,
+	ValidArgs: hook.Events,
+	RunE:      runHookInstall,
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:       "uninstall [post-merge|post-checkout|reference-transaction]...",
+	Short:     "Remove axe from the repository's git hooks",
+	Long:      `Remove the lines axe hook install added, from every hook unless some are named. Hook scripts left with nothing to run are deleted.`,
+	ValidArgs: hook.Events,
+	RunE:      runHookUninstall,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookInstallCmd.Flags().String("policy", hook.PolicyReport, fmt.Sprintf("What the hook does with merged branches (%s)", strings.Join(hook.Policies, ", ")))
+}
+
+// hookTarget is where a repository's hooks live
+type hookTarget struct {
+	dir string
+	// manager is the hook manager that owns dir, or ""
+	manager string
+}
+
+// openHooks validates the events and finds the hooks of the repository
+// selected by --repo
+func openHooks(cmd *cobra.Command, events []string, formatter output.Formatter) (hookTarget, error) {
+	for _, event := range events {
+		if err := hook.ValidateEvent(event); err != nil {
+			return hookTarget{}, err
+		}
+	}
+
+	repoPath, _ := cmd.Flags().GetString("repo")
+	if repoPath == "" {
+		repoPath = "."
+	}
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return hookTarget{}, err
+	}
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		formatter.PrintError(err.Error())
+		return hookTarget{}, err
+	}
+
+	dir, err := gitClient.GetHooksDir(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return hookTarget{}, err
+	}
+	gitDir, err := gitClient.GetGitDir(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return hookTarget{}, err
+	}
+	return hookTarget{dir: dir, manager: hook.Detect(filepath.Dir(gitDir), dir)}, nil
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	policy, _ := cmd.Flags().GetString("policy")
+	command, err := hook.Command(policy)
+	if err != nil {
+		return err
+	}
+	events := args
+	if len(events) == 0 {
+		events = []string{hook.EventPostMerge}
+	}
+
+	formatter := newFormatter(cmd)
+	target, err := openHooks(cmd, events, formatter)
+	if err != nil {
+		return err
+	}
+
+	// The manager would overwrite or ignore scripts written behind its back
+	if target.manager != "" {
+		formatter.PrintWarning(fmt.Sprintf("This repository's hooks are managed by %s, so axe didn't change them", target.manager))
+		for _, event := range events {
+			file, snippet := hook.Snippet(target.manager, event, command)
+			formatter.PrintInfo(fmt.Sprintf("To run axe from %s, add this to %s:", event, file))
+			fmt.Println()
+			fmt.Print(snippet)
+			fmt.Println()
+		}
+		return nil
+	}
+
+	var failed int
+	for _, event := range events {
+		path := filepath.Join(target.dir, event)
+		changed, err := hook.Install(target.dir, event, command)
+		switch {
+		case err != nil:
+			formatter.PrintError(err.Error())
+			failed++
+		case changed:
+			formatter.PrintSuccess(fmt.Sprintf("Installed the %s hook in %s", event, path))
+		default:
+			formatter.PrintInfo(fmt.Sprintf("The %s hook in %s is already installed", event, path))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to install %d of %d hook(s)", failed, len(events))
+	}
+	return nil
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) error {
+	events := args
+	if len(events) == 0 {
+		events = hook.Events
+	}
+
+	formatter := newFormatter(cmd)
+	target, err := openHooks(cmd, events, formatter)
+	if err != nil {
+		return err
+	}
+
+	var removed, failed int
+	for _, event := range events {
+		changed, err := hook.Uninstall(target.dir, event)
+		if err != nil {
+			formatter.PrintError(err.Error())
+			failed++
+			continue
+		}
+		if changed {
+			formatter.PrintSuccess(fmt.Sprintf("Removed axe from the %s hook in %s", event, target.dir))
+			removed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to uninstall %d of %d hook(s)", failed, len(events))
+	}
+	if removed == 0 {
+		if target.manager != "" {
+			formatter.PrintInfo(fmt.Sprintf("No axe hooks found in %s. With %s, remove the axe lines from its config.", target.dir, target.manager))
+		} else {
+			formatter.PrintInfo(fmt.Sprintf("No axe hooks found in %s", target.dir))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"github.com/spf13/cobra"
+)
+
+var keepCmd = &cobra.Command{
+	Use:   "keep <branch>...",
+	Short: "Never chop these branches, even once they're merged",
+	Long: `Mark branches so axe never chops them, say because a merged branch is the
+basis for a backport. The mark is stored in the repository's git config as
+branch.<name>.axeKeep.
+
+With --until, the branch is only kept through that day. PRs carrying a keep
+label (keep-branch, see --keep-label) are kept the same way without a mark.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runKeep,
+}
+
+var unkeepCmd = &cobra.Command{
+	Use:   "unkeep <branch>...",
+	Short: "Let axe chop branches marked with axe keep again",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runUnkeep,
+}
+
+func init() {
+	rootCmd.AddCommand(keepCmd)
+	keepCmd.Flags().String("until", "", "Only keep the branches through this date (YYYY-MM-DD)")
+	rootCmd.AddCommand(unkeepCmd)
+}
+
+func runKeep(cmd *cobra.Command, args []string) error {
+	var until time.Time
+	if untilFlag, _ := cmd.Flags().GetString("until"); untilFlag != "" {
+		var err error
+		until, err = time.ParseInLocation(branch.KeepDateLayout, untilFlag, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --until date %q (use YYYY-MM-DD)", untilFlag)
+		}
+		if until.AddDate(0, 0, 1).Before(time.Now()) {
+			return fmt.Errorf("--until date %s is in the past", untilFlag)
+		}
+	}
+
+	return forEachKeepArg(cmd, args, func(service *branch.Service, repoPath, name string) (string, error) {
+		if err := service.Keep(repoPath, name, until); err != nil {
+			return "", err
+		}
+		if until.IsZero() {
+			return fmt.Sprintf("Keeping %s 🛡️", name), nil
+		}
+		return fmt.Sprintf("Keeping %s through %s 🛡️", name, until.Format(branch.KeepDateLayout)), nil
+	})
+}
+
+func runUnkeep(cmd *cobra.Command, args []string) error {
+	return forEachKeepArg(cmd, args, func(service *branch.Service, repoPath, name string) (string, error) {
+		if err := service.Unkeep(repoPath, name); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s can be chopped again", name), nil
+	})
+}
+
+// forEachKeepArg applies a keep or unkeep to every branch argument,
+// reporting each one, and fails if any of them failed
+func forEachKeepArg(cmd *cobra.Command, args []string, apply func(service *branch.Service, repoPath, name string) (string, error)) error {
+	repoPath, _ := cmd.Flags().GetString("repo")
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	formatter := newFormatter(cmd)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	service := branch.NewService(gitClient, github.NewDefaultClient())
+	var failed int
+	for _, name := range args {
+		msg, err := apply(service, repoPath, name)
+		if err != nil {
+			formatter.PrintError(err.Error())
+			failed++
+			continue
+		}
+		formatter.PrintSuccess(msg)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to update %d of %d branch(es)", failed, len(args))
+	}
+	return nil
+}
@@ -3,10 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/nikzadkhani/axe/pkg/branch"
-	"github.com/nikzadkhani/axe/pkg/git"
-	"github.com/nikzadkhani/axe/pkg/github"
 	"github.com/nikzadkhani/axe/pkg/output"
 	"github.com/nikzadkhani/axe/pkg/progress"
 	"github.com/spf13/cobra"
@@ -19,7 +18,11 @@ var listCmd = &cobra.Command{
 	Long: `Find all local Git branches that have been squash-merged on GitHub
 but are still hanging around locally.
 
-These branches are ready to be chopped! Use 'axe chop' to remove them.`,
+These branches are ready to be chopped! Use 'axe chop' to remove them.
+
+With --check, axe exits with status 2 when branches are ready to chop, so it
+can gate a pre-push hook or a CI job. --quiet prints a single line, and only
+when branches are ready to chop, for git hooks (see axe hook install).`,
 	RunE: runList,
 }
 
@@ -27,32 +30,49 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().BoolP("verbose", "v", false, "Show verbose output including PR numbers")
 	listCmd.Flags().BoolP("all", "a", false, "Show all branches with their PR status (open, closed, no PR, etc.)")
+	listCmd.Flags().Bool("check", false, "Exit with status 2 if branches are ready to chop, for hooks and CI")
+	listCmd.Flags().BoolP("quiet", "q", false, "Print one line, and only when branches are ready to chop")
+	listCmd.Flags().Bool("needs-attention", false, "Only show open PRs that are approved with passing checks, or whose checks fail (implies --all)")
+	addLookupFlags(listCmd)
+	addFilterFlags(listCmd)
+	addRecursiveFlags(listCmd)
+}
+
+// showAllFlag reports whether every branch status should be listed, which
+// --needs-attention implies
+func showAllFlag(cmd *cobra.Command) bool {
+	showAll, _ := cmd.Flags().GetBool("all")
+	needsAttention, _ := cmd.Flags().GetBool("needs-attention")
+	return showAll || needsAttention
 }
 
 func runList(cmd *cobra.Command, args []string) error {
 	verbose, _ := cmd.Flags().GetBool("verbose")
-	showAll, _ := cmd.Flags().GetBool("all")
+	check, _ := cmd.Flags().GetBool("check")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	showAll := showAllFlag(cmd)
 	repoPath, _ := cmd.Flags().GetString("repo")
+	recursive, _ := cmd.Flags().GetBool("recursive")
 
 	if repoPath == "" {
 		repoPath = "."
 	}
 
-	// Create dependencies
-	gitClient := git.NewDefaultClient()
-	githubClient := github.NewDefaultClient()
-	branchService := branch.NewService(gitClient, githubClient)
-
-	// Create formatter based on --no-color flag
-	noColor, _ := cmd.Flags().GetBool("no-color")
-	var formatter output.Formatter
-	if noColor {
-		formatter = output.NewPlainFormatter(os.Stdout)
-	} else {
-		formatter = output.NewColoredFormatter(os.Stdout)
+	if quiet && (showAll || recursive) {
+		return fmt.Errorf("--quiet can't be used with --all, --needs-attention or --recursive")
+	}
+	if recursive {
+		return runListRecursive(cmd, repoPath)
 	}
 
+	// Create dependencies
+	formatter := newFormatter(cmd)
 	reporter := progress.NewSpinnerReporter(os.Stdout)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
 
 	// Validate repository
 	if err := gitClient.ValidateRepository(repoPath); err != nil {
@@ -60,7 +80,18 @@ func runList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	branchService, saveCache, err := newBranchService(cmd, gitClient, repoPath, formatter)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	defer saveCache()
+
 	// Show all branch statuses or just merged branches
+	result := outcome{check: check}
+	if quiet {
+		return runListQuiet(cmd, branchService, repoPath, formatter, result)
+	}
 	if showAll {
 		// Get all branch statuses
 		statusMap, err := branchService.GetAllBranchStatuses(repoPath, reporter)
@@ -76,17 +107,17 @@ func runList(cmd *cobra.Command, args []string) error {
 		for _, branches := range statusMap {
 			totalBranches += len(branches)
 		}
+		result.candidates = len(statusMap["merged"])
 
 		if totalBranches == 0 {
 			formatter.PrintInfo("No branches found! 🪓")
-			return nil
+		} else {
+			// Display all statuses
+			formatter.PrintBranchStatuses(statusMap)
 		}
-
-		// Display all statuses
-		formatter.PrintBranchStatuses(statusMap)
 	} else {
 		// Get merged branches only (original behavior)
-		mergedBranches, err := branchService.GetMergedBranches(repoPath, reporter)
+		mergedBranches, keptBranches, err := branchService.GetMergedAndKeptBranches(repoPath, reporter)
 		if err != nil {
 			formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
 			return err
@@ -94,21 +125,47 @@ func runList(cmd *cobra.Command, args []string) error {
 
 		fmt.Println() // Add spacing after spinner
 
+		printKeptBranches(formatter, keptBranches)
+		result.candidates = len(mergedBranches)
+
 		// Display results
 		if len(mergedBranches) == 0 {
 			formatter.PrintInfo("No branches to axe! All clean 🪓")
-			return nil
-		}
-
-		formatter.PrintHeader(fmt.Sprintf("🪓 Found %d branch(es) to axe:", len(mergedBranches)))
-		for _, mb := range mergedBranches {
-			if verbose {
-				formatter.PrintBranchWithPR(mb.Name, mb.PR)
-			} else {
-				formatter.PrintBranch(mb.Name)
+		} else {
+			formatter.PrintHeader(fmt.Sprintf("🪓 Found %d branch(es) to axe:", len(mergedBranches)))
+			for _, mb := range mergedBranches {
+				if verbose {
+					formatter.PrintMergedBranch(mb)
+				} else {
+					formatter.PrintBranch(mb.Name)
+				}
 			}
 		}
 	}
 
-	return nil
+	printServiceWarnings(formatter, branchService)
+	result.failedLookups = printFailedLookups(formatter, branchService)
+	return finish(cmd, result)
+}
+
+// runListQuiet prints a one-line summary of the merged branches, and nothing
+// when there are none, so it can run from git hooks. Failed lookups only
+// show in the exit code.
+func runListQuiet(cmd *cobra.Command, branchService *branch.Service, repoPath string, formatter output.Formatter, result outcome) error {
+	mergedBranches, err := branchService.GetMergedBranches(repoPath, progress.NewSilentReporter())
+	if err != nil {
+		formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+		return err
+	}
+
+	result.candidates = len(mergedBranches)
+	result.failedLookups = len(branchService.FailedLookups())
+	if len(mergedBranches) > 0 {
+		names := make([]string, len(mergedBranches))
+		for i, mb := range mergedBranches {
+			names[i] = mb.Name
+		}
+		formatter.PrintInfo(fmt.Sprintf("🪓 %d branch(es) ready to chop: %s (run axe chop)", len(names), strings.Join(names, ", ")))
+	}
+	return finish(cmd, result)
 }
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"github.com/nikzadkhani/axe/pkg/plan"
+	"github.com/nikzadkhani/axe/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Write the branches to chop to a plan file for review",
+	Long: `Find the branches ready to axe and write them, with their tip SHAs, PR
+evidence and the action to take, to a JSON plan file. Nothing is deleted.
+
+Review the plan (in a PR, say), set the action of any branch to "keep" to
+leave it alone, then run 'axe apply' on it.`,
+	RunE: runPlan,
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan.json>",
+	Short: "Chop the branches in a plan file",
+	Long: `Apply a plan written by 'axe plan'. Each branch is only chopped if it still
+points at the SHA recorded in the plan and its PR hasn't changed since, so
+a plan made in CI or by someone else is safe to apply on any clone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApply,
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.Flags().StringP("output", "o", "", "Write the plan to this file")
+	planCmd.MarkFlagRequired("output")
+	addLookupFlags(planCmd)
+	addFilterFlags(planCmd)
+
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().BoolP("dry-run", "n", false, "Show what would be chopped without actually chopping")
+	applyCmd.Flags().BoolP("force", "f", false, "Skip confirmation and start chopping")
+	applyCmd.Flags().Bool("offline", false, "Don't check on GitHub that PRs are unchanged; only verify SHAs")
+	applyCmd.Flags().Bool("per-branch", false, "Chop branches one at a time instead of in one transaction, so one failure doesn't stop the rest")
+	applyCmd.Flags().Bool("no-verify-local", false, "Chop merged branches even if their merge commit hasn't been pulled")
+	addPRFlags(applyCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	outputPath, _ := cmd.Flags().GetString("output")
+	repoPath, _ := cmd.Flags().GetString("repo")
+
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	// Create dependencies
+	formatter := newFormatter(cmd)
+	reporter := progress.NewSpinnerReporter(os.Stdout)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	// Validate repository
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	branchService, saveCache, err := newBranchService(cmd, gitClient, repoPath, formatter)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	defer saveCache()
+
+	mergedBranches, err := branchService.GetMergedBranches(repoPath, reporter)
+	if err != nil {
+		formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+		return err
+	}
+
+	remote, _ := gitClient.GetConfig(repoPath, "remote.origin.url")
+	p := plan.New(remote, mergedBranches, time.Now().UTC())
+	if err := p.Save(outputPath); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	fmt.Println()
+	printServiceWarnings(formatter, branchService)
+	for _, mb := range mergedBranches {
+		formatter.PrintMergedBranch(mb)
+	}
+	if len(mergedBranches) > 0 {
+		fmt.Println()
+	}
+	formatter.PrintSuccess(fmt.Sprintf("Wrote a plan to chop %d branch(es) to %s", len(mergedBranches), outputPath))
+	formatter.PrintInfo(fmt.Sprintf("Review it, then run: axe apply %s", outputPath))
+	return nil
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	offline, _ := cmd.Flags().GetBool("offline")
+	repoPath, _ := cmd.Flags().GetString("repo")
+
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	// Create dependencies
+	formatter := newFormatter(cmd)
+	reporter := progress.NewSpinnerReporter(os.Stdout)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	// Validate repository
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	p, err := plan.Load(args[0])
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	remote, _ := gitClient.GetConfig(repoPath, "remote.origin.url")
+	if err := p.CheckRemote(remote); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	// Whatever bases --accept-base allowed when the plan was made were
+	// reviewed along with it, so the bases its PRs went into are accepted
+	var bases []string
+	for _, e := range p.Branches {
+		if e.PR != nil && e.PR.BaseRefName != "" {
+			bases = append(bases, e.PR.BaseRefName)
+		}
+	}
+	branchService, saveCache, err := newBranchService(cmd, gitClient, repoPath, formatter, branch.WithAcceptedBases(bases...))
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	defer saveCache()
+
+	reporter.Start("Fetching local branches...")
+	current, err := gitClient.ListBranches(repoPath)
+	if err != nil {
+		reporter.StopWithError(fmt.Sprintf("Failed to fetch local branches: %v", err))
+		return err
+	}
+	reporter.Stop(fmt.Sprintf("Found %d local branches", len(current)))
+
+	// PRs are only checked for branches that are still where the plan left them
+	var prs map[string]*github.PRInfo
+	var lookupErrs map[string]error
+	if !offline {
+		unchanged, _ := p.Verify(current, nil, nil)
+		var names []string
+		for _, b := range unchanged {
+			names = append(names, b.Name)
+		}
+		prs, lookupErrs = map[string]*github.PRInfo{}, nil
+		if len(names) > 0 {
+			prs, lookupErrs = branchService.CurrentPRs(repoPath, names, reporter)
+		}
+	}
+	ready, rejected := p.Verify(current, prs, lookupErrs)
+
+	fmt.Println() // Add spacing after spinner
+	for _, r := range rejected {
+		formatter.PrintWarning(fmt.Sprintf("Skipping %s: %s", r.Branch, r.Reason))
+	}
+
+	// Branches kept since the plan was made are left alone
+	ready = slices.DeleteFunc(ready, func(b git.Branch) bool {
+		reason := branchService.KeepReason(repoPath, b.Name, prs[b.Name])
+		if reason != "" {
+			formatter.PrintWarning(fmt.Sprintf("Skipping %s: %s", b.Name, reason))
+		}
+		return reason != ""
+	})
+
+	// A merge commit pulled where the plan was made may not be on this
+	// clone. Offline, the PRs recorded in the plan are checked instead.
+	merged := make(map[string]*github.PRInfo, len(ready))
+	for _, b := range ready {
+		merged[b.Name] = prs[b.Name]
+	}
+	if offline {
+		for _, e := range p.Branches {
+			if _, ok := merged[e.Branch]; ok {
+				merged[e.Branch] = e.PR
+			}
+		}
+	}
+	unsafe := branchService.UnsafeMerges(repoPath, merged)
+	ready = slices.DeleteFunc(ready, func(b git.Branch) bool {
+		reason, ok := unsafe[b.Name]
+		if ok {
+			formatter.PrintWarning(fmt.Sprintf("Skipping %s: %s", b.Name, reason))
+		}
+		return ok
+	})
+
+	// Branches whose PR couldn't be looked up were skipped above
+	result := outcome{failedLookups: len(lookupErrs)}
+	if len(ready) == 0 {
+		formatter.PrintInfo("Nothing to chop from this plan.")
+		return finish(cmd, result)
+	}
+
+	formatter.PrintHeader(fmt.Sprintf("🪓 %d branch(es) from the plan are ready to chop:", len(ready)))
+	for _, b := range ready {
+		formatter.PrintBranch(b.Name)
+	}
+	fmt.Println()
+
+	if dryRun {
+		formatter.PrintWarning("Dry run - no branches were chopped")
+		return finish(cmd, result)
+	}
+	if !force && !confirm("🪓 Chop these branches? [y/N]: ") {
+		formatter.PrintInfo("Cancelled. No branches were chopped.")
+		return finish(cmd, result)
+	}
+
+	deleted, failed := branchService.DeleteBranches(repoPath, ready, reporter)
+	printChopResults(formatter, deleted, failed)
+	result.failed = len(failed)
+	return finish(cmd, result)
+}
@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/output"
+	"github.com/nikzadkhani/axe/pkg/progress"
+	"github.com/nikzadkhani/axe/pkg/workspace"
+	"github.com/spf13/cobra"
+)
+
+// maxParallelRepos limits how many repositories are scanned at once. GitHub
+// lookups across all repositories additionally share one scheduler, so the
+// --concurrency budget is global.
+const maxParallelRepos = 4
+
+// repoScan is the result of scanning a single repository
+type repoScan struct {
+	Path     string
+	Name     string
+	Service  *branch.Service
+	Merged   []branch.MergedBranch
+	Statuses map[string][]branch.BranchStatus
+	Err      error
+}
+
+// addRecursiveFlags registers the flags that control multi-repository scans
+func addRecursiveFlags(cmd *cobra.Command) {
+	defaults := workspace.DefaultOptions()
+	cmd.Flags().BoolP("recursive", "R", false, "Scan every git repository beneath --repo")
+	cmd.Flags().Int("max-depth", defaults.MaxDepth, "How many directory levels to search with --recursive")
+	cmd.Flags().StringSlice("ignore", defaults.Ignore, "Directory glob patterns to skip with --recursive")
+	cmd.Flags().Bool("submodules", false, "Include nested submodules with --recursive")
+}
+
+// discoverRepositories finds the repositories to scan beneath root
+func discoverRepositories(cmd *cobra.Command, root string) ([]string, error) {
+	opts := workspace.DefaultOptions()
+	opts.MaxDepth, _ = cmd.Flags().GetInt("max-depth")
+	opts.Ignore, _ = cmd.Flags().GetStringSlice("ignore")
+	opts.IncludeSubmodules, _ = cmd.Flags().GetBool("submodules")
+	return workspace.Discover(root, opts)
+}
+
+// scanRepositories runs scan against every repository with a shared lookup
+// scheduler and returns the results in repository order
+func scanRepositories(cmd *cobra.Command, root string, repos []string, formatter output.Formatter, scan func(*repoScan)) []*repoScan {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	cfg := branch.DefaultSchedulerConfig()
+	cfg.Concurrency = concurrency
+	scheduler := branch.NewScheduler(cfg)
+
+	reporter := progress.NewSpinnerReporter(os.Stdout)
+	reporter.Start(fmt.Sprintf("Scanning %d repositories...", len(repos)))
+
+	results := make([]*repoScan, len(repos))
+	sem := make(chan struct{}, maxParallelRepos)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	scanned := 0
+
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := &repoScan{Path: repo, Name: repoDisplayName(root, repo)}
+			gitClient, err := newGitClient(repo)
+			var service *branch.Service
+			var saveCache func()
+			if err == nil {
+				service, saveCache, err = newBranchService(cmd, gitClient, repo, formatter, branch.WithScheduler(scheduler))
+			}
+			if err != nil {
+				result.Err = err
+			} else {
+				result.Service = service
+				scan(result)
+				saveCache()
+			}
+			results[i] = result
+
+			mu.Lock()
+			scanned++
+			reporter.Update(fmt.Sprintf("Scanned %d/%d repositories", scanned, len(repos)))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	reporter.Stop(fmt.Sprintf("Scanned %d repositories", len(repos)))
+	return results
+}
+
+// repoDisplayName returns a repository path relative to the scan root
+func repoDisplayName(root, repo string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return repo
+	}
+	rel, err := filepath.Rel(absRoot, repo)
+	if err != nil {
+		return repo
+	}
+	if rel == "." {
+		return filepath.Base(repo)
+	}
+	return rel
+}
+
+// printScanErrors reports repositories that could not be scanned and returns
+// how many there were
+func printScanErrors(formatter output.Formatter, results []*repoScan) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			formatter.PrintError(fmt.Sprintf("%s: %v", r.Name, r.Err))
+			failed++
+		}
+	}
+	return failed
+}
+
+// printRepoFailedLookups warns about what couldn't be checked and failed PR
+// lookups in each scanned repository, and returns how many lookups failed
+func printRepoFailedLookups(formatter output.Formatter, results []*repoScan) int {
+	failed := 0
+	for _, r := range results {
+		if r.Service == nil {
+			continue
+		}
+		for _, warning := range r.Service.Warnings() {
+			formatter.PrintWarning(fmt.Sprintf("%s: %s", r.Name, warning))
+		}
+		if n := len(r.Service.FailedLookups()); n > 0 {
+			formatter.PrintWarning(fmt.Sprintf("%s: couldn't look up PRs for %d branch(es), so the results may be incomplete", r.Name, n))
+			failed += n
+		}
+	}
+	return failed
+}
+
+func runListRecursive(cmd *cobra.Command, root string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	check, _ := cmd.Flags().GetBool("check")
+	showAll := showAllFlag(cmd)
+	formatter := newFormatter(cmd)
+
+	repos, err := discoverRepositories(cmd, root)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	if len(repos) == 0 {
+		formatter.PrintInfo(fmt.Sprintf("No git repositories found under %s", root))
+		return nil
+	}
+
+	silent := progress.NewSilentReporter()
+	results := scanRepositories(cmd, root, repos, formatter, func(r *repoScan) {
+		if showAll {
+			r.Statuses, r.Err = r.Service.GetAllBranchStatuses(r.Path, silent)
+		} else {
+			r.Merged, r.Err = r.Service.GetMergedBranches(r.Path, silent)
+		}
+	})
+
+	fmt.Println() // Add spacing after spinner
+
+	total, withBranches := 0, 0
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+
+		if showAll {
+			count := 0
+			for _, branches := range r.Statuses {
+				count += len(branches)
+			}
+			if count == 0 {
+				continue
+			}
+			formatter.PrintHeader(fmt.Sprintf("📁 %s", r.Name))
+			formatter.PrintBranchStatuses(r.Statuses)
+			total += len(r.Statuses["merged"])
+			if len(r.Statuses["merged"]) > 0 {
+				withBranches++
+			}
+			continue
+		}
+
+		if len(r.Merged) == 0 {
+			continue
+		}
+		withBranches++
+		total += len(r.Merged)
+		formatter.PrintHeader(fmt.Sprintf("📁 %s: %d branch(es) to axe", r.Name, len(r.Merged)))
+		for _, mb := range r.Merged {
+			if verbose {
+				formatter.PrintMergedBranch(mb)
+			} else {
+				formatter.PrintBranch(mb.Name)
+			}
+		}
+	}
+
+	fmt.Println()
+	failed := printScanErrors(formatter, results)
+	result := outcome{check: check, candidates: total, failed: failed, failedLookups: printRepoFailedLookups(formatter, results)}
+
+	if total == 0 {
+		formatter.PrintInfo(fmt.Sprintf("No branches to axe in %d repositories! All clean 🪓", len(repos)-failed))
+	} else {
+		formatter.PrintHeader(fmt.Sprintf("🪓 Found %d branch(es) to axe across %d of %d repositories", total, withBranches, len(repos)))
+	}
+	return finish(cmd, result)
+}
+
+func runCleanRecursive(cmd *cobra.Command, root string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	confirmEach, _ := cmd.Flags().GetBool("confirm-each-repo")
+	formatter := newFormatter(cmd)
+
+	repos, err := discoverRepositories(cmd, root)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	if len(repos) == 0 {
+		formatter.PrintInfo(fmt.Sprintf("No git repositories found under %s", root))
+		return nil
+	}
+
+	silent := progress.NewSilentReporter()
+	results := scanRepositories(cmd, root, repos, formatter, func(r *repoScan) {
+		r.Merged, r.Err = r.Service.GetMergedBranches(r.Path, silent)
+	})
+
+	fmt.Println() // Add spacing after spinner
+	result := outcome{failed: printScanErrors(formatter, results), failedLookups: printRepoFailedLookups(formatter, results)}
+
+	var candidates []*repoScan
+	total := 0
+	for _, r := range results {
+		if r.Err == nil && len(r.Merged) > 0 {
+			candidates = append(candidates, r)
+			total += len(r.Merged)
+		}
+	}
+
+	if total == 0 {
+		formatter.PrintInfo("No branches to chop! All clean 🪓")
+		return finish(cmd, result)
+	}
+
+	// Display what will be chopped
+	for _, r := range candidates {
+		formatter.PrintHeader(fmt.Sprintf("📁 %s: %d branch(es) ready to chop", r.Name, len(r.Merged)))
+		for _, mb := range r.Merged {
+			formatter.PrintBranch(mb.Name)
+		}
+	}
+	formatter.PrintHeader(fmt.Sprintf("🪓 Found %d branch(es) ready to chop across %d repositories", total, len(candidates)))
+	fmt.Println()
+
+	if dryRun {
+		formatter.PrintWarning("Dry run - no branches were chopped")
+		return finish(cmd, result)
+	}
+
+	// Confirm once overall unless asked to confirm each repository
+	if !force && !confirmEach && !confirm("🪓 Chop these branches in all repositories? [y/N]: ") {
+		formatter.PrintInfo("Cancelled. No branches were chopped.")
+		return finish(cmd, result)
+	}
+
+	reporter := progress.NewSpinnerReporter(os.Stdout)
+	choppedTotal, failedTotal := 0, 0
+	for _, r := range candidates {
+		if !force && confirmEach && !confirm(fmt.Sprintf("🪓 Chop %d branch(es) in %s? [y/N]: ", len(r.Merged), r.Name)) {
+			formatter.PrintInfo(fmt.Sprintf("Skipped %s", r.Name))
+			continue
+		}
+
+		var toDelete []git.Branch
+		for _, mb := range r.Merged {
+			toDelete = append(toDelete, mb.Branch)
+		}
+
+		deleted, failed := r.Service.DeleteBranches(r.Path, toDelete, reporter)
+		for _, f := range failed {
+			formatter.PrintError(fmt.Sprintf("Failed to chop: %s in %s (%s)", f.Branch, r.Name, f.Reason))
+		}
+		choppedTotal += len(deleted)
+		failedTotal += len(failed)
+	}
+
+	fmt.Println()
+	if failedTotal > 0 {
+		formatter.PrintWarning(fmt.Sprintf("🪓 Chopped %d branch(es), %d failed", choppedTotal, failedTotal))
+	} else {
+		formatter.PrintSuccess(fmt.Sprintf("🪓 Chopped %d branch(es)!", choppedTotal))
+	}
+
+	result.failed += failedTotal
+	return finish(cmd, result)
+}
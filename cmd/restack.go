@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+var restackCmd = &cobra.Command{
+	Use:   "restack",
+	Short: "Rebase branches stacked on merged branches, then chop the merged ones",
+	Long: `When a branch in a stack is squash-merged, the branches built on top of it
+still carry its original commits. Restack rebases each of them onto the
+squash commit with 'git rebase --onto <squash commit> <fork point>', so
+only their own commits remain, and branches stacked on those onto the
+rebased branches. The merged parent is chopped once its whole stack is
+restacked.
+
+A rebase that hits a conflict is aborted, leaving that branch, the
+branches stacked on it and the merged parent untouched.`,
+	RunE: runRestack,
+}
+
+func init() {
+	rootCmd.AddCommand(restackCmd)
+	restackCmd.Flags().BoolP("dry-run", "n", false, "Show what would be restacked without changing anything")
+	restackCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
+	addLookupFlags(restackCmd)
+}
+
+func runRestack(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	repoPath, _ := cmd.Flags().GetString("repo")
+
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	// Create dependencies
+	formatter := newFormatter(cmd)
+	reporter := progress.NewSpinnerReporter(os.Stdout)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	// Validate repository
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	branchService, saveCache, err := newBranchService(cmd, gitClient, repoPath, formatter)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	defer saveCache()
+
+	stacks, err := branchService.GetMergedStacks(repoPath, reporter)
+	if err != nil {
+		formatter.PrintError(fmt.Sprintf("Failed to find stacked branches: %v", err))
+		return err
+	}
+
+	fmt.Println() // Add spacing after spinner
+	printServiceWarnings(formatter, branchService)
+
+	if len(stacks) == 0 {
+		formatter.PrintInfo("No branches are stacked on merged branches. Nothing to restack 🪓")
+		return nil
+	}
+
+	for _, stack := range stacks {
+		formatter.PrintMergedBranch(stack.Parent)
+		for _, child := range stack.Children {
+			formatter.PrintBranch(fmt.Sprintf("  └ %s (on %s)", child.Name, child.Parent))
+		}
+	}
+	fmt.Println()
+
+	if dryRun {
+		formatter.PrintWarning("Dry run - nothing was restacked")
+		return nil
+	}
+	if !force && !confirm("🪓 Restack these branches and chop the merged ones? [y/N]: ") {
+		formatter.PrintInfo("Cancelled. Nothing was restacked.")
+		return nil
+	}
+
+	var result outcome
+	for _, stack := range stacks {
+		steps, err := branchService.Restack(repoPath, stack, reporter)
+		if err != nil {
+			formatter.PrintError(fmt.Sprintf("Can't restack on %s: %v", stack.Parent.Name, err))
+			result.failed++
+			continue
+		}
+
+		restacked := true
+		for _, step := range steps {
+			if step.Err != nil {
+				restacked = false
+				result.failed++
+				formatter.PrintError(fmt.Sprintf("Failed to restack %s onto %s: %v", step.Branch, step.Onto, step.Err))
+			} else {
+				formatter.PrintSuccess(fmt.Sprintf("Restacked %s onto %s", step.Branch, step.Onto))
+			}
+		}
+		if !restacked {
+			formatter.PrintWarning(fmt.Sprintf("Kept %s until the branches stacked on it are restacked", stack.Parent.Name))
+			continue
+		}
+
+		_, failed := branchService.DeleteBranches(repoPath, []git.Branch{stack.Parent.Branch}, reporter)
+		for _, f := range failed {
+			formatter.PrintError(fmt.Sprintf("Failed to chop: %s (%s)", f.Branch, f.Reason))
+		}
+		if len(failed) == 0 {
+			formatter.PrintSuccess(fmt.Sprintf("Chopped: %s", stack.Parent.Name))
+		}
+		result.failed += len(failed)
+	}
+	return finish(cmd, result)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -14,13 +15,24 @@ var rootCmd = &cobra.Command{
 that have been squash-merged on GitHub but still exist locally.
 
 It uses the GitHub CLI (gh) to check the merge status of pull requests
-associated with your local branches.`,
+associated with your local branches.
+
+Exit codes:
+  0  nothing to do, or everything was done
+  1  error
+  2  branches ready to chop were found (axe branches --check)
+  3  partial failure: some branches or repositories failed
+  4  some PR lookups failed, so branches may be missing from the results`,
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var status *exitStatus
+		if errors.As(err, &status) {
+			os.Exit(status.code)
+		}
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(ExitError)
 	}
 }
 
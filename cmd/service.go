@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/cache"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"github.com/nikzadkhani/axe/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// newFormatter creates a formatter based on the --no-color flag
+func newFormatter(cmd *cobra.Command) output.Formatter {
+	return newFormatterTo(cmd, os.Stdout)
+}
+
+// newFormatterTo creates a formatter writing to w based on the --no-color flag
+func newFormatterTo(cmd *cobra.Command, w io.Writer) output.Formatter {
+	noColor, _ := cmd.Flags().GetBool("no-color")
+	if noColor {
+		return output.NewPlainFormatter(w)
+	}
+	return output.NewColoredFormatter(w)
+}
+
+// newGitClient creates the git backend selected by the axe.backend git config
+// key. The key is read in-process, so picking a backend doesn't itself run
+// git.
+func newGitClient(repoPath string) (git.Client, error) {
+	backend, _ := git.NewNativeClient().GetConfig(repoPath, "axe.backend")
+	return git.NewClient(backend)
+}
+
+// addLookupFlags registers the flags that control how PR lookups are made
+func addLookupFlags(cmd *cobra.Command) {
+	addPRFlags(cmd)
+	cmd.Flags().Bool("no-cache", false, "Don't read or write the PR status cache")
+	cmd.Flags().Bool("refresh", false, "Ignore cached PR status and look everything up again")
+	cmd.Flags().StringSlice("strategy", branch.Strategies, "How to detect merged branches ("+strings.Join(branch.Strategies, ", ")+")")
+	cmd.Flags().Bool("offline", false, "Don't call the GitHub API; detect merges from local refs only")
+	cmd.Flags().Bool("fetch-pr-refs", false, "Fetch refs/pull/*/head from origin before detecting merges")
+	cmd.Flags().Bool("fetch", false, "Fetch the default branch from origin before checking that merge commits were pulled")
+	cmd.Flags().Bool("no-verify-local", false, "Chop merged branches even if their merge commit hasn't been pulled")
+	cmd.Flags().StringSlice("accept-base", nil, "Also chop branches whose PR was merged into a branch matching these globs, not just the default branch")
+}
+
+// addPRFlags registers the lookup flags that commands checking PRs without
+// detecting merges, like axe apply, also need
+func addPRFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("concurrency", branch.DefaultSchedulerConfig().Concurrency, "Maximum number of concurrent GitHub lookups")
+	cmd.Flags().StringSlice("keep-label", []string{defaultKeepLabel}, "Never chop branches whose PR carries one of these labels (default from git config axe.keepLabels)")
+}
+
+// defaultKeepLabel is the PR label that keeps a branch unless --keep-label
+// or axe.keepLabels says otherwise
+const defaultKeepLabel = "keep-branch"
+
+// keepLabelsFromFlags returns the PR labels that keep a branch: --keep-label
+// if given, else the comma-separated axe.keepLabels git config, else
+// keep-branch
+func keepLabelsFromFlags(cmd *cobra.Command, gitClient git.Client, repoPath string) []string {
+	labels, _ := cmd.Flags().GetStringSlice("keep-label")
+	if !cmd.Flags().Changed("keep-label") {
+		if configured, err := gitClient.GetConfig(repoPath, "axe.keepLabels"); err == nil && configured != "" {
+			labels = strings.Split(configured, ",")
+		}
+	}
+	var keep []string
+	for _, label := range labels {
+		if label = strings.TrimSpace(label); label != "" {
+			keep = append(keep, label)
+		}
+	}
+	return keep
+}
+
+// addFilterFlags registers the flags that narrow which branches are acted on
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("match", nil, "Only include branches matching these globs")
+	cmd.Flags().StringSlice("exclude", nil, "Skip branches matching these globs")
+	cmd.Flags().StringSlice("state", nil, "Only include branches with these statuses ("+strings.Join(branch.Statuses, ", ")+")")
+	cmd.Flags().String("older-than", "", "Only include branches whose PR closed, or last commit was made, longer ago than this (e.g. 30d, 2w, 6mo)")
+	cmd.Flags().String("author", "", "Only include branches with commits by this email address, or \"me\"")
+	cmd.Flags().IntSlice("pr", nil, "Only include branches for these PR numbers")
+}
+
+// filterFromFlags builds a branch filter from the filter flags
+func filterFromFlags(cmd *cobra.Command, gitClient git.Client, repoPath string) (branch.Filter, error) {
+	var filter branch.Filter
+	filter.Match, _ = cmd.Flags().GetStringSlice("match")
+	filter.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	filter.States, _ = cmd.Flags().GetStringSlice("state")
+	filter.PRs, _ = cmd.Flags().GetIntSlice("pr")
+	filter.Author, _ = cmd.Flags().GetString("author")
+	// Only axe branches registers --needs-attention
+	filter.NeedsAttention, _ = cmd.Flags().GetBool("needs-attention")
+
+	if olderThan, _ := cmd.Flags().GetString("older-than"); olderThan != "" {
+		age, err := branch.ParseAge(olderThan)
+		if err != nil {
+			return filter, err
+		}
+		filter.OlderThan = age
+	}
+
+	if filter.Author == "me" {
+		email, err := gitClient.GetConfig(repoPath, "user.email")
+		if err != nil || email == "" {
+			return filter, fmt.Errorf("--author me requires git config user.email to be set")
+		}
+		filter.Author = email
+	}
+
+	return filter, filter.Validate()
+}
+
+// newBranchService creates a branch Service configured from the lookup and
+// filter flags. Extra options are applied last so callers can override flag
+// settings. The returned function saves the PR cache and must be called once
+// the service is no longer needed.
+func newBranchService(cmd *cobra.Command, gitClient git.Client, repoPath string, formatter output.Formatter, extra ...branch.Option) (*branch.Service, func(), error) {
+	filter, err := filterFromFlags(cmd, gitClient, repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	strategies, _ := cmd.Flags().GetStringSlice("strategy")
+	if err := branch.ValidateStrategies(strategies); err != nil {
+		return nil, nil, err
+	}
+	if offline, _ := cmd.Flags().GetBool("offline"); offline {
+		strategies = slices.DeleteFunc(slices.Clone(strategies), func(s string) bool { return s == branch.StrategyPR })
+	}
+
+	acceptBases, _ := cmd.Flags().GetStringSlice("accept-base")
+	if err := branch.ValidatePatterns(acceptBases); err != nil {
+		return nil, nil, err
+	}
+
+	if fetch, _ := cmd.Flags().GetBool("fetch-pr-refs"); fetch {
+		if err := gitClient.FetchPullRefs(repoPath, "origin"); err != nil {
+			formatter.PrintWarning(err.Error())
+		}
+	}
+
+	if fetch, _ := cmd.Flags().GetBool("fetch"); fetch {
+		if err := fetchDefaultBranch(gitClient, repoPath); err != nil {
+			formatter.PrintWarning(err.Error())
+		}
+	}
+
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	opts := []branch.Option{
+		branch.WithConcurrency(concurrency),
+		branch.WithFilter(filter),
+		branch.WithStrategies(strategies...),
+		branch.WithAcceptedBases(acceptBases...),
+		branch.WithKeepLabels(keepLabelsFromFlags(cmd, gitClient, repoPath)...),
+	}
+
+	store := openCache(cmd, gitClient, repoPath, formatter)
+	if store != nil {
+		opts = append(opts, branch.WithCache(store))
+	}
+	if noVerify, _ := cmd.Flags().GetBool("no-verify-local"); noVerify {
+		opts = append(opts, branch.WithoutLocalVerification())
+	}
+	if perBranch, _ := cmd.Flags().GetBool("per-branch"); perBranch {
+		opts = append(opts, branch.WithPerBranchDeletion())
+	}
+	opts = append(opts, extra...)
+
+	service := branch.NewService(gitClient, github.NewDefaultClient(), opts...)
+	done := func() {
+		if store == nil {
+			return
+		}
+		if err := store.Save(); err != nil {
+			formatter.PrintWarning(fmt.Sprintf("Failed to save PR cache: %v", err))
+		}
+	}
+	return service, done, nil
+}
+
+// fetchDefaultBranch updates origin's copy of the default branch, so merge
+// commits that haven't been pulled yet can still be found locally
+func fetchDefaultBranch(gitClient git.Client, repoPath string) error {
+	defaultBranch, err := gitClient.GetDefaultBranch(repoPath)
+	if err != nil {
+		return err
+	}
+	return gitClient.FetchBranch(repoPath, "origin", defaultBranch)
+}
+
+// openCache opens the repository's PR cache unless --no-cache is set. Cache
+// problems are reported as warnings since axe works without it.
+func openCache(cmd *cobra.Command, gitClient git.Client, repoPath string, formatter output.Formatter) *cache.Store {
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	if noCache {
+		return nil
+	}
+
+	gitDir, err := gitClient.GetGitDir(repoPath)
+	if err != nil {
+		formatter.PrintWarning(fmt.Sprintf("PR cache disabled: %v", err))
+		return nil
+	}
+
+	store, err := cache.Open(cache.DefaultPath(gitDir), cache.DefaultTTLs())
+	if err != nil {
+		formatter.PrintWarning(fmt.Sprintf("PR cache disabled: %v", err))
+		return nil
+	}
+
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	store.SetRefresh(refresh)
+	return store
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+var staleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "List branches that never had a PR and have gone quiet 🍂",
+	Long: `Find local branches that never had a PR and haven't seen a commit for a
+while, showing when and by whom they were last touched and how many commits
+they hold that aren't on the default branch.
+
+Branches with commits that exist on no remote are left out unless
+--include-unpushed is given, since chopping them would lose that work.
+Use --chop to delete the listed branches. A recovery point is saved under
+refs/axe/recovery/ first.`,
+	RunE: runStale,
+}
+
+func init() {
+	rootCmd.AddCommand(staleCmd)
+	staleCmd.Flags().String("idle", "90d", "Only list branches whose last commit is older than this (e.g. 30d, 6mo, 1y)")
+	staleCmd.Flags().Bool("include-unpushed", false, "Also list branches with commits that aren't on any remote")
+	staleCmd.Flags().Bool("chop", false, "Chop the stale branches")
+	staleCmd.Flags().BoolP("dry-run", "n", false, "With --chop, show what would be chopped without actually chopping")
+	staleCmd.Flags().BoolP("force", "f", false, "With --chop, skip confirmation and start chopping")
+	staleCmd.Flags().Bool("per-branch", false, "With --chop, chop branches one at a time instead of in one transaction")
+	addLookupFlags(staleCmd)
+	addFilterFlags(staleCmd)
+}
+
+func runStale(cmd *cobra.Command, args []string) error {
+	idleFlag, _ := cmd.Flags().GetString("idle")
+	includeUnpushed, _ := cmd.Flags().GetBool("include-unpushed")
+	chop, _ := cmd.Flags().GetBool("chop")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	repoPath, _ := cmd.Flags().GetString("repo")
+
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	idleFor, err := branch.ParseAge(idleFlag)
+	if err != nil {
+		return err
+	}
+	if offline, _ := cmd.Flags().GetBool("offline"); offline {
+		return fmt.Errorf("--offline can't be used with axe stale, which needs GitHub to tell which branches never had a PR")
+	}
+
+	// Create dependencies
+	formatter := newFormatter(cmd)
+	reporter := progress.NewSpinnerReporter(os.Stdout)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	// Validate repository
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	branchService, saveCache, err := newBranchService(cmd, gitClient, repoPath, formatter)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	defer saveCache()
+
+	staleBranches, err := branchService.GetStaleBranches(repoPath, idleFor, reporter)
+	if err != nil {
+		formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+		return err
+	}
+
+	fmt.Println() // Add spacing after spinner
+	result := outcome{failedLookups: printFailedLookups(formatter, branchService)}
+
+	// Unpushed work is only listed when asked for
+	var listed []branch.StaleBranch
+	skipped := 0
+	for _, sb := range staleBranches {
+		if sb.HasUnpushedWork() && !includeUnpushed {
+			skipped++
+			continue
+		}
+		listed = append(listed, sb)
+	}
+
+	if len(listed) == 0 {
+		formatter.PrintInfo(fmt.Sprintf("No branches idle for longer than %s! 🍂", idleFlag))
+	} else {
+		formatter.PrintHeader(fmt.Sprintf("🍂 Found %d branch(es) without a PR idle for longer than %s:", len(listed), idleFlag))
+		for _, sb := range listed {
+			formatter.PrintStaleBranch(sb)
+		}
+	}
+	if skipped > 0 {
+		fmt.Println()
+		formatter.PrintInfo(fmt.Sprintf("Skipped %d branch(es) with unpushed commits (use --include-unpushed to list them)", skipped))
+	}
+
+	if !chop || len(listed) == 0 {
+		return finish(cmd, result)
+	}
+
+	fmt.Println()
+	if dryRun {
+		formatter.PrintWarning("Dry run - no branches were chopped")
+		return finish(cmd, result)
+	}
+
+	if !force && !confirm("🪓 Chop these branches? [y/N]: ") {
+		formatter.PrintInfo("Cancelled. No branches were chopped.")
+		return finish(cmd, result)
+	}
+
+	var branchNames []string
+	var toDelete []git.Branch
+	for _, sb := range listed {
+		branchNames = append(branchNames, sb.Name)
+		toDelete = append(toDelete, sb.Branch)
+	}
+
+	// Stale branches were never reviewed, so keep a way back
+	recoveryPrefix, err := branchService.CreateRecoveryPoint(repoPath, branchNames)
+	if err != nil {
+		formatter.PrintError(fmt.Sprintf("%v; no branches were chopped", err))
+		return err
+	}
+
+	deleted, failed := branchService.DeleteBranches(repoPath, toDelete, reporter)
+	printChopResults(formatter, deleted, failed)
+	if len(deleted) > 0 {
+		formatter.PrintInfo(fmt.Sprintf("Recovery point saved under %s. To restore a branch run:", recoveryPrefix))
+		formatter.PrintInfo(fmt.Sprintf("  git branch <name> %s/<name>", recoveryPrefix))
+	}
+
+	result.failed = len(failed)
+	return finish(cmd, result)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch, fast-forward the default branch, and chop merged branches 🪓",
+	Long: `Run the daily cleanup in one go:
+
+  1. Fetch origin, pruning branches that were deleted there (git fetch --prune)
+  2. Fast-forward the default branch to its upstream (git pull --ff-only),
+     even when it isn't checked out
+  3. Find the merged branches and, if one of them is checked out, switch
+     to the default branch
+  4. Chop the merged branches
+
+Each stage can be skipped with its --no-* flag. A stage that fails is
+reported and the rest still run, so nothing is chopped that chop wouldn't
+chop on its own.`,
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().BoolP("dry-run", "n", false, "Fetch and fast-forward, but only show what would be chopped")
+	syncCmd.Flags().BoolP("force", "f", false, "Skip confirmation and start chopping")
+	syncCmd.Flags().Bool("no-fetch", false, "Don't fetch origin")
+	syncCmd.Flags().Bool("no-prune", false, "Don't prune branches deleted on origin while fetching")
+	syncCmd.Flags().Bool("no-pull", false, "Don't fast-forward the default branch")
+	syncCmd.Flags().Bool("no-switch", false, "Don't switch off a merged branch that is checked out")
+	syncCmd.Flags().Bool("no-chop", false, "Stop after updating the default branch")
+	syncCmd.Flags().Bool("per-branch", false, "Chop branches one at a time instead of in one transaction, so one failure doesn't stop the rest")
+	addLookupFlags(syncCmd)
+	addFilterFlags(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	noFetch, _ := cmd.Flags().GetBool("no-fetch")
+	noPrune, _ := cmd.Flags().GetBool("no-prune")
+	noPull, _ := cmd.Flags().GetBool("no-pull")
+	noSwitch, _ := cmd.Flags().GetBool("no-switch")
+	noChop, _ := cmd.Flags().GetBool("no-chop")
+	repoPath, _ := cmd.Flags().GetString("repo")
+
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	// Create dependencies
+	formatter := newFormatter(cmd)
+	reporter := progress.NewSpinnerReporter(os.Stdout)
+	gitClient, err := newGitClient(repoPath)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	// Validate repository
+	if err := gitClient.ValidateRepository(repoPath); err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+
+	branchService, saveCache, err := newBranchService(cmd, gitClient, repoPath, formatter)
+	if err != nil {
+		formatter.PrintError(err.Error())
+		return err
+	}
+	defer saveCache()
+
+	// Failures are already reported by the spinner; later stages are safe
+	// to run on stale refs, but the run still counts as a partial failure
+	var result outcome
+	if !noFetch {
+		if err := branchService.Fetch(repoPath, !noPrune, reporter); err != nil {
+			result.failed++
+		}
+	}
+	if !noPull {
+		if _, err := branchService.FastForwardDefault(repoPath, reporter); err != nil {
+			result.failed++
+		}
+	}
+	if noChop {
+		return finish(cmd, result)
+	}
+
+	mergedBranches, keptBranches, err := branchService.GetMergedAndKeptBranches(repoPath, reporter)
+	if err != nil {
+		formatter.PrintError(fmt.Sprintf("Failed to get local branches: %v", err))
+		return err
+	}
+
+	fmt.Println() // Add spacing after spinner
+
+	printKeptBranches(formatter, keptBranches)
+	printServiceWarnings(formatter, branchService)
+	result.failedLookups = printFailedLookups(formatter, branchService)
+
+	if len(mergedBranches) == 0 {
+		formatter.PrintInfo("No branches to chop! All clean 🪓")
+		return finish(cmd, result)
+	}
+
+	formatter.PrintHeader(fmt.Sprintf("🪓 Found %d branch(es) ready to chop:", len(mergedBranches)))
+	for _, mb := range mergedBranches {
+		formatter.PrintBranch(mb.Name)
+	}
+	fmt.Println()
+
+	if dryRun {
+		formatter.PrintWarning("Dry run - no branches were chopped")
+		return finish(cmd, result)
+	}
+	if !force && !confirm("🪓 Chop these branches? [y/N]: ") {
+		formatter.PrintInfo("Cancelled. No branches were chopped.")
+		return finish(cmd, result)
+	}
+
+	// A merged branch that stays checked out is reported as a failure
+	if !noSwitch {
+		if _, err := branchService.LeaveMergedHead(repoPath, mergedBranches, reporter); err != nil {
+			result.failed++
+		}
+	}
+
+	toDelete := make([]git.Branch, len(mergedBranches))
+	for i, mb := range mergedBranches {
+		toDelete[i] = mb.Branch
+	}
+	deleted, failed := branchService.DeleteBranches(repoPath, toDelete, reporter)
+	printChopResults(formatter, deleted, failed)
+	result.failed += len(failed)
+	return finish(cmd, result)
+}
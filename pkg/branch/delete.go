@@ -0,0 +1,97 @@
+package branch
+
+import (
+	"fmt"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+)
+
+// DeleteFailure is a branch that wasn't deleted, and why
+type DeleteFailure struct {
+	Branch string
+	Reason string
+}
+
+// DeleteBranches deletes branches, each only if it still points at the SHA
+// it was scanned at. They are deleted in one transaction, so if any branch
+// moved none are deleted, unless WithPerBranchDeletion is set. Branches that
+// are checked out are never deleted.
+func (s *Service) DeleteBranches(repoPath string, branches []git.Branch, reporter ProgressReporter) (deleted []string, failed []DeleteFailure) {
+	var deletable []git.Branch
+	for _, b := range branches {
+		switch {
+		case b.WorktreePath != "":
+			failed = append(failed, DeleteFailure{Branch: b.Name, Reason: "checked out in " + b.WorktreePath})
+		case b.IsHead:
+			failed = append(failed, DeleteFailure{Branch: b.Name, Reason: "checked out"})
+		default:
+			deletable = append(deletable, b)
+		}
+	}
+	if len(deletable) == 0 {
+		return nil, failed
+	}
+
+	reporter.Start(fmt.Sprintf("Chopping %d branches...", len(deletable)))
+	if !s.perBranch {
+		if err := s.gitClient.DeleteBranches(repoPath, deletable); err != nil {
+			failed = append(failed, s.explainFailures(repoPath, deletable, err)...)
+		} else {
+			for _, b := range deletable {
+				deleted = append(deleted, b.Name)
+			}
+		}
+		reporter.Stop(fmt.Sprintf("Chopped %d branches", len(deleted)))
+		return deleted, failed
+	}
+
+	for i, b := range deletable {
+		reporter.Update(fmt.Sprintf("Chopping (%d/%d): %s", i+1, len(deletable), b.Name))
+		if err := s.gitClient.DeleteBranches(repoPath, []git.Branch{b}); err != nil {
+			failed = append(failed, s.explainFailures(repoPath, []git.Branch{b}, err)...)
+		} else {
+			deleted = append(deleted, b.Name)
+		}
+	}
+	reporter.Stop(fmt.Sprintf("Chopped %d branches", len(deleted)))
+	return deleted, failed
+}
+
+// explainFailures works out why deleting branches failed by comparing them
+// with the branches as they are now. Branches that passed verification were
+// only kept because others failed it.
+func (s *Service) explainFailures(repoPath string, branches []git.Branch, err error) []DeleteFailure {
+	current := make(map[string]string)
+	if listed, listErr := s.gitClient.ListBranches(repoPath); listErr == nil {
+		for _, b := range listed {
+			current[b.Name] = b.SHA
+		}
+	}
+
+	failures := make([]DeleteFailure, len(branches))
+	unverified := 0
+	for i, b := range branches {
+		failures[i].Branch = b.Name
+		sha, ok := current[b.Name]
+		switch {
+		case !ok:
+			failures[i].Reason = "no longer exists"
+		case sha != b.SHA:
+			failures[i].Reason = fmt.Sprintf("moved to %s since it was scanned", git.ShortSHA(sha))
+		default:
+			continue
+		}
+		unverified++
+	}
+	for i := range failures {
+		if failures[i].Reason != "" {
+			continue
+		}
+		if unverified > 0 {
+			failures[i].Reason = fmt.Sprintf("kept because %d branch(es) failed verification", unverified)
+		} else {
+			failures[i].Reason = err.Error()
+		}
+	}
+	return failures
+}
@@ -0,0 +1,169 @@
+package branch
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// Detection strategies decide whether a branch was merged
+const (
+	// StrategyPR looks for a merged PR on GitHub, which catches squash merges
+	StrategyPR = "pr"
+	// StrategyAncestor checks whether the branch tip is reachable from the
+	// default branch, which catches merge commits and fast-forwards
+	StrategyAncestor = "ancestor"
+	// StrategyRebase checks whether every commit on the branch has an
+	// equivalent patch on the default branch, which catches rebase merges
+	StrategyRebase = "rebase"
+	// StrategyPullRefs matches branch tips against fetched refs/pull/<n>/head
+	// refs and looks for a "Title (#n)" squash commit on the default branch,
+	// which identifies merged PRs without any API call
+	StrategyPullRefs = "pull-refs"
+)
+
+// EvidenceUpstreamGone records that the branch was deleted on the remote. It
+// corroborates a merge but is never enough on its own.
+const EvidenceUpstreamGone = "upstream-gone"
+
+// Strategies lists every detection strategy
+var Strategies = []string{StrategyPR, StrategyPullRefs, StrategyAncestor, StrategyRebase}
+
+// Evidence is a single signal that a branch was merged
+type Evidence struct {
+	// Kind is a detection strategy or EvidenceUpstreamGone
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// ValidateStrategies checks that every strategy is known
+func ValidateStrategies(strategies []string) error {
+	for _, strategy := range strategies {
+		if !slices.Contains(Strategies, strategy) {
+			return fmt.Errorf("invalid strategy %q (valid strategies: %s)", strategy, strings.Join(Strategies, ", "))
+		}
+	}
+	return nil
+}
+
+// mergedBy names the merge method the evidence points to: "merge" for
+// ancestry, "rebase" for equivalent patches and "squash" for a merged PR
+// whose commits aren't on the default branch. It is empty without evidence.
+func mergedBy(evidence []Evidence) string {
+	has := func(kind string) bool {
+		return slices.ContainsFunc(evidence, func(e Evidence) bool { return e.Kind == kind })
+	}
+	switch {
+	case has(StrategyAncestor):
+		return "merge"
+	case has(StrategyRebase):
+		return "rebase"
+	case has(StrategyPR), has(StrategyPullRefs):
+		return "squash"
+	default:
+		return ""
+	}
+}
+
+// squashScanLimit is how many commits of the default branch are searched
+// for squash merge subjects
+const squashScanLimit = 2000
+
+// squashSubject matches the "Title (#123)" subjects GitHub gives squash merges
+var squashSubject = regexp.MustCompile(`^(.*) \(#(\d+)\)$`)
+
+// localDetection is what the local strategies found out about each branch
+type localDetection struct {
+	evidence map[string][]Evidence
+	// prs holds merged PRs identified without any API call
+	prs map[string]*github.PRInfo
+}
+
+// detectLocally runs the local detection strategies against the default
+// branch. Branches pointing at the same commit as the default branch were
+// most likely just created, so they are never considered merged.
+func (s *Service) detectLocally(repoPath string, local localBranches, branches []string) localDetection {
+	det := localDetection{
+		evidence: make(map[string][]Evidence),
+		prs:      make(map[string]*github.PRInfo),
+	}
+
+	usePullRefs := slices.Contains(s.strategies, StrategyPullRefs)
+	useAncestor := slices.Contains(s.strategies, StrategyAncestor)
+	useRebase := slices.Contains(s.strategies, StrategyRebase)
+	if !usePullRefs && !useAncestor && !useRebase {
+		return det
+	}
+
+	defaultBranch, err := s.gitClient.GetDefaultBranch(repoPath)
+	if err != nil || defaultBranch == "" {
+		return det
+	}
+	defaultTip := local.byName[defaultBranch].SHA
+
+	var squashed map[string]*github.PRInfo
+	if usePullRefs {
+		squashed = s.squashedPullRefs(repoPath, defaultBranch)
+	}
+
+	for _, branch := range branches {
+		tip := local.byName[branch].SHA
+		if branch == defaultBranch || tip == defaultTip {
+			continue
+		}
+
+		if pr, ok := squashed[tip]; ok {
+			det.evidence[branch] = append(det.evidence[branch], Evidence{Kind: StrategyPullRefs, Detail: fmt.Sprintf("PR #%d squash-merged into %s", pr.Number, defaultBranch)})
+			det.prs[branch] = pr
+		}
+
+		if useAncestor {
+			if ok, err := s.gitClient.IsAncestor(repoPath, branch, defaultBranch); err == nil && ok {
+				det.evidence[branch] = append(det.evidence[branch], Evidence{Kind: StrategyAncestor, Detail: "reachable from " + defaultBranch})
+				continue
+			}
+		}
+
+		// No unapplied commits means every commit on the branch has an
+		// equivalent on the default branch
+		if useRebase {
+			if n, err := s.gitClient.CountUnappliedCommits(repoPath, defaultBranch, branch); err == nil && n == 0 {
+				det.evidence[branch] = append(det.evidence[branch], Evidence{Kind: StrategyRebase, Detail: "all commits applied to " + defaultBranch})
+			}
+		}
+	}
+	return det
+}
+
+// squashedPullRefs maps PR head SHAs from fetched pull refs to their PR when
+// a squash merge subject for that PR is on the default branch
+func (s *Service) squashedPullRefs(repoPath, defaultBranch string) map[string]*github.PRInfo {
+	heads, err := s.gitClient.GetPullRefs(repoPath)
+	if err != nil || len(heads) == 0 {
+		return nil
+	}
+	commits, err := s.gitClient.GetCommits(repoPath, defaultBranch, "", squashScanLimit)
+	if err != nil {
+		return nil
+	}
+
+	titles := make(map[int]string)
+	for _, c := range commits {
+		if m := squashSubject.FindStringSubmatch(c.Subject); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			titles[n] = m[1]
+		}
+	}
+
+	squashed := make(map[string]*github.PRInfo)
+	for sha, n := range heads {
+		if title, ok := titles[n]; ok {
+			squashed[sha] = &github.PRInfo{Number: n, State: "MERGED", Title: title}
+		}
+	}
+	return squashed
+}
@@ -0,0 +1,124 @@
+package branch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_GetMergedBranches_LocalStrategies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	gitMock.EXPECT().ListBranches(".").Return([]git.Branch{
+		{Name: "main", SHA: "m"}, {Name: "merged", SHA: "a"}, {Name: "rebased", SHA: "b"},
+		{Name: "squashed", SHA: "c"}, {Name: "fresh", SHA: "m"}, {Name: "wip", SHA: "d"},
+	}, nil)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+
+	// fresh points at main, so it's never checked
+	gitMock.EXPECT().IsAncestor(".", "merged", "main").Return(true, nil)
+	gitMock.EXPECT().IsAncestor(".", "rebased", "main").Return(false, nil)
+	gitMock.EXPECT().CountUnappliedCommits(".", "main", "rebased").Return(0, nil)
+	gitMock.EXPECT().IsAncestor(".", "squashed", "main").Return(false, nil)
+	gitMock.EXPECT().CountUnappliedCommits(".", "main", "squashed").Return(2, nil)
+	gitMock.EXPECT().IsAncestor(".", "wip", "main").Return(false, nil)
+	gitMock.EXPECT().CountUnappliedCommits(".", "main", "wip").Return(1, nil)
+
+	ghMock.EXPECT().GetMergedPR(".", "merged").Return(nil, nil)
+	ghMock.EXPECT().GetMergedPR(".", "rebased").Return(&github.PRInfo{Number: 2, State: "MERGED"}, nil)
+	ghMock.EXPECT().GetMergedPR(".", "squashed").Return(&github.PRInfo{Number: 3, State: "MERGED"}, nil)
+	ghMock.EXPECT().GetMergedPR(".", "fresh").Return(nil, nil)
+	ghMock.EXPECT().GetMergedPR(".", "wip").Return(nil, nil)
+
+	expectPlainRepo(gitMock)
+	service := NewService(gitMock, ghMock, WithStrategies(StrategyPR, StrategyAncestor, StrategyRebase))
+	branches, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, mb := range branches {
+		got[mb.Name] = mb.MergedBy
+	}
+	want := map[string]string{"merged": "merge", "rebased": "rebase", "squashed": "squash"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMergedBranches() merged_by = %v, want %v", got, want)
+	}
+	if len(branches[1].Evidence) != 2 {
+		t.Errorf("rebased evidence = %v, want the PR and rebase strategies", branches[1].Evidence)
+	}
+}
+
+func TestService_GetAllBranchStatuses_LocalStrategiesWithoutPRLookups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "merged", "wip"), nil)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+	gitMock.EXPECT().IsAncestor(".", "merged", "main").Return(true, nil)
+	gitMock.EXPECT().IsAncestor(".", "wip", "main").Return(false, nil)
+
+	expectPlainRepo(gitMock)
+	service := NewService(gitMock, ghMock, WithStrategies(StrategyAncestor))
+	statusMap, err := service.GetAllBranchStatuses(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetAllBranchStatuses() error = %v", err)
+	}
+	if len(statusMap["merged"]) != 1 || len(statusMap["no-pr"]) != 1 {
+		t.Errorf("GetAllBranchStatuses() = %v, want merged and wip without a PR", statusMap)
+	}
+}
+
+func TestService_GetMergedBranches_PullRefsOffline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	gitMock.EXPECT().ListBranches(".").Return([]git.Branch{
+		{Name: "main", SHA: "m"}, {Name: "squashed", SHA: "a"}, {Name: "open", SHA: "b"}, {Name: "unknown", SHA: "c"},
+	}, nil)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+	gitMock.EXPECT().GetPullRefs(".").Return(map[string]int{"a": 12, "b": 13}, nil)
+	gitMock.EXPECT().GetCommits(".", "main", "", squashScanLimit).Return([]git.Commit{
+		{Subject: "Add login (#12)"},
+		{Subject: "Mention #13 without merging it"},
+	}, nil)
+
+	// No GitHub lookups are made
+	expectPlainRepo(gitMock)
+	service := NewService(gitMock, ghMock, WithStrategies(StrategyPullRefs))
+	branches, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+
+	if len(branches) != 1 {
+		t.Fatalf("GetMergedBranches() = %+v, want only squashed", branches)
+	}
+	mb := branches[0]
+	if mb.Name != "squashed" || mb.PR == nil || mb.PR.Number != 12 || mb.PR.Title != "Add login" || mb.MergedBy != "squash" {
+		t.Errorf("GetMergedBranches() = %+v, want squashed as PR #12 \"Add login\"", mb)
+	}
+}
+
+func TestValidateStrategies(t *testing.T) {
+	if err := ValidateStrategies([]string{"pr", "rebase"}); err != nil {
+		t.Errorf("ValidateStrategies() error = %v, want nil", err)
+	}
+	if err := ValidateStrategies([]string{"octopus"}); err == nil {
+		t.Error("ValidateStrategies() with unknown strategy = nil, want error")
+	}
+}
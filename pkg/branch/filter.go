@@ -0,0 +1,175 @@
+package branch
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// Statuses lists every status a branch can be categorized as. "merged" means
+// merged into the default branch; "merged-into-other" means the PR was merged
+// into some other branch, so its commits may not be on the default branch yet;
+// "merged-not-pulled" means the merge commit hasn't been pulled;
+// "merged-reverted" means the merge was reverted on the default branch;
+// "queued" means an open PR is in a merge queue or has auto-merge enabled;
+// "kept" means a keep marker or a keep label on the PR protects the branch.
+var Statuses = []string{"merged", "merged-into-other", "merged-not-pulled", "merged-reverted", "queued", "open", "closed", "draft", "gone", "no-pr", "kept"}
+
+// Filter narrows the branches a Service acts on. Name, author and commit age
+// filters are applied before any GitHub lookup to save API calls; state, PR
+// number and PR close date filters need the lookup results.
+type Filter struct {
+	// Match keeps only branches matching one of these globs
+	Match []string
+	// Exclude drops branches matching any of these globs
+	Exclude []string
+	// States keeps only branches with one of these statuses
+	States []string
+	// OlderThan keeps only branches whose PR closed, or whose last commit
+	// was made, longer ago than this
+	OlderThan time.Duration
+	// Author keeps only branches with a commit by this email address
+	Author string
+	// PRs keeps only branches whose PR has one of these numbers
+	PRs []int
+	// NeedsAttention keeps only branches whose open PR is approved with
+	// passing checks, so it can be merged, or has failing checks
+	NeedsAttention bool
+}
+
+// IsZero reports whether the filter keeps every branch
+func (f Filter) IsZero() bool {
+	return len(f.Match) == 0 && len(f.Exclude) == 0 && len(f.States) == 0 &&
+		f.OlderThan == 0 && f.Author == "" && len(f.PRs) == 0 && !f.NeedsAttention
+}
+
+// Validate checks that the filter's globs and states are well formed
+func (f Filter) Validate() error {
+	if err := ValidatePatterns(append(slices.Clone(f.Match), f.Exclude...)); err != nil {
+		return err
+	}
+	for _, state := range f.States {
+		if !slices.Contains(Statuses, state) {
+			return fmt.Errorf("invalid state %q (valid states: %s)", state, strings.Join(Statuses, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidatePatterns checks that every glob is well formed
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := globToRegexp(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesName applies the Match and Exclude globs
+func (f Filter) matchesName(name string) bool {
+	if len(f.Match) > 0 && !matchAny(f.Match, name) {
+		return false
+	}
+	return !matchAny(f.Exclude, name)
+}
+
+// allowsState applies the States filter
+func (f Filter) allowsState(status string) bool {
+	return len(f.States) == 0 || slices.Contains(f.States, status)
+}
+
+// allowsPR applies the PRs filter
+func (f Filter) allowsPR(pr *github.PRInfo) bool {
+	if len(f.PRs) == 0 {
+		return true
+	}
+	return pr != nil && slices.Contains(f.PRs, pr.Number)
+}
+
+// allowsAttention applies the NeedsAttention filter
+func (f Filter) allowsAttention(pr *github.PRInfo) bool {
+	return !f.NeedsAttention || NeedsAttention(pr)
+}
+
+// NeedsAttention reports whether an open PR is waiting on its author: it's
+// approved with passing checks and only needs merging, or its checks fail
+func NeedsAttention(pr *github.PRInfo) bool {
+	if pr == nil || pr.State != "OPEN" {
+		return false
+	}
+	checks := pr.CheckState()
+	// A queued PR will be merged without anyone's help
+	ready := pr.ReviewDecision == "APPROVED" && (checks == github.ChecksPassing || checks == "") && !pr.Queued()
+	return ready || checks == github.ChecksFailing
+}
+
+// matchAny reports whether name matches any of the globs
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if re, err := globToRegexp(pattern); err == nil && re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp converts a glob to a regular expression. Like git's branch
+// patterns, * also matches /, so "*login*" matches "feature/login".
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// ageUnits maps the suffixes accepted by ParseAge to durations
+var ageUnits = map[string]time.Duration{
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"mo": 30 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+var agePattern = regexp.MustCompile(`^(\d+)(mo|m|h|d|w|y)$`)
+
+// ParseAge parses an age such as 45m, 12h, 30d, 2w, 6mo or 1y
+func ParseAge(s string) (time.Duration, error) {
+	m := agePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid age %q (use a number followed by m, h, d, w, mo or y)", s)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: %w", s, err)
+	}
+	return time.Duration(n) * ageUnits[m[2]], nil
+}
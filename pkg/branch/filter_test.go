@@ -0,0 +1,207 @@
+package branch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFilter_MatchesName(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		branch string
+		want   bool
+	}{
+		{"no globs", Filter{}, "feature/login", true},
+		{"match prefix", Filter{Match: []string{"feature/*"}}, "feature/login", true},
+		{"match miss", Filter{Match: []string{"feature/*"}}, "bugfix/typo", false},
+		{"star crosses slashes", Filter{Match: []string{"*login*"}}, "feature/login-form", true},
+		{"question mark", Filter{Match: []string{"v?"}}, "v2", true},
+		{"character class", Filter{Match: []string{"release-[0-9]*"}}, "release-3.1", true},
+		{"negated class", Filter{Match: []string{"[!r]*"}}, "release-3.1", false},
+		{"exclude wins", Filter{Match: []string{"feature/*"}, Exclude: []string{"*/wip*"}}, "feature/wip-login", false},
+		{"dots are literal", Filter{Match: []string{"v1.0"}}, "v1x0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matchesName(tt.branch); got != tt.want {
+				t.Errorf("matchesName(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_Validate(t *testing.T) {
+	if err := (Filter{States: []string{"merged", "closed"}, Match: []string{"feature/*"}}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := (Filter{States: []string{"gone-fishing"}}).Validate(); err == nil {
+		t.Error("Validate() with unknown state = nil, want error")
+	}
+	if err := (Filter{Exclude: []string{"[abc"}}).Validate(); err == nil {
+		t.Error("Validate() with unterminated class = nil, want error")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"45m", 45 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"6mo", 180 * 24 * time.Hour, false},
+		{"1y", 365 * 24 * time.Hour, false},
+		{"30", 0, true},
+		{"d30", 0, true},
+		{"1.5d", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAge(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestService_GetMergedBranches_FiltersBeforeLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	now := time.Unix(1700000000, 0)
+
+	gitMock.EXPECT().
+		ListBranches(".").
+		Return([]git.Branch{
+			{Name: "main"},
+			{Name: "feature/old", CommitDate: now.Add(-90 * 24 * time.Hour)},
+			{Name: "feature/new", CommitDate: now.Add(-time.Hour)},
+			{Name: "feature/theirs", CommitDate: now.Add(-90 * 24 * time.Hour)},
+			{Name: "feature/fresh", CommitDate: now.Add(-90 * 24 * time.Hour)},
+			{Name: "bugfix/typo", CommitDate: now.Add(-90 * 24 * time.Hour)},
+		}, nil)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+
+	// feature/new is too recent to need its commits read
+	gitMock.EXPECT().GetCommits(".", "feature/old", "main", 0).
+		Return([]git.Commit{{AuthorEmail: "other@example.com"}, {AuthorEmail: "Me@Example.com"}}, nil)
+	gitMock.EXPECT().GetCommits(".", "feature/theirs", "main", 0).
+		Return([]git.Commit{{AuthorEmail: "other@example.com"}}, nil)
+
+	// Without unique commits, the tip commit decides
+	gitMock.EXPECT().GetCommits(".", "feature/fresh", "main", 0).Return(nil, nil)
+	gitMock.EXPECT().GetCommits(".", "feature/fresh", "", 1).
+		Return([]git.Commit{{AuthorEmail: "other@example.com"}}, nil)
+
+	// Only feature/old survives the name, age and author filters
+	ghMock.EXPECT().
+		GetMergedPR(".", "feature/old").
+		Return(&github.PRInfo{Number: 1, State: "MERGED", ClosedAt: now.Add(-60 * 24 * time.Hour)}, nil)
+
+	expectPlainRepo(gitMock)
+	service := NewService(gitMock, ghMock, WithFilter(Filter{
+		Match:     []string{"feature/*"},
+		OlderThan: 30 * 24 * time.Hour,
+		Author:    "me@example.com",
+	}))
+	service.now = func() time.Time { return now }
+
+	branches, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+	if len(branches) != 1 || branches[0].Name != "feature/old" {
+		t.Errorf("GetMergedBranches() = %v, want [feature/old]", branches)
+	}
+}
+
+func TestService_GetMergedBranches_StateFilterSkipsLookups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	gitMock.EXPECT().ListBranches(".").Return(branchList("feature-1"), nil)
+
+	service := NewService(gitMock, ghMock, WithFilter(Filter{States: []string{"open"}}))
+	branches, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil || len(branches) != 0 {
+		t.Errorf("GetMergedBranches() = %v, %v, want no branches and no lookups", branches, err)
+	}
+}
+
+func TestService_GetAllBranchStatuses_PostLookupFilters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	now := time.Unix(1700000000, 0)
+
+	gitMock.EXPECT().ListBranches(".").Return(branchList("a", "b", "c", "d"), nil)
+	ghMock.EXPECT().GetPRStatus(".", "a").Return(&github.PRInfo{Number: 123, State: "MERGED", ClosedAt: now.Add(-48 * time.Hour)}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "b").Return(&github.PRInfo{Number: 456, State: "CLOSED", ClosedAt: now.Add(-time.Hour)}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "c").Return(&github.PRInfo{Number: 789, State: "OPEN"}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "d").Return(&github.PRInfo{Number: 456, State: "CLOSED", ClosedAt: now.Add(-72 * time.Hour)}, nil)
+
+	expectPlainRepo(gitMock)
+	expectNoMergeQueue(ghMock)
+	service := NewService(gitMock, ghMock, WithFilter(Filter{
+		States: []string{"merged", "closed"},
+		PRs:    []int{123, 456},
+	}))
+	service.now = func() time.Time { return now }
+
+	statusMap, err := service.GetAllBranchStatuses(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetAllBranchStatuses() error = %v", err)
+	}
+	if len(statusMap["merged"]) != 1 || len(statusMap["closed"]) != 2 || len(statusMap["open"]) != 0 {
+		t.Errorf("GetAllBranchStatuses() = %v, want a merged, b and d closed", statusMap)
+	}
+}
+
+func TestNeedsAttention(t *testing.T) {
+	passing := []github.Check{{Status: "COMPLETED", Conclusion: "SUCCESS"}}
+	failing := []github.Check{{Status: "COMPLETED", Conclusion: "FAILURE"}}
+	pending := []github.Check{{Status: "IN_PROGRESS"}}
+
+	tests := []struct {
+		name string
+		pr   *github.PRInfo
+		want bool
+	}{
+		{name: "no PR", pr: nil, want: false},
+		{name: "approved and green", pr: &github.PRInfo{State: "OPEN", ReviewDecision: "APPROVED", Checks: passing}, want: true},
+		{name: "approved without checks", pr: &github.PRInfo{State: "OPEN", ReviewDecision: "APPROVED"}, want: true},
+		{name: "approved with checks running", pr: &github.PRInfo{State: "OPEN", ReviewDecision: "APPROVED", Checks: pending}, want: false},
+		{name: "approved and already queued", pr: &github.PRInfo{State: "OPEN", ReviewDecision: "APPROVED", Checks: passing, InMergeQueue: true}, want: false},
+		{name: "failing checks", pr: &github.PRInfo{State: "OPEN", ReviewDecision: "REVIEW_REQUIRED", Checks: failing}, want: true},
+		{name: "awaiting review", pr: &github.PRInfo{State: "OPEN", ReviewDecision: "REVIEW_REQUIRED", Checks: passing}, want: false},
+		{name: "merged", pr: &github.PRInfo{State: "MERGED", ReviewDecision: "APPROVED", Checks: passing}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsAttention(tt.pr); got != tt.want {
+				t.Errorf("NeedsAttention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package branch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// KeepDateLayout is the format of the expiry date of a keep marker
+const KeepDateLayout = "2006-01-02"
+
+// KeepConfigKey is the git config key of a branch's keep marker. It holds
+// "true" to keep the branch for good, or the last day to keep it.
+func KeepConfigKey(branch string) string {
+	return "branch." + branch + ".axeKeep"
+}
+
+// Keep marks a branch so it's never chopped. A zero until keeps it for good,
+// otherwise it's kept through that day.
+func (s *Service) Keep(repoPath, branch string, until time.Time) error {
+	if err := s.requireBranch(repoPath, branch); err != nil {
+		return err
+	}
+	value := "true"
+	if !until.IsZero() {
+		value = until.Format(KeepDateLayout)
+	}
+	if err := s.gitClient.SetConfig(repoPath, KeepConfigKey(branch), value); err != nil {
+		return fmt.Errorf("failed to keep %q: %w", branch, err)
+	}
+	return nil
+}
+
+// Unkeep removes a branch's keep marker. Branches without one are left alone.
+func (s *Service) Unkeep(repoPath, branch string) error {
+	if err := s.gitClient.UnsetConfig(repoPath, KeepConfigKey(branch)); err != nil {
+		return fmt.Errorf("failed to unkeep %q: %w", branch, err)
+	}
+	return nil
+}
+
+// requireBranch checks that branch is a local branch
+func (s *Service) requireBranch(repoPath, branch string) error {
+	branches, err := s.gitClient.ListBranches(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	if !slices.ContainsFunc(branches, func(b git.Branch) bool { return b.Name == branch }) {
+		return fmt.Errorf("branch %q not found", branch)
+	}
+	return nil
+}
+
+// KeepReason explains why a branch must not be chopped, or returns "" if
+// nothing keeps it. A keep marker wins over a keep label on its PR.
+func (s *Service) KeepReason(repoPath, branch string, pr *github.PRInfo) string {
+	if reason := s.keepMarker(repoPath, branch); reason != "" {
+		return reason
+	}
+	if pr == nil {
+		return ""
+	}
+	for _, label := range s.keepLabels {
+		if pr.HasLabel(label) {
+			return fmt.Sprintf("PR #%d is labeled %s", pr.Number, label)
+		}
+	}
+	return ""
+}
+
+// keepMarker describes a branch's keep marker, or returns "" if it has none
+// or it expired. Markers that can't be read or parsed keep the branch, since
+// someone meant to keep it.
+func (s *Service) keepMarker(repoPath, branch string) string {
+	value, err := s.gitClient.GetConfig(repoPath, KeepConfigKey(branch))
+	if err != nil {
+		return fmt.Sprintf("keep marker unreadable: %v", err)
+	}
+	switch strings.ToLower(value) {
+	case "", "false", "no", "off", "0":
+		return ""
+	case "true", "yes", "on", "1":
+		return "kept with axe keep"
+	}
+	until, err := time.ParseInLocation(KeepDateLayout, value, time.Local)
+	if err != nil {
+		return fmt.Sprintf("kept with axe keep (%s)", value)
+	}
+	if !s.now().Before(until.AddDate(0, 0, 1)) {
+		return ""
+	}
+	return "kept with axe keep until " + value
+}
@@ -0,0 +1,162 @@
+package branch
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/cache"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_KeepReason(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	labeled := &github.PRInfo{Number: 4, Labels: []github.Label{{Name: "Keep-Branch"}}}
+
+	tests := []struct {
+		name   string
+		marker string
+		pr     *github.PRInfo
+		want   string
+	}{
+		{name: "nothing", want: ""},
+		{name: "marker", marker: "true", want: "kept with axe keep"},
+		{name: "marker turned off", marker: "false", want: ""},
+		{name: "marker until a later day", marker: "2026-12-01", want: "kept with axe keep until 2026-12-01"},
+		{name: "marker until today", marker: "2026-10-18", want: "kept with axe keep until 2026-10-18"},
+		{name: "expired marker", marker: "2026-10-17", want: ""},
+		{name: "unparseable marker", marker: "forever", want: "kept with axe keep (forever)"},
+		{name: "keep label", pr: labeled, want: "PR #4 is labeled keep-branch"},
+		{name: "other label", pr: &github.PRInfo{Number: 5, Labels: []github.Label{{Name: "bug"}}}, want: ""},
+		{name: "marker wins over label", marker: "true", pr: labeled, want: "kept with axe keep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			gitMock := git.NewMockClient(ctrl)
+			gitMock.EXPECT().GetConfig(".", "branch.feature.axeKeep").Return(tt.marker, nil)
+
+			service := NewService(gitMock, github.NewMockClient(ctrl), WithKeepLabels("keep-branch"))
+			service.now = func() time.Time { return now }
+			if got := service.KeepReason(".", "feature", tt.pr); got != tt.want {
+				t.Errorf("KeepReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_GetMergedAndKeptBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "done", "backport", "marked"), nil).Times(2)
+	for _, lookup := range []func(string, string) *gomock.Call{
+		func(repo, branch string) *gomock.Call { return ghMock.EXPECT().GetMergedPR(repo, branch) },
+		func(repo, branch string) *gomock.Call { return ghMock.EXPECT().GetPRStatus(repo, branch) },
+	} {
+		lookup(".", "done").Return(&github.PRInfo{Number: 1, State: "MERGED"}, nil)
+		lookup(".", "backport").Return(&github.PRInfo{Number: 2, State: "MERGED", Labels: []github.Label{{Name: "keep-branch"}}}, nil)
+	}
+	ghMock.EXPECT().GetMergedPR(".", "marked").Return(nil, nil)
+	ghMock.EXPECT().GetPRStatus(".", "marked").Return(&github.PRInfo{Number: 3, State: "OPEN"}, nil)
+	gitMock.EXPECT().GetConfig(".", "branch.marked.axeKeep").Return("true", nil).AnyTimes()
+	expectPlainRepo(gitMock)
+	expectNoMergeQueue(ghMock)
+
+	service := NewService(gitMock, ghMock, WithKeepLabels("keep-branch"))
+	merged, kept, err := service.GetMergedAndKeptBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedAndKeptBranches() error = %v", err)
+	}
+	if len(merged) != 1 || merged[0].Name != "done" {
+		t.Errorf("GetMergedAndKeptBranches() merged = %v, want done", merged)
+	}
+	if len(kept) != 1 || kept[0].Name != "backport" || kept[0].Kept != "PR #2 is labeled keep-branch" {
+		t.Errorf("GetMergedAndKeptBranches() kept = %v, want backport kept by its label", kept)
+	}
+
+	statuses, err := service.GetAllBranchStatuses(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetAllBranchStatuses() error = %v", err)
+	}
+	if got := statuses["kept"]; len(got) != 2 || got[0].Name != "backport" || got[1].Name != "marked" || got[1].Kept != "kept with axe keep" {
+		t.Errorf("kept = %v, want backport and marked", got)
+	}
+	if len(statuses["open"]) != 0 {
+		t.Errorf("open = %v, want the marked branch kept instead", statuses["open"])
+	}
+}
+
+func TestService_GetMergedAndKeptBranches_RefreshesCachedLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := cache.Open(filepath.Join(t.TempDir(), "prs.json"), cache.DefaultTTLs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "backport"), nil).Times(2)
+	gitMock.EXPECT().GetGitDir(".").Return("/repo/.git", nil).Times(2)
+	gitMock.EXPECT().GetConfig(".", "branch.backport.axeKeep").Return("", nil).AnyTimes()
+	expectPlainRepo(gitMock)
+
+	// The PR is cached before it's labeled, and only its labels are asked
+	// for again
+	ghMock.EXPECT().GetMergedPR(".", "backport").Return(&github.PRInfo{Number: 2, State: "MERGED"}, nil)
+	ghMock.EXPECT().GetLabels(".", []int{2}).Return(map[int][]github.Label{2: {{Name: "keep-branch"}}}, nil)
+
+	service := NewService(gitMock, ghMock, WithCache(store), WithKeepLabels("keep-branch"))
+	merged, kept, err := service.GetMergedAndKeptBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedAndKeptBranches() error = %v", err)
+	}
+	if len(merged) != 1 || len(kept) != 0 {
+		t.Fatalf("GetMergedAndKeptBranches() before labeling = %v, %v, want backport merged", merged, kept)
+	}
+
+	merged, kept, err = service.GetMergedAndKeptBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedAndKeptBranches() error = %v", err)
+	}
+	if len(merged) != 0 {
+		t.Errorf("GetMergedAndKeptBranches() merged = %v, want none", merged)
+	}
+	if len(kept) != 1 || kept[0].Kept != "PR #2 is labeled keep-branch" {
+		t.Errorf("GetMergedAndKeptBranches() kept = %v, want backport kept by its new label", kept)
+	}
+}
+
+func TestService_Keep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "backport"), nil).Times(3)
+	gitMock.EXPECT().SetConfig(".", "branch.backport.axeKeep", "true").Return(nil)
+	gitMock.EXPECT().SetConfig(".", "branch.backport.axeKeep", "2026-12-01").Return(nil)
+	gitMock.EXPECT().UnsetConfig(".", "branch.backport.axeKeep").Return(nil)
+
+	service := NewService(gitMock, github.NewMockClient(ctrl))
+	if err := service.Keep(".", "backport", time.Time{}); err != nil {
+		t.Errorf("Keep() error = %v", err)
+	}
+	if err := service.Keep(".", "backport", time.Date(2026, 12, 1, 0, 0, 0, 0, time.Local)); err != nil {
+		t.Errorf("Keep() until error = %v", err)
+	}
+	if err := service.Keep(".", "missing", time.Time{}); err == nil {
+		t.Error("Keep() of a missing branch = nil, want error")
+	}
+	if err := service.Unkeep(".", "backport"); err != nil {
+		t.Errorf("Unkeep() error = %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package branch
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// queuePollInterval is how often WaitForQueued checks on queued PRs
+const queuePollInterval = 20 * time.Second
+
+// GetQueuedBranches returns local branches whose open PR is in a merge queue
+// or has auto-merge enabled. Branches listed in skip are not looked up.
+func (s *Service) GetQueuedBranches(repoPath string, skip []string, reporter ProgressReporter) ([]BranchStatus, error) {
+	local, err := s.localCandidates(repoPath, reporter)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredBranches []string
+	for _, branch := range local.names() {
+		if !slices.Contains(skip, branch) {
+			filteredBranches = append(filteredBranches, branch)
+		}
+	}
+
+	if len(filteredBranches) == 0 {
+		return []BranchStatus{}, nil
+	}
+
+	reporter.Start(fmt.Sprintf("Looking for queued PRs (%d to check)...", len(filteredBranches)))
+	statuses := s.checkAllBranchesParallel(repoPath, local, filteredBranches, reporter)
+	reporter.Stop(fmt.Sprintf("Found %d branches with queued PRs", len(statuses["queued"])))
+
+	return statuses["queued"], nil
+}
+
+// WaitForQueued polls the PRs of queued branches until they merge, leave
+// the queue, or timeout passes. Branches whose PR merged into the default
+// branch are returned as merged once origin's default branch has been
+// fetched to verify the merge. The rest are returned in left with their
+// latest status: still "queued", or whatever they became instead.
+func (s *Service) WaitForQueued(repoPath string, queued []BranchStatus, timeout time.Duration, reporter ProgressReporter) (merged []MergedBranch, left []BranchStatus) {
+	lookup := func(branch string) (*github.PRInfo, error) {
+		return s.githubClient.GetPRStatus(repoPath, branch)
+	}
+
+	deadline := s.now().Add(timeout)
+	waiting := slices.Clone(queued)
+	reporter.Start(fmt.Sprintf("Waiting for %d queued PR(s) to merge...", len(waiting)))
+	for len(waiting) > 0 {
+		names := make([]string, len(waiting))
+		for i, bs := range waiting {
+			names[i] = bs.Name
+		}
+
+		var landed []BranchStatus
+		var still []BranchStatus
+		results := s.scheduler.run(names, lookup, reporter)
+		s.markMergeQueued(repoPath, results)
+		for i, result := range results {
+			bs := waiting[i]
+			switch {
+			case result.Err != nil:
+				// Keep waiting, the next poll may succeed
+				still = append(still, bs)
+			case result.PR != nil && result.PR.State == "MERGED":
+				bs.PR = result.PR
+				landed = append(landed, bs)
+			case result.PR != nil && result.PR.Queued():
+				bs.PR = result.PR
+				still = append(still, bs)
+			default:
+				// Dequeued, closed or auto-merge disabled
+				bs.PR = result.PR
+				bs.Status = statusFor(result.PR, nil)
+				left = append(left, bs)
+			}
+		}
+
+		if len(landed) > 0 {
+			verified, unverified := s.verifyLanded(repoPath, landed)
+			merged = append(merged, verified...)
+			left = append(left, unverified...)
+		}
+		waiting = still
+
+		remaining := deadline.Sub(s.now())
+		if len(waiting) == 0 || remaining <= 0 {
+			break
+		}
+		reporter.Update(fmt.Sprintf("Waiting for %d queued PR(s) to merge (%d merged, %s left)...", len(waiting), len(merged), remaining.Round(time.Second)))
+		if remaining > queuePollInterval {
+			remaining = queuePollInterval
+		}
+		s.sleep(remaining)
+	}
+
+	left = append(left, waiting...)
+	reporter.Stop(fmt.Sprintf("%d queued PR(s) merged, %d did not", len(merged), len(left)))
+	return merged, left
+}
+
+// verifyLanded fetches the default branch's upstream so the merge commits of
+// PRs that just merged can be verified like any other merged PR
+func (s *Service) verifyLanded(repoPath string, landed []BranchStatus) (merged []MergedBranch, unverified []BranchStatus) {
+	check := s.newMergeCheck(repoPath, nil)
+	// A failed fetch leaves the merges unverified
+	_ = check.fetchUpstream()
+
+	for _, bs := range landed {
+		status := check.status(bs.PR)
+		if status == "merged" && check.reverted(bs.PR, bs.SHA) {
+			status = "merged-reverted"
+		}
+		if status != "merged" {
+			bs.Status = status
+			unverified = append(unverified, bs)
+			continue
+		}
+		evidence := []Evidence{{Kind: StrategyPR, Detail: prMergedDetail(bs.PR)}}
+		merged = append(merged, MergedBranch{
+			Branch:   bs.Branch,
+			PR:       bs.PR,
+			Evidence: evidence,
+			MergedBy: mergedBy(evidence),
+		})
+	}
+	return merged, unverified
+}
@@ -0,0 +1,108 @@
+package branch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_GetQueuedBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "merged", "auto", "queue", "open"), nil)
+	ghMock.EXPECT().GetPRStatus(".", "auto").Return(&github.PRInfo{Number: 1, State: "OPEN", AutoMergeRequest: &github.AutoMergeRequest{MergeMethod: "SQUASH"}}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "queue").Return(&github.PRInfo{Number: 2, State: "OPEN"}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "open").Return(&github.PRInfo{Number: 3, State: "OPEN"}, nil)
+	// One query covers every open PR without auto-merge
+	ghMock.EXPECT().InMergeQueue(".", []int{2, 3}).Return(map[int]bool{2: true, 3: false}, nil)
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, ghMock)
+	queued, err := service.GetQueuedBranches(".", []string{"merged"}, &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetQueuedBranches() error = %v", err)
+	}
+	if len(queued) != 2 || queued[0].Name != "auto" || queued[1].Name != "queue" {
+		t.Errorf("GetQueuedBranches() = %v, want auto and queue", queued)
+	}
+}
+
+func TestService_WaitForQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	queuedPR := func(n int) *github.PRInfo {
+		return &github.PRInfo{Number: n, State: "OPEN", InMergeQueue: true}
+	}
+	openPR := func(n int) *github.PRInfo {
+		return &github.PRInfo{Number: n, State: "OPEN"}
+	}
+
+	// lands merges on the second poll, dequeued is pulled from the queue,
+	// slow is still queued when the timeout runs out
+	gomock.InOrder(
+		ghMock.EXPECT().GetPRStatus(".", "lands").Return(openPR(1), nil),
+		ghMock.EXPECT().GetPRStatus(".", "lands").Return(&github.PRInfo{Number: 1, State: "MERGED", BaseRefName: "main", MergeCommit: &github.Commit{OID: "abc123"}}, nil),
+	)
+	ghMock.EXPECT().GetPRStatus(".", "dequeued").Return(&github.PRInfo{Number: 2, State: "OPEN"}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "slow").Return(openPR(3), nil).Times(3)
+	gomock.InOrder(
+		ghMock.EXPECT().InMergeQueue(".", []int{1, 2, 3}).Return(map[int]bool{1: true, 3: true}, nil),
+		ghMock.EXPECT().InMergeQueue(".", []int{3}).Return(map[int]bool{3: true}, nil).Times(2),
+	)
+	gitMock.EXPECT().ListBranches(".").Return(trackedList("main"), nil)
+	gitMock.EXPECT().FetchBranch(".", "origin", "main").Return(nil)
+	gitMock.EXPECT().IsAncestor(".", "abc123", "main").Return(false, nil)
+	gitMock.EXPECT().IsAncestor(".", "abc123", "origin/main").Return(true, nil)
+	expectPlainRepo(gitMock)
+
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	service := NewService(gitMock, ghMock, WithScheduler(NewScheduler(SchedulerConfig{Concurrency: 1})))
+	service.now = clock.now
+	service.sleep = clock.sleep
+
+	var queued []BranchStatus
+	for i, name := range []string{"lands", "dequeued", "slow"} {
+		queued = append(queued, BranchStatus{Branch: git.Branch{Name: name, SHA: name}, Status: "queued", PR: queuedPR(i + 1)})
+	}
+	merged, left := service.WaitForQueued(".", queued, 30*time.Second, &mockReporter{})
+
+	if len(merged) != 1 || merged[0].Name != "lands" || merged[0].PR.State != "MERGED" {
+		t.Errorf("WaitForQueued() merged = %v, want lands", merged)
+	}
+	if len(left) != 2 || left[0].Name != "dequeued" || left[0].Status != "open" || left[1].Name != "slow" || left[1].Status != "queued" {
+		t.Errorf("WaitForQueued() left = %v, want dequeued (open) and slow (queued)", left)
+	}
+	if want := []time.Duration{20 * time.Second, 10 * time.Second}; len(clock.slept) != 2 || clock.slept[0] != want[0] || clock.slept[1] != want[1] {
+		t.Errorf("WaitForQueued() slept %v, want %v", clock.slept, want)
+	}
+}
+
+func TestService_WaitForQueued_UnpulledMerge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	ghMock.EXPECT().GetPRStatus(".", "lands").Return(&github.PRInfo{Number: 1, State: "MERGED", MergeCommit: &github.Commit{OID: "abc123"}}, nil)
+	gitMock.EXPECT().ListBranches(".").Return(trackedList("main"), nil)
+	gitMock.EXPECT().FetchBranch(".", "origin", "main").Return(errors.New("offline"))
+	gitMock.EXPECT().IsAncestor(".", "abc123", gomock.Any()).Return(false, nil).Times(2)
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, ghMock)
+	queued := []BranchStatus{{Branch: git.Branch{Name: "lands"}, Status: "queued"}}
+	merged, left := service.WaitForQueued(".", queued, time.Minute, &mockReporter{})
+	if len(merged) != 0 || len(left) != 1 || left[0].Status != "merged-not-pulled" {
+		t.Errorf("WaitForQueued() = %v, %v, want lands left as merged-not-pulled", merged, left)
+	}
+}
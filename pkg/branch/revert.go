@@ -0,0 +1,149 @@
+package branch
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// revertedCommit matches the line git revert adds to a revert's message
+var revertedCommit = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+
+// revertedMerge matches the line git revert -m 1 adds when reverting a merge
+// commit, which names the merge's first parent
+var revertedMerge = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40}), reversing\s+changes made to ([0-9a-f]{7,40})`)
+
+// reverts records what was reverted on the default branch and not reapplied
+type reverts struct {
+	// commits are the reverted commits, as written in the reverts
+	commits map[string]bool
+	// prs are the numbers of PRs whose squash commit was reverted
+	prs map[int]bool
+	// merges maps reverted merge commits to their first parent, so the
+	// branches they merged can be told apart
+	merges map[string]string
+	// undoes maps each revert commit to what it reverted, so reverting the
+	// revert can restore it
+	undoes map[string]revertTargets
+}
+
+type revertTargets struct {
+	commits []string
+	pr      int
+}
+
+// scanReverts finds the reverts among commits, which are newest first as
+// GetCommits returns them. A revert that was itself reverted doesn't count.
+func scanReverts(commits []git.Commit) reverts {
+	r := reverts{
+		commits: make(map[string]bool),
+		prs:     make(map[int]bool),
+		merges:  make(map[string]string),
+		undoes:  make(map[string]revertTargets),
+	}
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		var targets revertTargets
+		for _, m := range revertedCommit.FindAllStringSubmatch(c.Body, -1) {
+			targets.commits = append(targets.commits, m[1])
+		}
+		title, isRevert := revertedTitle(c.Subject)
+		if reapplied, ok := revertedTitle(title); ok {
+			// Revert "Revert "Title (#12)"" reapplies PR #12
+			if n, ok := squashNumber(reapplied); ok {
+				delete(r.prs, n)
+			}
+		} else if isRevert {
+			targets.pr, _ = squashNumber(title)
+		}
+		if len(targets.commits) == 0 && targets.pr == 0 {
+			continue
+		}
+
+		reapplied := false
+		for _, target := range targets.commits {
+			for sha, undone := range r.undoes {
+				if strings.HasPrefix(sha, target) {
+					r.restore(undone)
+					reapplied = true
+				}
+			}
+		}
+		if reapplied {
+			continue
+		}
+
+		for _, target := range targets.commits {
+			r.commits[target] = true
+		}
+		for _, m := range revertedMerge.FindAllStringSubmatch(c.Body, -1) {
+			r.merges[m[1]] = m[2]
+		}
+		if targets.pr != 0 {
+			r.prs[targets.pr] = true
+		}
+		r.undoes[c.SHA] = targets
+	}
+	return r
+}
+
+// restore forgets a revert that was reverted
+func (r reverts) restore(undone revertTargets) {
+	for _, sha := range undone.commits {
+		delete(r.commits, sha)
+		delete(r.merges, sha)
+	}
+	if undone.pr != 0 {
+		delete(r.prs, undone.pr)
+	}
+}
+
+// reverted reports whether a revert on the default branch undid the PR's
+// merge or squash commit, or the branch tip itself
+func (r reverts) reverted(pr *github.PRInfo, tip string) bool {
+	if pr != nil && r.prs[pr.Number] {
+		return true
+	}
+	var shas []string
+	if pr != nil && pr.MergeCommit != nil && pr.MergeCommit.OID != "" {
+		shas = append(shas, pr.MergeCommit.OID)
+	}
+	if tip != "" {
+		shas = append(shas, tip)
+	}
+	for target := range r.commits {
+		for _, sha := range shas {
+			if strings.HasPrefix(sha, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// revertedTitle returns the subject reverted by a `Revert "<subject>"`
+// commit. The revert may itself have been squash-merged from a PR, which
+// appends its own " (#n)".
+func revertedTitle(subject string) (string, bool) {
+	if m := squashSubject.FindStringSubmatch(subject); m != nil && strings.HasPrefix(m[1], `Revert "`) {
+		subject = m[1]
+	}
+	title, ok := strings.CutPrefix(subject, `Revert "`)
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(title, `"`)
+}
+
+// squashNumber returns the PR number of a "Title (#123)" squash subject
+func squashNumber(subject string) (int, bool) {
+	m := squashSubject.FindStringSubmatch(subject)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[2])
+	return n, err == nil
+}
@@ -0,0 +1,137 @@
+package branch
+
+import (
+	"testing"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestScanReverts(t *testing.T) {
+	squashed := &github.PRInfo{Number: 12, MergeCommit: &github.Commit{OID: "aaaa1111"}}
+
+	tests := []struct {
+		name    string
+		commits []git.Commit // newest first
+		pr      *github.PRInfo
+		tip     string
+		want    bool
+	}{
+		{
+			name:    "revert of the squash commit",
+			commits: []git.Commit{{SHA: "1111bbbb", Subject: `Revert "Add login (#12)"`, Body: "This reverts commit aaaa1111."}},
+			pr:      squashed,
+			want:    true,
+		},
+		{
+			name:    "revert PR squash-merged from GitHub",
+			commits: []git.Commit{{SHA: "1111bbbb", Subject: `Revert "Add login (#12)" (#13)`, Body: "Reverts octo/app#12"}},
+			pr:      &github.PRInfo{Number: 12},
+			want:    true,
+		},
+		{
+			name:    "revert of a merge commit",
+			commits: []git.Commit{{SHA: "1111bbbb", Subject: `Revert "Merge pull request #12 from octo/login"`, Body: "This reverts commit aaaa1111, reversing\nchanges made to bbbb2222."}},
+			pr:      squashed,
+			want:    true,
+		},
+		{
+			name:    "revert of a fast-forwarded branch tip",
+			commits: []git.Commit{{SHA: "1111bbbb", Subject: `Revert "Add login"`, Body: "This reverts commit cccc3333."}},
+			tip:     "cccc3333",
+			want:    true,
+		},
+		{
+			name: "revert that was reverted",
+			commits: []git.Commit{
+				{SHA: "2222bbbb", Subject: `Revert "Revert "Add login (#12)""`, Body: "This reverts commit 1111bbbb."},
+				{SHA: "1111bbbb", Subject: `Revert "Add login (#12)"`, Body: "This reverts commit aaaa1111."},
+			},
+			pr:   squashed,
+			want: false,
+		},
+		{
+			name:    "revert of another PR",
+			commits: []git.Commit{{SHA: "1111bbbb", Subject: `Revert "Add logout (#11)"`, Body: "This reverts commit eeee5555."}},
+			pr:      squashed,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scanReverts(tt.commits).reverted(tt.pr, tt.tip); got != tt.want {
+				t.Errorf("reverted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_RevertedBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "kept", "reverted"), nil).Times(2)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil).AnyTimes()
+	gitMock.EXPECT().GetCommits(".", "main", "", squashScanLimit).Return([]git.Commit{
+		{SHA: "1111bbbb", Subject: `Revert "Add login (#2)"`, Body: "This reverts commit 5555aaaa."},
+	}, nil).Times(2)
+	for _, lookup := range []func(string, string) *gomock.Call{
+		func(repo, branch string) *gomock.Call { return ghMock.EXPECT().GetMergedPR(repo, branch) },
+		func(repo, branch string) *gomock.Call { return ghMock.EXPECT().GetPRStatus(repo, branch) },
+	} {
+		lookup(".", "kept").Return(&github.PRInfo{Number: 1, State: "MERGED"}, nil)
+		lookup(".", "reverted").Return(&github.PRInfo{Number: 2, State: "MERGED"}, nil)
+	}
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, ghMock)
+	merged, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+	if len(merged) != 1 || merged[0].Name != "kept" {
+		t.Errorf("GetMergedBranches() = %v, want only kept", merged)
+	}
+
+	statuses, err := service.GetAllBranchStatuses(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetAllBranchStatuses() error = %v", err)
+	}
+	if got := statuses["merged-reverted"]; len(got) != 1 || got[0].Name != "reverted" {
+		t.Errorf("merged-reverted = %v, want [reverted]", got)
+	}
+}
+
+func TestService_RevertedMergeWithoutPR(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "kept", "reverted"), nil)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil).AnyTimes()
+	gitMock.EXPECT().GetCommits(".", "main", "", squashScanLimit).Return([]git.Commit{
+		{SHA: "1111bbbb", Subject: `Revert "Merge branch 'reverted'"`, Body: "This reverts commit aaaa1111, reversing\nchanges made to aaaa0000."},
+	}, nil)
+	for _, name := range []string{"kept", "reverted"} {
+		gitMock.EXPECT().IsAncestor(".", name, "main").Return(true, nil)
+	}
+	// kept was merged before the reverted merge, reverted by it
+	gitMock.EXPECT().IsAncestor(".", "kept", "aaaa1111").Return(true, nil)
+	gitMock.EXPECT().IsAncestor(".", "kept", "aaaa0000").Return(true, nil)
+	gitMock.EXPECT().IsAncestor(".", "reverted", "aaaa1111").Return(true, nil)
+	gitMock.EXPECT().IsAncestor(".", "reverted", "aaaa0000").Return(false, nil)
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, github.NewMockClient(ctrl), WithStrategies(StrategyAncestor), WithScheduler(NewScheduler(SchedulerConfig{Concurrency: 1})))
+	merged, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+	if len(merged) != 1 || merged[0].Name != "kept" {
+		t.Errorf("GetMergedBranches() = %v, want only kept", merged)
+	}
+}
@@ -0,0 +1,254 @@
+package branch
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// SchedulerConfig controls how PR lookups are scheduled
+type SchedulerConfig struct {
+	// Concurrency is the maximum number of lookups in flight at once
+	Concurrency int
+	// MaxRetries is how many times a transient failure is retried
+	MaxRetries int
+	// MaxRateLimitRetries is how many times a lookup is retried after waiting
+	// out a rate limit. Rate limits don't count against MaxRetries.
+	MaxRateLimitRetries int
+	// BaseDelay is the initial backoff delay for transient failures
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay for transient failures
+	MaxDelay time.Duration
+	// MaxRateLimitWait is the longest the scheduler will pause for a rate
+	// limit to reset before giving up on a lookup
+	MaxRateLimitWait time.Duration
+}
+
+// DefaultSchedulerConfig returns the scheduler settings used by the CLI
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		Concurrency:         10,
+		MaxRetries:          3,
+		MaxRateLimitRetries: 5,
+		BaseDelay:           500 * time.Millisecond,
+		MaxDelay:            10 * time.Second,
+		MaxRateLimitWait:    5 * time.Minute,
+	}
+}
+
+// lookupFunc performs a single PR lookup for a branch
+type lookupFunc func(branch string) (*github.PRInfo, error)
+
+// lookupResult is the outcome of a scheduled lookup
+type lookupResult struct {
+	Branch string
+	PR     *github.PRInfo
+	Err    error
+	// Cached is set when PR came from the cache rather than GitHub
+	Cached bool
+}
+
+// Scheduler runs PR lookups through a bounded worker pool. It retries
+// transient failures with jittered exponential backoff and, when GitHub
+// reports a rate limit, pauses all workers and halves its concurrency
+// instead of failing. Concurrency recovers gradually as lookups succeed.
+type Scheduler struct {
+	cfg SchedulerConfig
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	limit       int
+	inflight    int
+	successes   int
+	pausedUntil time.Time
+
+	// now, sleep and jitter are replaceable for testing
+	now    func() time.Time
+	sleep  func(time.Duration)
+	jitter func(time.Duration) time.Duration
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.MaxRateLimitRetries < 0 {
+		cfg.MaxRateLimitRetries = 0
+	}
+	s := &Scheduler{
+		cfg:    cfg,
+		limit:  cfg.Concurrency,
+		now:    time.Now,
+		sleep:  time.Sleep,
+		jitter: equalJitter,
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Concurrency returns the current adaptive concurrency limit
+func (s *Scheduler) Concurrency() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limit
+}
+
+// run looks up every branch and returns the results in input order
+func (s *Scheduler) run(branches []string, lookup lookupFunc, reporter ProgressReporter) []lookupResult {
+	results := make([]lookupResult, len(branches))
+	if len(branches) == 0 {
+		return results
+	}
+
+	numWorkers := min(s.cfg.Concurrency, len(branches))
+	indexChan := make(chan int, len(branches))
+	for i := range branches {
+		indexChan <- i
+	}
+	close(indexChan)
+
+	var processed int
+	var progressMu sync.Mutex
+	total := len(branches)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexChan {
+				branch := branches[idx]
+				pr, err := s.lookupWithRetry(branch, lookup, reporter)
+				results[idx] = lookupResult{Branch: branch, PR: pr, Err: err}
+
+				progressMu.Lock()
+				processed++
+				reporter.Update(fmt.Sprintf("Checking PR status (%d/%d)", processed, total))
+				progressMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// lookupWithRetry runs a single lookup, retrying retryable failures. Transient
+// failures and rate limits are counted separately, so waiting out a rate
+// limit doesn't use up the retries meant for flaky responses.
+func (s *Scheduler) lookupWithRetry(branch string, lookup lookupFunc, reporter ProgressReporter) (*github.PRInfo, error) {
+	retries, rateLimits := 0, 0
+	for {
+		s.acquire()
+		pr, err := lookup(branch)
+		s.release(err)
+
+		if err == nil {
+			return pr, nil
+		}
+		if !github.IsRetryable(err) {
+			return nil, err
+		}
+
+		var rle *github.RateLimitError
+		if errors.As(err, &rle) {
+			if rateLimits == s.cfg.MaxRateLimitRetries {
+				return nil, err
+			}
+			wait := rle.Wait(s.now())
+			if wait <= 0 {
+				wait = s.backoff(rateLimits)
+			}
+			if wait > s.cfg.MaxRateLimitWait {
+				return nil, err
+			}
+			rateLimits++
+			s.pause(wait)
+			reporter.Update(fmt.Sprintf("Rate limited by GitHub, resuming in %s", wait.Round(time.Second)))
+			continue
+		}
+
+		if retries == s.cfg.MaxRetries {
+			return nil, err
+		}
+		s.sleep(s.backoff(retries))
+		retries++
+	}
+}
+
+// backoff returns the jittered exponential delay for the given attempt
+func (s *Scheduler) backoff(attempt int) time.Duration {
+	delay := s.cfg.BaseDelay << attempt
+	if delay <= 0 || delay > s.cfg.MaxDelay {
+		delay = s.cfg.MaxDelay
+	}
+	return s.jitter(delay)
+}
+
+// acquire blocks until a lookup slot is free and no rate limit pause is active
+func (s *Scheduler) acquire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if wait := s.pausedUntil.Sub(s.now()); wait > 0 {
+			s.mu.Unlock()
+			s.sleep(wait)
+			s.mu.Lock()
+			continue
+		}
+		if s.inflight < s.limit {
+			s.inflight++
+			return
+		}
+		s.cond.Wait()
+	}
+}
+
+// release frees a lookup slot and adapts the concurrency limit: rate limits
+// halve it, while a full window of successes raises it by one
+func (s *Scheduler) release(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight--
+
+	var rle *github.RateLimitError
+	switch {
+	case errors.As(err, &rle):
+		s.limit = max(1, s.limit/2)
+		s.successes = 0
+	case err == nil && s.limit < s.cfg.Concurrency:
+		s.successes++
+		if s.successes >= s.limit {
+			s.limit++
+			s.successes = 0
+		}
+	}
+
+	s.cond.Broadcast()
+}
+
+// pause stops all workers from starting new lookups for the given duration
+func (s *Scheduler) pause(wait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until := s.now().Add(wait); until.After(s.pausedUntil) {
+		s.pausedUntil = until
+	}
+}
+
+// equalJitter returns a random duration in [d/2, d]
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
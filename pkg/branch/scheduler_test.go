@@ -0,0 +1,212 @@
+package branch
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// fakeClock provides a deterministic clock whose sleep advances time
+type fakeClock struct {
+	mu    sync.Mutex
+	t     time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	c.slept = append(c.slept, d)
+}
+
+func newTestScheduler(cfg SchedulerConfig) (*Scheduler, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	s := NewScheduler(cfg)
+	s.now = clock.now
+	s.sleep = clock.sleep
+	s.jitter = func(d time.Duration) time.Duration { return d }
+	return s, clock
+}
+
+func TestScheduler_PreservesInputOrder(t *testing.T) {
+	s, _ := newTestScheduler(DefaultSchedulerConfig())
+	branches := []string{"a", "b", "c", "d", "e"}
+
+	results := s.run(branches, func(branch string) (*github.PRInfo, error) {
+		return &github.PRInfo{Title: branch}, nil
+	}, &mockReporter{})
+
+	for i, r := range results {
+		if r.Branch != branches[i] || r.PR.Title != branches[i] {
+			t.Errorf("run() result[%d] = %s, want %s", i, r.Branch, branches[i])
+		}
+	}
+}
+
+func TestScheduler_RetriesTransientErrors(t *testing.T) {
+	cfg := DefaultSchedulerConfig()
+	cfg.BaseDelay = time.Second
+	s, clock := newTestScheduler(cfg)
+
+	calls := 0
+	results := s.run([]string{"feature"}, func(branch string) (*github.PRInfo, error) {
+		calls++
+		if calls < 3 {
+			return nil, &github.TransientError{Err: errors.New("timeout")}
+		}
+		return &github.PRInfo{Number: 1}, nil
+	}, &mockReporter{})
+
+	if results[0].Err != nil {
+		t.Fatalf("run() error = %v, want nil", results[0].Err)
+	}
+	if calls != 3 {
+		t.Errorf("lookup called %d times, want 3", calls)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second}
+	if len(clock.slept) != len(want) || clock.slept[0] != want[0] || clock.slept[1] != want[1] {
+		t.Errorf("backoff delays = %v, want %v", clock.slept, want)
+	}
+}
+
+func TestScheduler_DoesNotRetryPermanentErrors(t *testing.T) {
+	s, _ := newTestScheduler(DefaultSchedulerConfig())
+
+	calls := 0
+	results := s.run([]string{"feature"}, func(branch string) (*github.PRInfo, error) {
+		calls++
+		return nil, errors.New("not authenticated")
+	}, &mockReporter{})
+
+	if results[0].Err == nil {
+		t.Error("run() error = nil, want error")
+	}
+	if calls != 1 {
+		t.Errorf("lookup called %d times, want 1", calls)
+	}
+}
+
+func TestScheduler_GivesUpAfterMaxRetries(t *testing.T) {
+	cfg := DefaultSchedulerConfig()
+	cfg.MaxRetries = 2
+	s, _ := newTestScheduler(cfg)
+
+	calls := 0
+	results := s.run([]string{"feature"}, func(branch string) (*github.PRInfo, error) {
+		calls++
+		return nil, &github.TransientError{Err: errors.New("502 bad gateway")}
+	}, &mockReporter{})
+
+	if results[0].Err == nil {
+		t.Error("run() error = nil, want error")
+	}
+	if calls != 3 {
+		t.Errorf("lookup called %d times, want 3", calls)
+	}
+}
+
+func TestScheduler_WaitsForRateLimitReset(t *testing.T) {
+	cfg := DefaultSchedulerConfig()
+	cfg.Concurrency = 8
+	s, clock := newTestScheduler(cfg)
+
+	calls := 0
+	results := s.run([]string{"feature"}, func(branch string) (*github.PRInfo, error) {
+		calls++
+		if calls == 1 {
+			return nil, &github.RateLimitError{Reset: clock.now().Add(30 * time.Second)}
+		}
+		return &github.PRInfo{Number: 7}, nil
+	}, &mockReporter{})
+
+	if results[0].Err != nil || results[0].PR.Number != 7 {
+		t.Fatalf("run() = %+v, want PR #7", results[0])
+	}
+	if len(clock.slept) != 1 || clock.slept[0] != 30*time.Second {
+		t.Errorf("slept %v, want [30s]", clock.slept)
+	}
+	if got := s.Concurrency(); got != 4 {
+		t.Errorf("Concurrency() = %d after rate limit, want 4", got)
+	}
+}
+
+func TestScheduler_RateLimitsDontUseUpRetries(t *testing.T) {
+	cfg := DefaultSchedulerConfig()
+	cfg.MaxRetries = 1
+	cfg.MaxRateLimitRetries = 2
+	s, clock := newTestScheduler(cfg)
+
+	// Two rate limits and a transient failure all fit within the budgets
+	calls := 0
+	results := s.run([]string{"feature"}, func(branch string) (*github.PRInfo, error) {
+		calls++
+		switch calls {
+		case 1, 3:
+			return nil, &github.RateLimitError{Reset: clock.now().Add(10 * time.Second)}
+		case 2:
+			return nil, &github.TransientError{Err: errors.New("502 bad gateway")}
+		}
+		return &github.PRInfo{Number: 7}, nil
+	}, &mockReporter{})
+	if results[0].Err != nil || results[0].PR.Number != 7 {
+		t.Fatalf("run() = %+v, want PR #7", results[0])
+	}
+
+	// A third rate limit is one too many
+	calls = 0
+	results = s.run([]string{"feature"}, func(branch string) (*github.PRInfo, error) {
+		calls++
+		return nil, &github.RateLimitError{Reset: clock.now().Add(10 * time.Second)}
+	}, &mockReporter{})
+	var rle *github.RateLimitError
+	if !errors.As(results[0].Err, &rle) {
+		t.Errorf("run() error = %v, want RateLimitError", results[0].Err)
+	}
+	if calls != 3 {
+		t.Errorf("lookup called %d times, want 3", calls)
+	}
+}
+
+func TestScheduler_FailsWhenRateLimitResetTooFar(t *testing.T) {
+	cfg := DefaultSchedulerConfig()
+	cfg.MaxRateLimitWait = time.Minute
+	s, clock := newTestScheduler(cfg)
+
+	results := s.run([]string{"feature"}, func(branch string) (*github.PRInfo, error) {
+		return nil, &github.RateLimitError{Reset: clock.now().Add(time.Hour)}
+	}, &mockReporter{})
+
+	var rle *github.RateLimitError
+	if !errors.As(results[0].Err, &rle) {
+		t.Errorf("run() error = %v, want RateLimitError", results[0].Err)
+	}
+	if len(clock.slept) != 0 {
+		t.Errorf("slept %v, want no waiting", clock.slept)
+	}
+}
+
+func TestScheduler_RecoversConcurrency(t *testing.T) {
+	cfg := DefaultSchedulerConfig()
+	cfg.Concurrency = 4
+	s, _ := newTestScheduler(cfg)
+	s.limit = 1
+
+	branches := []string{"a", "b", "c", "d", "e", "f"}
+	s.run(branches, func(branch string) (*github.PRInfo, error) {
+		return nil, nil
+	}, &mockReporter{})
+
+	if got := s.Concurrency(); got <= 1 {
+		t.Errorf("Concurrency() = %d after successes, want > 1", got)
+	}
+}
@@ -2,24 +2,37 @@ package branch
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
-	"sync/atomic"
+	"time"
 
+	"github.com/nikzadkhani/axe/pkg/cache"
 	"github.com/nikzadkhani/axe/pkg/git"
 	"github.com/nikzadkhani/axe/pkg/github"
 )
 
 // MergedBranch represents a branch with its associated merged PR
 type MergedBranch struct {
-	Name string
-	PR   *github.PRInfo
+	git.Branch
+	// PR is the merged PR, or nil if the merge was only detected locally
+	PR *github.PRInfo
+	// Evidence lists every signal that the branch was merged
+	Evidence []Evidence
+	// MergedBy names the merge method: "merge", "rebase" or "squash"
+	MergedBy string
+	// Kept explains why the branch is kept although it was merged, or is
+	// empty if it can be chopped
+	Kept string
 }
 
 // BranchStatus represents a branch with its PR status
 type BranchStatus struct {
-	Name   string
-	Status string // "merged", "open", "closed", "draft", "no-pr"
+	git.Branch
+	Status string // one of Statuses
 	PR     *github.PRInfo
+	// Kept explains why the branch is kept, for the "kept" status
+	Kept string
 }
 
 // ProgressReporter is an interface for reporting progress during operations
@@ -30,235 +43,746 @@ type ProgressReporter interface {
 	StopWithError(msg string)
 }
 
+// RecoveryRefPrefix is where recovery points for deleted branches are stored
+const RecoveryRefPrefix = "refs/axe/recovery/"
+
 // Service orchestrates git and GitHub operations for branch management
 type Service struct {
 	gitClient    git.Client
 	githubClient github.Client
+	scheduler    *Scheduler
+	cache        *cache.Store
+	filter       Filter
+	strategies   []string
+	acceptBases  []string
+	keepLabels   []string
+	noVerify     bool
+	perBranch    bool
+	now          func() time.Time
+	sleep        func(time.Duration)
+
+	mu            sync.Mutex
+	failedLookups map[string]error
+	warnings      []string
+}
+
+// Option configures optional Service behavior
+type Option func(*Service)
+
+// WithScheduler sets the scheduler used for PR lookups. Sharing one
+// scheduler between services shares its concurrency and rate limit state.
+func WithScheduler(scheduler *Scheduler) Option {
+	return func(s *Service) {
+		s.scheduler = scheduler
+	}
+}
+
+// WithConcurrency sets the maximum number of concurrent PR lookups
+func WithConcurrency(n int) Option {
+	return func(s *Service) {
+		cfg := DefaultSchedulerConfig()
+		cfg.Concurrency = n
+		s.scheduler = NewScheduler(cfg)
+	}
+}
+
+// WithCache enables the persistent PR lookup cache
+func WithCache(store *cache.Store) Option {
+	return func(s *Service) {
+		s.cache = store
+	}
+}
+
+// WithFilter narrows the branches the Service acts on
+func WithFilter(filter Filter) Option {
+	return func(s *Service) {
+		s.filter = filter
+	}
+}
+
+// WithStrategies sets the strategies used to detect merged branches
+func WithStrategies(strategies ...string) Option {
+	return func(s *Service) {
+		s.strategies = strategies
+	}
+}
+
+// WithAcceptedBases counts PRs merged into branches matching these globs as
+// merged, like PRs merged into the default branch
+func WithAcceptedBases(patterns ...string) Option {
+	return func(s *Service) {
+		s.acceptBases = patterns
+	}
+}
+
+// WithKeepLabels keeps branches whose PR carries one of these labels, even
+// once the PR is merged
+func WithKeepLabels(labels ...string) Option {
+	return func(s *Service) {
+		s.keepLabels = labels
+	}
+}
+
+// WithoutLocalVerification trusts merged PRs even when their merge commit
+// hasn't been pulled
+func WithoutLocalVerification() Option {
+	return func(s *Service) {
+		s.noVerify = true
+	}
+}
+
+// WithPerBranchDeletion deletes branches one at a time, so a branch that
+// can't be deleted doesn't stop the others
+func WithPerBranchDeletion() Option {
+	return func(s *Service) {
+		s.perBranch = true
+	}
 }
 
-// NewService creates a new branch Service
-func NewService(gitClient git.Client, githubClient github.Client) *Service {
-	return &Service{
+// NewService creates a new branch Service. Merged branches are detected with
+// PR lookups only, unless WithStrategies enables the local strategies.
+func NewService(gitClient git.Client, githubClient github.Client, opts ...Option) *Service {
+	s := &Service{
 		gitClient:    gitClient,
 		githubClient: githubClient,
+		scheduler:    NewScheduler(DefaultSchedulerConfig()),
+		strategies:   []string{StrategyPR},
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// GetMergedBranches returns all local branches that have been squash-merged on GitHub
+// GetMergedBranches returns all local branches that have been squash-merged
+// on GitHub, leaving out those kept by a keep marker or label
 func (s *Service) GetMergedBranches(repoPath string, reporter ProgressReporter) ([]MergedBranch, error) {
+	merged, _, err := s.GetMergedAndKeptBranches(repoPath, reporter)
+	return merged, err
+}
+
+// GetMergedAndKeptBranches is GetMergedBranches, but also returns the merged
+// branches that are kept, with the reason in their Kept field
+func (s *Service) GetMergedAndKeptBranches(repoPath string, reporter ProgressReporter) (merged, kept []MergedBranch, err error) {
+	local, err := s.localCandidates(repoPath, reporter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(local.candidates) == 0 || !s.filter.allowsState("merged") {
+		return []MergedBranch{}, nil, nil
+	}
+
+	// Check each branch for merged PRs (parallelized)
+	reporter.Start(fmt.Sprintf("Looking for branches to chop (%d to check)...", len(local.candidates)))
+	merged = []MergedBranch{}
+	for _, mb := range s.checkBranchesParallel(repoPath, local, local.names(), reporter) {
+		if mb.Kept != "" {
+			kept = append(kept, mb)
+		} else {
+			merged = append(merged, mb)
+		}
+	}
+	if len(kept) > 0 {
+		reporter.Stop(fmt.Sprintf("Found %d branches ready to axe, %d kept", len(merged), len(kept)))
+	} else {
+		reporter.Stop(fmt.Sprintf("Found %d branches ready to axe", len(merged)))
+	}
+
+	return merged, kept, nil
+}
+
+// localBranches is a snapshot of the local branches from one ListBranches call
+type localBranches struct {
+	byName map[string]git.Branch
+	// candidates are the branches that may be acted on
+	candidates []git.Branch
+}
+
+// names returns the names of the candidates
+func (l localBranches) names() []string {
+	names := make([]string, len(l.candidates))
+	for i, b := range l.candidates {
+		names[i] = b.Name
+	}
+	return names
+}
+
+// localCandidates lists the local branches. Every branch except main and
+// master that passes the pre-lookup filters is a candidate.
+func (s *Service) localCandidates(repoPath string, reporter ProgressReporter) (localBranches, error) {
 	// Get all local branches
 	reporter.Start("Fetching local branches...")
-	branches, err := s.gitClient.GetLocalBranches(repoPath)
+	branches, err := s.gitClient.ListBranches(repoPath)
 	if err != nil {
 		reporter.StopWithError(fmt.Sprintf("Failed to fetch local branches: %v", err))
-		return nil, err
+		return localBranches{}, err
 	}
 	reporter.Stop(fmt.Sprintf("Found %d local branches", len(branches)))
 
-	// Filter out main/master branches
-	var filteredBranches []string
-	for _, branch := range branches {
-		if branch != "main" && branch != "master" && branch != "" {
-			filteredBranches = append(filteredBranches, branch)
+	local := localBranches{byName: make(map[string]git.Branch, len(branches))}
+	var filteredBranches []git.Branch
+	for _, b := range branches {
+		local.byName[b.Name] = b
+		// Filter out main/master branches
+		if b.Name != "main" && b.Name != "master" {
+			filteredBranches = append(filteredBranches, b)
+		}
+	}
+
+	local.candidates = s.preFilter(repoPath, filteredBranches)
+	return local, nil
+}
+
+// checkBranchesParallel looks up merged PRs for the branches concurrently
+// and combines them with the local detection strategies. A PR merged into a
+// branch other than the default branch isn't evidence on its own, since its
+// commits may not have reached the default branch yet, and neither is one
+// whose merge commit hasn't been pulled. Branches whose merge was reverted
+// are left out too.
+func (s *Service) checkBranchesParallel(repoPath string, local localBranches, branches []string, reporter ProgressReporter) []MergedBranch {
+	lookup := func(branch string) (*github.PRInfo, error) {
+		return s.githubClient.GetMergedPR(repoPath, branch)
+	}
+
+	detected := s.detectLocally(repoPath, local, branches)
+	check := s.newMergeCheck(repoPath, local.byName)
+
+	var mergedBranches []MergedBranch
+	for _, result := range s.lookupPRs(repoPath, local, branches, cache.KindMerged, lookup, reporter) {
+		var evidence []Evidence
+		if result.Err == nil && result.PR != nil && check.status(result.PR) == "merged" {
+			evidence = append(evidence, Evidence{Kind: StrategyPR, Detail: prMergedDetail(result.PR)})
 		}
+		evidence = append(evidence, detected.evidence[result.Branch]...)
+
+		// Fall back to the PR identified offline
+		pr := result.PR
+		if pr == nil {
+			pr = detected.prs[result.Branch]
+		}
+
+		// Only keep branches with evidence of a merge that wasn't reverted
+		b := local.byName[result.Branch]
+		if len(evidence) == 0 || check.reverted(pr, b.SHA) || !s.postFilter("merged", pr) {
+			continue
+		}
+		if b.Upstream.Gone {
+			evidence = append(evidence, Evidence{Kind: EvidenceUpstreamGone, Detail: b.Upstream.Ref + " was deleted"})
+		}
+
+		mergedBranches = append(mergedBranches, MergedBranch{
+			Branch:   b,
+			PR:       pr,
+			Evidence: evidence,
+			MergedBy: mergedBy(evidence),
+			Kept:     s.KeepReason(repoPath, b.Name, pr),
+		})
 	}
 
-	if len(filteredBranches) == 0 {
-		return []MergedBranch{}, nil
+	return mergedBranches
+}
+
+// prMergedDetail describes a merged PR as evidence
+func prMergedDetail(pr *github.PRInfo) string {
+	if pr.BaseRefName == "" {
+		return fmt.Sprintf("PR #%d merged", pr.Number)
 	}
+	return fmt.Sprintf("PR #%d merged into %s", pr.Number, pr.BaseRefName)
+}
 
-	// Check each branch for merged PRs (parallelized)
-	reporter.Start(fmt.Sprintf("Looking for branches to chop (%d to check)...", len(filteredBranches)))
-	mergedBranches := s.checkBranchesParallel(repoPath, filteredBranches, reporter)
-	reporter.Stop(fmt.Sprintf("Found %d branches ready to axe", len(mergedBranches)))
-
-	return mergedBranches, nil
-}
-
-// checkBranchesParallel checks multiple branches concurrently using a worker pool
-func (s *Service) checkBranchesParallel(repoPath string, branches []string, reporter ProgressReporter) []MergedBranch {
-	// Use a worker pool to limit concurrent API calls
-	const maxWorkers = 10
-	numWorkers := min(maxWorkers, len(branches))
-
-	// Channels for work distribution
-	branchChan := make(chan string, len(branches))
-	resultChan := make(chan MergedBranch, len(branches))
-
-	// Progress tracking
-	var processed atomic.Int32
-	total := int32(len(branches))
-
-	// Start workers
-	var wg sync.WaitGroup
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for branch := range branchChan {
-				pr, err := s.githubClient.GetMergedPR(repoPath, branch)
-
-				// Update progress
-				count := processed.Add(1)
-				reporter.Update(fmt.Sprintf("Checking PR status (%d/%d)", count, total))
-
-				// Only send result if branch has a merged PR
-				if err == nil && pr != nil {
-					resultChan <- MergedBranch{
-						Name: branch,
-						PR:   pr,
-					}
-				}
-			}
-		}()
+// CurrentPRs looks up the most recent PR of each branch on GitHub. The cache
+// is skipped so the answer reflects GitHub right now. Branches whose lookup
+// failed are returned in failed.
+func (s *Service) CurrentPRs(repoPath string, branches []string, reporter ProgressReporter) (prs map[string]*github.PRInfo, failed map[string]error) {
+	lookup := func(branch string) (*github.PRInfo, error) {
+		return s.githubClient.GetPRStatus(repoPath, branch)
 	}
 
-	// Send work to workers
-	for _, branch := range branches {
-		branchChan <- branch
+	reporter.Start(fmt.Sprintf("Checking PRs for %d branches...", len(branches)))
+	prs = make(map[string]*github.PRInfo, len(branches))
+	failed = make(map[string]error)
+	for _, result := range s.scheduler.run(branches, lookup, reporter) {
+		if result.Err != nil {
+			failed[result.Branch] = result.Err
+			continue
+		}
+		prs[result.Branch] = result.PR
 	}
-	close(branchChan)
+	reporter.Stop(fmt.Sprintf("Checked PRs for %d branches", len(branches)))
+	return prs, failed
+}
 
-	// Wait for all workers to finish, then close results
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+// GetClosedBranches returns local branches whose most recent PR was closed
+// without merging at least olderThan ago. Branches listed in skip, typically
+// those already known to be merged, are not looked up.
+func (s *Service) GetClosedBranches(repoPath string, olderThan time.Duration, skip []string, reporter ProgressReporter) ([]BranchStatus, error) {
+	local, err := s.localCandidates(repoPath, reporter)
+	if err != nil {
+		return nil, err
+	}
 
-	// Collect results
-	var mergedBranches []MergedBranch
-	for result := range resultChan {
-		mergedBranches = append(mergedBranches, result)
+	var filteredBranches []string
+	for _, branch := range local.names() {
+		if !slices.Contains(skip, branch) {
+			filteredBranches = append(filteredBranches, branch)
+		}
 	}
 
-	return mergedBranches
+	if len(filteredBranches) == 0 || !s.filter.allowsState("closed") {
+		return []BranchStatus{}, nil
+	}
+
+	reporter.Start(fmt.Sprintf("Looking for closed PRs (%d to check)...", len(filteredBranches)))
+	statuses := s.checkAllBranchesParallel(repoPath, local, filteredBranches, reporter)
+
+	var closed []BranchStatus
+	for _, bs := range statuses["closed"] {
+		if olderThan > 0 && s.now().Sub(bs.PR.ClosedAt) < olderThan {
+			continue
+		}
+		closed = append(closed, bs)
+	}
+	reporter.Stop(fmt.Sprintf("Found %d branches with closed PRs", len(closed)))
+
+	return closed, nil
 }
 
-// DeleteBranches deletes the specified branches
-func (s *Service) DeleteBranches(repoPath string, branches []string, reporter ProgressReporter) (deleted []string, failed []string) {
-	reporter.Start(fmt.Sprintf("Chopping %d branches...", len(branches)))
-	for i, branch := range branches {
-		reporter.Update(fmt.Sprintf("Chopping (%d/%d): %s", i+1, len(branches), branch))
-		err := s.gitClient.DeleteBranch(repoPath, branch)
-		if err != nil {
-			failed = append(failed, branch)
-		} else {
-			deleted = append(deleted, branch)
+// GetGoneBranches returns local branches whose upstream was deleted on the
+// remote but for which no PR was found. Branches listed in skip, typically
+// those already known to be merged, are not looked up.
+func (s *Service) GetGoneBranches(repoPath string, skip []string, reporter ProgressReporter) ([]BranchStatus, error) {
+	local, err := s.localCandidates(repoPath, reporter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.filter.allowsState("gone") {
+		return []BranchStatus{}, nil
+	}
+
+	// Only branches with a gone upstream need a lookup
+	var gone []string
+	for _, b := range local.candidates {
+		if b.Upstream.Gone && !slices.Contains(skip, b.Name) {
+			gone = append(gone, b.Name)
 		}
 	}
-	reporter.Stop(fmt.Sprintf("Chopped %d branches", len(deleted)))
-	return deleted, failed
+
+	if len(gone) == 0 {
+		return []BranchStatus{}, nil
+	}
+
+	reporter.Start(fmt.Sprintf("Checking branches with a gone upstream (%d to check)...", len(gone)))
+	statuses := s.checkAllBranchesParallel(repoPath, local, gone, reporter)
+	reporter.Stop(fmt.Sprintf("Found %d branches with a gone upstream and no PR", len(statuses["gone"])))
+
+	return statuses["gone"], nil
 }
 
-// GetAllBranchStatuses returns all local branches with their PR status
-func (s *Service) GetAllBranchStatuses(repoPath string, reporter ProgressReporter) (map[string][]BranchStatus, error) {
-	// Get all local branches
-	reporter.Start("Fetching local branches...")
-	branches, err := s.gitClient.GetLocalBranches(repoPath)
+// GetRevertedBranches returns local branches whose merge was reverted on the
+// default branch. Branches listed in skip are not looked up.
+func (s *Service) GetRevertedBranches(repoPath string, skip []string, reporter ProgressReporter) ([]BranchStatus, error) {
+	local, err := s.localCandidates(repoPath, reporter)
 	if err != nil {
-		reporter.StopWithError(fmt.Sprintf("Failed to fetch local branches: %v", err))
 		return nil, err
 	}
-	reporter.Stop(fmt.Sprintf("Found %d local branches", len(branches)))
 
-	// Filter out main/master branches
 	var filteredBranches []string
-	for _, branch := range branches {
-		if branch != "main" && branch != "master" && branch != "" {
+	for _, branch := range local.names() {
+		if !slices.Contains(skip, branch) {
 			filteredBranches = append(filteredBranches, branch)
 		}
 	}
 
-	if len(filteredBranches) == 0 {
+	if len(filteredBranches) == 0 || !s.filter.allowsState("merged-reverted") {
+		return []BranchStatus{}, nil
+	}
+
+	reporter.Start(fmt.Sprintf("Looking for reverted merges (%d to check)...", len(filteredBranches)))
+	statuses := s.checkAllBranchesParallel(repoPath, local, filteredBranches, reporter)
+	reporter.Stop(fmt.Sprintf("Found %d branches whose merge was reverted", len(statuses["merged-reverted"])))
+
+	return statuses["merged-reverted"], nil
+}
+
+// CreateRecoveryPoint saves the current tip of each branch under
+// refs/axe/recovery/<timestamp>/ so deleted branches can be restored. It
+// returns the ref prefix used.
+func (s *Service) CreateRecoveryPoint(repoPath string, branches []string) (string, error) {
+	local, err := s.gitClient.ListBranches(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create recovery point: %w", err)
+	}
+	tips := make(map[string]string, len(local))
+	for _, b := range local {
+		tips[b.Name] = b.SHA
+	}
+
+	prefix := RecoveryPoint(s.now())
+	for _, branch := range branches {
+		sha, ok := tips[branch]
+		if !ok {
+			return "", fmt.Errorf("failed to create recovery point: branch %q not found", branch)
+		}
+		if err := s.gitClient.CreateRef(repoPath, prefix+"/"+branch, sha); err != nil {
+			return "", fmt.Errorf("failed to create recovery point: %w", err)
+		}
+	}
+	return prefix, nil
+}
+
+// RecoveryPoint names the recovery point created at t. Branches saved in it
+// are stored under <recovery point>/<branch>.
+func RecoveryPoint(t time.Time) string {
+	return RecoveryRefPrefix + t.Format("20060102-150405")
+}
+
+// GetAllBranchStatuses returns all local branches with their PR status
+func (s *Service) GetAllBranchStatuses(repoPath string, reporter ProgressReporter) (map[string][]BranchStatus, error) {
+	local, err := s.localCandidates(repoPath, reporter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(local.candidates) == 0 {
 		return map[string][]BranchStatus{}, nil
 	}
 
 	// Check each branch for PR status (parallelized)
-	reporter.Start(fmt.Sprintf("Checking PR status for %d branches...", len(filteredBranches)))
-	statuses := s.checkAllBranchesParallel(repoPath, filteredBranches, reporter)
-	reporter.Stop(fmt.Sprintf("Completed status check for %d branches", len(filteredBranches)))
+	reporter.Start(fmt.Sprintf("Checking PR status for %d branches...", len(local.candidates)))
+	statuses := s.checkAllBranchesParallel(repoPath, local, local.names(), reporter)
+	reporter.Stop(fmt.Sprintf("Completed status check for %d branches", len(local.candidates)))
 
 	return statuses, nil
 }
 
-// checkAllBranchesParallel checks all branches concurrently and categorizes them by status
-func (s *Service) checkAllBranchesParallel(repoPath string, branches []string, reporter ProgressReporter) map[string][]BranchStatus {
-	// Use a worker pool to limit concurrent API calls
-	const maxWorkers = 10
-	numWorkers := min(maxWorkers, len(branches))
-
-	// Channels for work distribution
-	branchChan := make(chan string, len(branches))
-	resultChan := make(chan BranchStatus, len(branches))
-
-	// Progress tracking
-	var processed atomic.Int32
-	total := int32(len(branches))
-
-	// Start workers
-	var wg sync.WaitGroup
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for branch := range branchChan {
-				pr, err := s.githubClient.GetPRStatus(repoPath, branch)
-
-				// Update progress
-				count := processed.Add(1)
-				reporter.Update(fmt.Sprintf("Checking PR status (%d/%d)", count, total))
-
-				// Determine status
-				var status string
-				if err != nil || pr == nil {
-					status = "no-pr"
-				} else if pr.IsDraft {
-					status = "draft"
-				} else if pr.State == "MERGED" {
-					status = "merged"
-				} else if pr.State == "OPEN" {
-					status = "open"
-				} else if pr.State == "CLOSED" {
-					status = "closed"
-				} else {
-					status = "no-pr"
-				}
-
-				resultChan <- BranchStatus{
-					Name:   branch,
-					Status: status,
-					PR:     pr,
-				}
+// checkAllBranchesParallel looks up PR status for the branches concurrently
+// and categorizes them by status. Branches without a PR whose upstream is
+// gone are categorized as "gone", branches whose PR was merged into another
+// branch as "merged-into-other", branches whose merge commit isn't local as
+// "merged-not-pulled", and branches without an open PR that the
+// local strategies found merged as "merged", or "merged-reverted" when the
+// merge was reverted on the default branch.
+func (s *Service) checkAllBranchesParallel(repoPath string, local localBranches, branches []string, reporter ProgressReporter) map[string][]BranchStatus {
+	lookup := func(branch string) (*github.PRInfo, error) {
+		return s.githubClient.GetPRStatus(repoPath, branch)
+	}
+
+	statusMap := map[string][]BranchStatus{
+		"merged":            {},
+		"merged-into-other": {},
+		"merged-not-pulled": {},
+		"merged-reverted":   {},
+		"queued":            {},
+		"open":              {},
+		"closed":            {},
+		"draft":             {},
+		"gone":              {},
+		"no-pr":             {},
+		"kept":              {},
+	}
+
+	detected := s.detectLocally(repoPath, local, branches)
+	check := s.newMergeCheck(repoPath, local.byName)
+
+	results := s.lookupPRs(repoPath, local, branches, cache.KindStatus, lookup, reporter)
+	s.markMergeQueued(repoPath, results)
+	for _, result := range results {
+		b := local.byName[result.Branch]
+		pr := result.PR
+		status := statusFor(pr, result.Err)
+		// A failed lookup doesn't prove there is no PR
+		if status == "no-pr" && result.Err == nil && b.Upstream.Gone {
+			status = "gone"
+		}
+		if status == "merged" {
+			status = check.status(pr)
+		}
+		if (status == "no-pr" || status == "gone" || status == "closed" || status == "merged-into-other" || status == "merged-not-pulled") && len(detected.evidence[result.Branch]) > 0 {
+			status = "merged"
+			if offline, ok := detected.prs[result.Branch]; ok && (pr == nil || pr.State != "MERGED") {
+				pr = offline
 			}
-		}()
+		}
+		if status == "merged" && check.reverted(pr, b.SHA) {
+			status = "merged-reverted"
+		}
+		kept := s.KeepReason(repoPath, b.Name, pr)
+		if kept != "" {
+			status = "kept"
+		}
+		if !s.postFilter(status, pr) {
+			continue
+		}
+		statusMap[status] = append(statusMap[status], BranchStatus{
+			Branch: b,
+			Status: status,
+			PR:     pr,
+			Kept:   kept,
+		})
 	}
 
-	// Send work to workers
-	for _, branch := range branches {
-		branchChan <- branch
+	return statusMap
+}
+
+// preFilter applies the filters that don't need PR data, so filtered out
+// branches never cost a GitHub lookup
+func (s *Service) preFilter(repoPath string, branches []git.Branch) []git.Branch {
+	if s.filter.IsZero() {
+		return branches
 	}
-	close(branchChan)
 
-	// Wait for all workers to finish, then close results
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+	var defaultBranch string
+	if s.filter.Author != "" {
+		defaultBranch, _ = s.gitClient.GetDefaultBranch(repoPath)
+	}
 
-	// Collect and categorize results
-	statusMap := map[string][]BranchStatus{
-		"merged": {},
-		"open":   {},
-		"closed": {},
-		"draft":  {},
-		"no-pr":  {},
+	var kept []git.Branch
+	for _, b := range branches {
+		if !s.filter.matchesName(b.Name) {
+			continue
+		}
+		// A PR closes after its last commit, so recently committed branches
+		// can't have an old enough PR either
+		if s.filter.OlderThan > 0 && s.now().Sub(b.CommitDate) < s.filter.OlderThan {
+			continue
+		}
+		if s.filter.Author != "" && !s.hasCommitBy(repoPath, b.Name, defaultBranch, s.filter.Author) {
+			continue
+		}
+		kept = append(kept, b)
 	}
+	return kept
+}
 
-	for result := range resultChan {
-		statusMap[result.Status] = append(statusMap[result.Status], result)
+// hasCommitBy reports whether any commit unique to the branch was authored by
+// email. Branches without unique commits are judged by their tip commit.
+func (s *Service) hasCommitBy(repoPath, branch, defaultBranch string, email string) bool {
+	var commits []git.Commit
+	if defaultBranch != "" && defaultBranch != branch {
+		commits, _ = s.gitClient.GetCommits(repoPath, branch, defaultBranch, 0)
+	}
+	if len(commits) == 0 {
+		commits, _ = s.gitClient.GetCommits(repoPath, branch, "", 1)
+	}
+	for _, c := range commits {
+		if strings.EqualFold(c.AuthorEmail, email) {
+			return true
+		}
 	}
+	return false
+}
 
-	return statusMap
+// postFilter applies the filters that need the PR lookup result
+func (s *Service) postFilter(status string, pr *github.PRInfo) bool {
+	if !s.filter.allowsState(status) || !s.filter.allowsPR(pr) || !s.filter.allowsAttention(pr) {
+		return false
+	}
+	if s.filter.OlderThan > 0 && pr != nil {
+		if closed := pr.ClosedAt; !closed.IsZero() && s.now().Sub(closed) < s.filter.OlderThan {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupPRs looks up PRs for the branches if the PR strategy is enabled.
+// Otherwise every branch is reported as having no PR.
+func (s *Service) lookupPRs(repoPath string, local localBranches, branches []string, kind string, lookup lookupFunc, reporter ProgressReporter) []lookupResult {
+	if !slices.Contains(s.strategies, StrategyPR) {
+		results := make([]lookupResult, len(branches))
+		for i, branch := range branches {
+			results[i] = lookupResult{Branch: branch}
+		}
+		return results
+	}
+	results := s.lookupCached(repoPath, local, branches, kind, lookup, reporter)
+	s.recordLookups(results)
+	s.refreshLabels(repoPath, results)
+	return results
+}
+
+// refreshLabels replaces the labels of cached merged PRs with their labels
+// on GitHub now. Merged PRs stay cached for good, but a keep label can be
+// added to one at any time. Without keep labels nothing is asked.
+func (s *Service) refreshLabels(repoPath string, results []lookupResult) {
+	if len(s.keepLabels) == 0 {
+		return
+	}
+	var numbers []int
+	for _, result := range results {
+		if pr := result.PR; result.Cached && pr != nil && pr.State == "MERGED" {
+			numbers = append(numbers, pr.Number)
+		}
+	}
+	if len(numbers) == 0 {
+		return
+	}
+	labels, err := s.githubClient.GetLabels(repoPath, numbers)
+	if err != nil {
+		s.warn(fmt.Sprintf("Couldn't refresh the labels of cached PRs, so keep labels added since they were cached are missed: %v", err))
+		return
+	}
+	for i, result := range results {
+		if !result.Cached || result.PR == nil {
+			continue
+		}
+		if current, ok := labels[result.PR.Number]; ok {
+			// The PR is shared with the cache
+			pr := *result.PR
+			pr.Labels = current
+			results[i].PR = &pr
+		}
+	}
+}
+
+// markMergeQueued sets InMergeQueue on the open PRs among results, which
+// GetPRStatus leaves unset, with one query for all of them. PRs with
+// auto-merge enabled are queued either way and aren't asked about. The
+// state only decides whether a PR is listed as queued, so a failed query
+// leaves it unset.
+func (s *Service) markMergeQueued(repoPath string, results []lookupResult) {
+	var numbers []int
+	for _, result := range results {
+		if pr := result.PR; pr != nil && pr.State == "OPEN" && !pr.IsDraft && pr.AutoMergeRequest == nil {
+			numbers = append(numbers, pr.Number)
+		}
+	}
+	if len(numbers) == 0 {
+		return
+	}
+	queued, err := s.githubClient.InMergeQueue(repoPath, numbers)
+	if err != nil {
+		return
+	}
+	for i, result := range results {
+		if result.PR != nil && queued[result.PR.Number] {
+			// The PR may be shared with the cache
+			pr := *result.PR
+			pr.InMergeQueue = true
+			results[i].PR = &pr
+		}
+	}
+}
+
+// recordLookups remembers which lookups failed for FailedLookups. A branch
+// that is looked up again successfully is forgotten.
+func (s *Service) recordLookups(results []lookupResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, result := range results {
+		if result.Err == nil {
+			delete(s.failedLookups, result.Branch)
+			continue
+		}
+		if s.failedLookups == nil {
+			s.failedLookups = make(map[string]error)
+		}
+		s.failedLookups[result.Branch] = result.Err
+	}
+}
+
+// FailedLookups returns the branches whose PR lookup failed so far, with the
+// last error for each. Their status is unknown, so results that left them
+// out may be incomplete.
+func (s *Service) FailedLookups() map[string]error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failed := make(map[string]error, len(s.failedLookups))
+	for branch, err := range s.failedLookups {
+		failed[branch] = err
+	}
+	return failed
+}
+
+// warn records a warning for Warnings, once
+func (s *Service) warn(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.warnings, msg) {
+		s.warnings = append(s.warnings, msg)
+	}
+}
+
+// Warnings returns what the service couldn't check so far, which may make
+// its results less reliable
+func (s *Service) Warnings() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.warnings)
+}
+
+// lookupCached answers lookups from the PR cache where possible and schedules
+// the remaining branches. Results are returned in input order.
+func (s *Service) lookupCached(repoPath string, local localBranches, branches []string, kind string, lookup lookupFunc, reporter ProgressReporter) []lookupResult {
+	if s.cache == nil {
+		return s.scheduler.run(branches, lookup, reporter)
+	}
+
+	repo, err := s.gitClient.GetGitDir(repoPath)
+	if err != nil {
+		return s.scheduler.run(branches, lookup, reporter)
+	}
+
+	keyFor := func(branch, kind string) cache.Key {
+		return cache.Key{Repo: repo, Head: branch, SHA: local.byName[branch].SHA, Kind: kind}
+	}
+
+	results := make([]lookupResult, len(branches))
+	var misses []string
+	var missIndexes []int
+	for i, branch := range branches {
+		if pr, ok := s.cache.Get(keyFor(branch, kind)); ok {
+			results[i] = lookupResult{Branch: branch, PR: pr, Cached: true}
+			continue
+		}
+		// A status lookup that found a merged PR also answers a merged lookup
+		if kind == cache.KindMerged {
+			if pr, ok := s.cache.Get(keyFor(branch, cache.KindStatus)); ok && pr != nil && pr.State == "MERGED" {
+				results[i] = lookupResult{Branch: branch, PR: pr, Cached: true}
+				continue
+			}
+		}
+		misses = append(misses, branch)
+		missIndexes = append(missIndexes, i)
+	}
+
+	if len(misses) > 0 {
+		reporter.Update(fmt.Sprintf("%d cached, checking %d branches on GitHub...", len(branches)-len(misses), len(misses)))
+	}
+
+	for i, result := range s.scheduler.run(misses, lookup, reporter) {
+		results[missIndexes[i]] = result
+		// Failed lookups are never cached
+		if result.Err == nil {
+			s.cache.Put(keyFor(result.Branch, kind), result.PR)
+		}
+	}
+
+	return results
+}
+
+// statusFor maps a PR lookup result to a branch status
+func statusFor(pr *github.PRInfo, err error) string {
+	switch {
+	case err != nil || pr == nil:
+		return "no-pr"
+	case pr.IsDraft:
+		return "draft"
+	case pr.State == "MERGED":
+		return "merged"
+	case pr.Queued():
+		return "queued"
+	case pr.State == "OPEN":
+		return "open"
+	case pr.State == "CLOSED":
+		return "closed"
+	default:
+		return "no-pr"
+	}
 }
 
 // min returns the minimum of two integers
@@ -2,8 +2,12 @@ package branch
 
 import (
 	"errors"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/nikzadkhani/axe/pkg/cache"
 	"github.com/nikzadkhani/axe/pkg/git"
 	"github.com/nikzadkhani/axe/pkg/github"
 	"go.uber.org/mock/gomock"
@@ -12,6 +16,41 @@ import (
 // mockReporter implements ProgressReporter for testing
 type mockReporter struct{}
 
+// branchList returns local branches with the given names, each with a
+// distinct tip SHA
+func branchList(names ...string) []git.Branch {
+	branches := make([]git.Branch, len(names))
+	for i, name := range names {
+		branches[i] = git.Branch{Name: name, SHA: name}
+	}
+	return branches
+}
+
+// expectNoMergeQueue answers merge queue lookups with no PR queued
+func expectNoMergeQueue(ghMock *github.MockClient) {
+	ghMock.EXPECT().InMergeQueue(".", gomock.Any()).Return(map[int]bool{}, nil).AnyTimes()
+}
+
+// trackedList is branchList with main tracking origin/main
+func trackedList(names ...string) []git.Branch {
+	branches := branchList(names...)
+	for i := range branches {
+		if branches[i].Name == "main" {
+			branches[i].Upstream = git.Upstream{Ref: "origin/main"}
+		}
+	}
+	return branches
+}
+
+// expectPlainRepo lets the scan of the default branch for reverts and the
+// reads of keep markers run and find nothing. It must follow the test's own
+// expectations, which gomock matches first.
+func expectPlainRepo(gitMock *git.MockClient) {
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil).AnyTimes()
+	gitMock.EXPECT().GetCommits(".", gomock.Any(), "", squashScanLimit).Return(nil, nil).AnyTimes()
+	gitMock.EXPECT().GetConfig(".", gomock.Any()).Return("", nil).AnyTimes()
+}
+
 func (m *mockReporter) Start(msg string)         {}
 func (m *mockReporter) Update(msg string)        {}
 func (m *mockReporter) Stop(msg string)          {}
@@ -30,8 +69,8 @@ func TestService_GetMergedBranches(t *testing.T) {
 			name: "returns merged branches successfully",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
-					Return([]string{"main", "feature-1", "feature-2"}, nil)
+					ListBranches(".").
+					Return(branchList("main", "feature-1", "feature-2"), nil)
 
 				ghMock.EXPECT().
 					GetMergedPR(".", "feature-1").
@@ -50,8 +89,8 @@ func TestService_GetMergedBranches(t *testing.T) {
 			name: "filters out main and master branches",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
-					Return([]string{"main", "master", "feature-1"}, nil)
+					ListBranches(".").
+					Return(branchList("main", "master", "feature-1"), nil)
 
 				ghMock.EXPECT().
 					GetMergedPR(".", "feature-1").
@@ -66,7 +105,7 @@ func TestService_GetMergedBranches(t *testing.T) {
 			name: "returns error when git client fails",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
+					ListBranches(".").
 					Return(nil, errors.New("git error"))
 			},
 			repoPath:     ".",
@@ -77,8 +116,8 @@ func TestService_GetMergedBranches(t *testing.T) {
 			name: "returns empty list when no merged branches found",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
-					Return([]string{"main", "feature-1"}, nil)
+					ListBranches(".").
+					Return(branchList("main", "feature-1"), nil)
 
 				ghMock.EXPECT().
 					GetMergedPR(".", "feature-1").
@@ -93,8 +132,8 @@ func TestService_GetMergedBranches(t *testing.T) {
 			name: "handles empty branch list",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
-					Return([]string{}, nil)
+					ListBranches(".").
+					Return(branchList(), nil)
 			},
 			repoPath:      ".",
 			wantBranches:  0,
@@ -113,6 +152,7 @@ func TestService_GetMergedBranches(t *testing.T) {
 
 			tt.setupMocks(gitMock, ghMock)
 
+			expectPlainRepo(gitMock)
 			service := NewService(gitMock, ghMock)
 			reporter := &mockReporter{}
 			branches, err := service.GetMergedBranches(tt.repoPath, reporter)
@@ -139,57 +179,79 @@ func TestService_GetMergedBranches(t *testing.T) {
 
 func TestService_DeleteBranches(t *testing.T) {
 	tests := []struct {
-		name           string
-		setupMocks     func(*git.MockClient)
-		branches       []string
-		wantDeleted    int
-		wantFailed     int
+		name            string
+		setupMocks      func(*git.MockClient)
+		branches        []git.Branch
+		opts            []Option
 		expectedDeleted []string
-		expectedFailed  []string
+		expectedFailed  []DeleteFailure
 	}{
 		{
-			name: "deletes all branches successfully",
+			name: "deletes all branches in one transaction",
 			setupMocks: func(gitMock *git.MockClient) {
-				gitMock.EXPECT().DeleteBranch(".", "feature-1").Return(nil)
-				gitMock.EXPECT().DeleteBranch(".", "feature-2").Return(nil)
+				gitMock.EXPECT().DeleteBranches(".", branchList("feature-1", "feature-2")).Return(nil)
 			},
-			branches:        []string{"feature-1", "feature-2"},
-			wantDeleted:     2,
-			wantFailed:      0,
+			branches:        branchList("feature-1", "feature-2"),
 			expectedDeleted: []string{"feature-1", "feature-2"},
-			expectedFailed:  []string{},
 		},
 		{
-			name: "handles partial deletion failure",
+			name: "reports branches that moved since the scan and keeps the rest",
 			setupMocks: func(gitMock *git.MockClient) {
-				gitMock.EXPECT().DeleteBranch(".", "feature-1").Return(nil)
-				gitMock.EXPECT().DeleteBranch(".", "feature-2").Return(errors.New("delete error"))
+				gitMock.EXPECT().DeleteBranches(".", branchList("feature-1", "feature-2", "feature-3")).Return(errors.New("cannot lock ref"))
+				gitMock.EXPECT().ListBranches(".").Return([]git.Branch{
+					{Name: "feature-1", SHA: "feature-1"},
+					{Name: "feature-2", SHA: "0123456789abcdef"},
+				}, nil)
 			},
-			branches:        []string{"feature-1", "feature-2"},
-			wantDeleted:     1,
-			wantFailed:      1,
-			expectedDeleted: []string{"feature-1"},
-			expectedFailed:  []string{"feature-2"},
+			branches: branchList("feature-1", "feature-2", "feature-3"),
+			expectedFailed: []DeleteFailure{
+				{Branch: "feature-1", Reason: "kept because 2 branch(es) failed verification"},
+				{Branch: "feature-2", Reason: "moved to 0123456 since it was scanned"},
+				{Branch: "feature-3", Reason: "no longer exists"},
+			},
+		},
+		{
+			name: "reports the error when every branch verified",
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().DeleteBranches(".", branchList("feature-1")).Return(errors.New("delete error"))
+				gitMock.EXPECT().ListBranches(".").Return(branchList("feature-1"), nil)
+			},
+			branches:       branchList("feature-1"),
+			expectedFailed: []DeleteFailure{{Branch: "feature-1", Reason: "delete error"}},
 		},
 		{
-			name: "handles all deletions failing",
+			name: "deletes the rest one at a time in per-branch mode",
 			setupMocks: func(gitMock *git.MockClient) {
-				gitMock.EXPECT().DeleteBranch(".", "feature-1").Return(errors.New("delete error"))
+				gitMock.EXPECT().DeleteBranches(".", branchList("feature-1")).Return(nil)
+				gitMock.EXPECT().DeleteBranches(".", branchList("feature-2")).Return(errors.New("cannot lock ref"))
+				gitMock.EXPECT().ListBranches(".").Return([]git.Branch{{Name: "feature-2", SHA: "0123456789abcdef"}}, nil)
+				gitMock.EXPECT().DeleteBranches(".", branchList("feature-3")).Return(nil)
+			},
+			branches:        branchList("feature-1", "feature-2", "feature-3"),
+			opts:            []Option{WithPerBranchDeletion()},
+			expectedDeleted: []string{"feature-1", "feature-3"},
+			expectedFailed:  []DeleteFailure{{Branch: "feature-2", Reason: "moved to 0123456 since it was scanned"}},
+		},
+		{
+			name: "never deletes checked out branches",
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().DeleteBranches(".", branchList("feature-1")).Return(nil)
+			},
+			branches: []git.Branch{
+				{Name: "feature-1", SHA: "feature-1"},
+				{Name: "feature-2", SHA: "feature-2", IsHead: true},
+				{Name: "feature-3", SHA: "feature-3", WorktreePath: "/tmp/wt"},
+			},
+			expectedDeleted: []string{"feature-1"},
+			expectedFailed: []DeleteFailure{
+				{Branch: "feature-2", Reason: "checked out"},
+				{Branch: "feature-3", Reason: "checked out in /tmp/wt"},
 			},
-			branches:        []string{"feature-1"},
-			wantDeleted:     0,
-			wantFailed:      1,
-			expectedDeleted: []string{},
-			expectedFailed:  []string{"feature-1"},
 		},
 		{
-			name:            "handles empty branch list",
-			setupMocks:      func(gitMock *git.MockClient) {},
-			branches:        []string{},
-			wantDeleted:     0,
-			wantFailed:      0,
-			expectedDeleted: []string{},
-			expectedFailed:  []string{},
+			name:       "handles empty branch list",
+			setupMocks: func(gitMock *git.MockClient) {},
+			branches:   []git.Branch{},
 		},
 	}
 
@@ -203,30 +265,39 @@ func TestService_DeleteBranches(t *testing.T) {
 
 			tt.setupMocks(gitMock)
 
-			service := NewService(gitMock, ghMock)
+			service := NewService(gitMock, ghMock, tt.opts...)
 			reporter := &mockReporter{}
 			deleted, failed := service.DeleteBranches(".", tt.branches, reporter)
 
-			if len(deleted) != tt.wantDeleted {
-				t.Errorf("DeleteBranches() deleted %d branches, want %d", len(deleted), tt.wantDeleted)
+			if !reflect.DeepEqual(deleted, tt.expectedDeleted) {
+				t.Errorf("DeleteBranches() deleted = %v, want %v", deleted, tt.expectedDeleted)
 			}
-
-			if len(failed) != tt.wantFailed {
-				t.Errorf("DeleteBranches() failed %d branches, want %d", len(failed), tt.wantFailed)
+			if !reflect.DeepEqual(failed, tt.expectedFailed) {
+				t.Errorf("DeleteBranches() failed = %+v, want %+v", failed, tt.expectedFailed)
 			}
+		})
+	}
+}
 
-			for i, name := range tt.expectedDeleted {
-				if i >= len(deleted) || deleted[i] != name {
-					t.Errorf("DeleteBranches() deleted[%d] = %v, want %s", i, deleted, name)
-				}
-			}
+func TestService_CurrentPRs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-			for i, name := range tt.expectedFailed {
-				if i >= len(failed) || failed[i] != name {
-					t.Errorf("DeleteBranches() failed[%d] = %v, want %s", i, failed, name)
-				}
-			}
-		})
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	open := &github.PRInfo{Number: 2, State: "OPEN"}
+	ghMock.EXPECT().GetPRStatus(".", "feature-1").Return(open, nil)
+	ghMock.EXPECT().GetPRStatus(".", "feature-2").Return(nil, nil)
+	ghMock.EXPECT().GetPRStatus(".", "feature-3").Return(nil, errors.New("not found"))
+
+	service := NewService(gitMock, ghMock, WithScheduler(NewScheduler(SchedulerConfig{Concurrency: 1})))
+	prs, failed := service.CurrentPRs(".", []string{"feature-1", "feature-2", "feature-3"}, &mockReporter{})
+
+	if !reflect.DeepEqual(prs, map[string]*github.PRInfo{"feature-1": open, "feature-2": nil}) {
+		t.Errorf("CurrentPRs() prs = %v", prs)
+	}
+	if len(failed) != 1 || failed["feature-3"] == nil {
+		t.Errorf("CurrentPRs() failed = %v, want feature-3", failed)
 	}
 }
 
@@ -242,8 +313,11 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 			name: "categorizes branches by status",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
-					Return([]string{"main", "merged-1", "open-1", "draft-1", "closed-1", "no-pr-1"}, nil)
+					ListBranches(".").
+					Return(append(
+						branchList("main", "merged-1", "open-1", "draft-1", "closed-1", "no-pr-1"),
+						git.Branch{Name: "gone-1", SHA: "gone-1", Upstream: git.Upstream{Ref: "origin/gone-1", Gone: true}},
+					), nil)
 
 				ghMock.EXPECT().
 					GetPRStatus(".", "merged-1").
@@ -264,6 +338,10 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 				ghMock.EXPECT().
 					GetPRStatus(".", "no-pr-1").
 					Return(nil, nil)
+
+				ghMock.EXPECT().
+					GetPRStatus(".", "gone-1").
+					Return(nil, nil)
 			},
 			repoPath: ".",
 			wantErr:  false,
@@ -272,6 +350,7 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 				"open":   1,
 				"draft":  1,
 				"closed": 1,
+				"gone":   1,
 				"no-pr":  1,
 			},
 		},
@@ -279,8 +358,8 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 			name: "filters out main and master branches",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
-					Return([]string{"main", "master", "feature-1"}, nil)
+					ListBranches(".").
+					Return(branchList("main", "master", "feature-1"), nil)
 
 				ghMock.EXPECT().
 					GetPRStatus(".", "feature-1").
@@ -300,7 +379,7 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 			name: "returns error when git client fails",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
+					ListBranches(".").
 					Return(nil, errors.New("git error"))
 			},
 			repoPath: ".",
@@ -310,8 +389,8 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 			name: "handles empty branch list",
 			setupMocks: func(gitMock *git.MockClient, ghMock *github.MockClient) {
 				gitMock.EXPECT().
-					GetLocalBranches(".").
-					Return([]string{}, nil)
+					ListBranches(".").
+					Return(branchList(), nil)
 			},
 			repoPath: ".",
 			wantErr:  false,
@@ -335,6 +414,8 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 
 			tt.setupMocks(gitMock, ghMock)
 
+			expectPlainRepo(gitMock)
+			expectNoMergeQueue(ghMock)
 			service := NewService(gitMock, ghMock)
 			reporter := &mockReporter{}
 			statusMap, err := service.GetAllBranchStatuses(tt.repoPath, reporter)
@@ -355,3 +436,420 @@ func TestService_GetAllBranchStatuses(t *testing.T) {
 		})
 	}
 }
+
+func TestService_GetMergedBranches_UsesCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	store, err := cache.Open(filepath.Join(t.TempDir(), "prs.json"), cache.DefaultTTLs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "feature-1", "feature-2"), nil).Times(2)
+	gitMock.EXPECT().GetGitDir(".").Return("/repo/.git", nil).Times(2)
+
+	// Only the first scan reaches GitHub
+	ghMock.EXPECT().
+		GetMergedPR(".", "feature-1").
+		Return(&github.PRInfo{Number: 1, State: "MERGED", Title: "Feature 1"}, nil).
+		Times(1)
+	ghMock.EXPECT().
+		GetMergedPR(".", "feature-2").
+		Return(nil, errors.New("network down")).
+		Times(2)
+
+	expectPlainRepo(gitMock)
+	service := NewService(gitMock, ghMock, WithCache(store))
+	reporter := &mockReporter{}
+
+	for i := 0; i < 2; i++ {
+		branches, err := service.GetMergedBranches(".", reporter)
+		if err != nil {
+			t.Fatalf("GetMergedBranches() error = %v", err)
+		}
+		if len(branches) != 1 || branches[0].Name != "feature-1" {
+			t.Errorf("GetMergedBranches() run %d = %v, want [feature-1]", i, branches)
+		}
+	}
+}
+
+func TestService_GetClosedBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	now := time.Unix(1700000000, 0)
+
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "merged", "old-closed", "new-closed", "open"), nil)
+	ghMock.EXPECT().GetPRStatus(".", "old-closed").Return(&github.PRInfo{Number: 1, State: "CLOSED", ClosedAt: now.Add(-90 * 24 * time.Hour)}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "new-closed").Return(&github.PRInfo{Number: 2, State: "CLOSED", ClosedAt: now.Add(-24 * time.Hour)}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "open").Return(&github.PRInfo{Number: 3, State: "OPEN"}, nil)
+	expectPlainRepo(gitMock)
+	expectNoMergeQueue(ghMock)
+
+	service := NewService(gitMock, ghMock)
+	service.now = func() time.Time { return now }
+
+	// Branches in skip are never looked up
+	closed, err := service.GetClosedBranches(".", 60*24*time.Hour, []string{"merged"}, &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetClosedBranches() error = %v", err)
+	}
+	if len(closed) != 1 || closed[0].Name != "old-closed" {
+		t.Errorf("GetClosedBranches() = %v, want [old-closed]", closed)
+	}
+}
+
+func TestService_CreateRecoveryPoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	gitMock.EXPECT().ListBranches(".").Return([]git.Branch{{Name: "feature/a", SHA: "aaa"}, {Name: "feature/b", SHA: "bbb"}}, nil)
+	gitMock.EXPECT().CreateRef(".", "refs/axe/recovery/20231114-221320/feature/a", "aaa").Return(nil)
+
+	service := NewService(gitMock, ghMock)
+	service.now = func() time.Time { return time.Unix(1700000000, 0).UTC() }
+
+	prefix, err := service.CreateRecoveryPoint(".", []string{"feature/a"})
+	if err != nil {
+		t.Fatalf("CreateRecoveryPoint() error = %v", err)
+	}
+	if prefix != "refs/axe/recovery/20231114-221320" {
+		t.Errorf("CreateRecoveryPoint() prefix = %q", prefix)
+	}
+
+	gitMock.EXPECT().ListBranches(".").Return([]git.Branch{}, nil)
+	if _, err := service.CreateRecoveryPoint(".", []string{"missing"}); err == nil {
+		t.Error("CreateRecoveryPoint() for a missing branch = nil, want error")
+	}
+}
+
+func TestService_GetGoneBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	gitMock.EXPECT().ListBranches(".").Return([]git.Branch{
+		{Name: "main"},
+		{Name: "merged", Upstream: git.Upstream{Ref: "origin/merged", Gone: true}},
+		{Name: "gone", Upstream: git.Upstream{Ref: "origin/gone", Gone: true}},
+		{Name: "gone-with-pr", Upstream: git.Upstream{Ref: "origin/gone-with-pr", Gone: true}},
+		{Name: "gone-failed", Upstream: git.Upstream{Ref: "origin/gone-failed", Gone: true}},
+		{Name: "tracked", Upstream: git.Upstream{Ref: "origin/tracked", Ahead: 2}},
+	}, nil)
+
+	// Only gone branches that aren't skipped are looked up
+	ghMock.EXPECT().GetPRStatus(".", "gone").Return(nil, nil)
+	ghMock.EXPECT().GetPRStatus(".", "gone-with-pr").Return(&github.PRInfo{Number: 5, State: "CLOSED"}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "gone-failed").Return(nil, errors.New("boom"))
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, ghMock)
+	gone, err := service.GetGoneBranches(".", []string{"merged"}, &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetGoneBranches() error = %v", err)
+	}
+	if len(gone) != 1 || gone[0].Name != "gone" || gone[0].Status != "gone" {
+		t.Errorf("GetGoneBranches() = %v, want [gone]", gone)
+	}
+}
+
+func TestService_GetMergedBranches_UpstreamGone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+
+	gitMock.EXPECT().ListBranches(".").Return([]git.Branch{
+		{Name: "main"},
+		{Name: "feature-1", Upstream: git.Upstream{Ref: "origin/feature-1", Gone: true}},
+	}, nil)
+	ghMock.EXPECT().GetMergedPR(".", "feature-1").Return(&github.PRInfo{Number: 1, State: "MERGED"}, nil)
+
+	expectPlainRepo(gitMock)
+	service := NewService(gitMock, ghMock)
+	branches, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+	want := []Evidence{
+		{Kind: StrategyPR, Detail: "PR #1 merged"},
+		{Kind: EvidenceUpstreamGone, Detail: "origin/feature-1 was deleted"},
+	}
+	if len(branches) != 1 || !reflect.DeepEqual(branches[0].Evidence, want) || branches[0].MergedBy != "squash" {
+		t.Errorf("GetMergedBranches() = %+v, want feature-1 squash-merged with a gone upstream", branches)
+	}
+}
+
+func TestService_GetMergedBranches_Base(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		// mergeCommit is the merge commit of the PR into release/1.0
+		mergeCommit string
+		setupMocks  func(*git.MockClient)
+		want        []string
+	}{
+		{
+			name: "only chops PRs merged into the default branch",
+			want: []string{"into-main: PR #1 merged into main", "unknown-base: PR #3 merged"},
+		},
+		{
+			name: "chops PRs merged into accepted bases",
+			opts: []Option{WithAcceptedBases("release/*")},
+			want: []string{"into-main: PR #1 merged into main", "into-release: PR #2 merged into release/1.0", "unknown-base: PR #3 merged"},
+		},
+		{
+			name:        "chops PRs merged into accepted bases once their merge commit is pulled",
+			opts:        []Option{WithAcceptedBases("release/*")},
+			mergeCommit: "release-merge",
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "release-merge", "origin/release/1.0").Return(true, nil)
+			},
+			want: []string{"into-main: PR #1 merged into main", "into-release: PR #2 merged into release/1.0", "unknown-base: PR #3 merged"},
+		},
+		{
+			name:        "keeps PRs merged into accepted bases until their merge commit is pulled",
+			opts:        []Option{WithAcceptedBases("release/*")},
+			mergeCommit: "release-merge",
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "release-merge", "origin/release/1.0").Return(false, nil)
+				gitMock.EXPECT().IsAncestor(".", "release-merge", "main").Return(false, nil)
+			},
+			want: []string{"into-main: PR #1 merged into main", "unknown-base: PR #3 merged"},
+		},
+		{
+			name: "chops PRs merged elsewhere whose commits reached the default branch",
+			opts: []Option{WithStrategies(StrategyPR, StrategyAncestor)},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "into-main", "main").Return(false, nil)
+				gitMock.EXPECT().IsAncestor(".", "into-release", "main").Return(true, nil)
+				gitMock.EXPECT().IsAncestor(".", "unknown-base", "main").Return(false, nil)
+			},
+			want: []string{"into-main: PR #1 merged into main", "into-release: reachable from main", "unknown-base: PR #3 merged"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			gitMock := git.NewMockClient(ctrl)
+			ghMock := github.NewMockClient(ctrl)
+			gitMock.EXPECT().ListBranches(".").Return(branchList("main", "into-main", "into-release", "unknown-base"), nil)
+			gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil).AnyTimes()
+			ghMock.EXPECT().GetMergedPR(".", "into-main").Return(&github.PRInfo{Number: 1, State: "MERGED", BaseRefName: "main"}, nil)
+			release := &github.PRInfo{Number: 2, State: "MERGED", BaseRefName: "release/1.0"}
+			if tt.mergeCommit != "" {
+				release.MergeCommit = &github.Commit{OID: tt.mergeCommit}
+			}
+			ghMock.EXPECT().GetMergedPR(".", "into-release").Return(release, nil)
+			ghMock.EXPECT().GetMergedPR(".", "unknown-base").Return(&github.PRInfo{Number: 3, State: "MERGED"}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(gitMock)
+			}
+
+			expectPlainRepo(gitMock)
+			service := NewService(gitMock, ghMock, append([]Option{WithScheduler(NewScheduler(SchedulerConfig{Concurrency: 1}))}, tt.opts...)...)
+			branches, err := service.GetMergedBranches(".", &mockReporter{})
+			if err != nil {
+				t.Fatalf("GetMergedBranches() error = %v", err)
+			}
+
+			var got []string
+			for _, mb := range branches {
+				got = append(got, mb.Name+": "+mb.Evidence[0].Detail)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMergedBranches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_GetAllBranchStatuses_MergedElsewhere(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(trackedList("main", "into-main", "into-parent", "not-pulled"), nil)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+	gitMock.EXPECT().IsAncestor(".", "squash", gomock.Any()).Return(false, nil).Times(2)
+	ghMock.EXPECT().GetPRStatus(".", "into-main").Return(&github.PRInfo{Number: 1, State: "MERGED", BaseRefName: "main"}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "into-parent").Return(&github.PRInfo{Number: 2, State: "MERGED", BaseRefName: "parent"}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "not-pulled").Return(&github.PRInfo{Number: 3, State: "MERGED", BaseRefName: "main", MergeCommit: &github.Commit{OID: "squash"}}, nil)
+
+	expectPlainRepo(gitMock)
+	service := NewService(gitMock, ghMock)
+	statuses, err := service.GetAllBranchStatuses(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetAllBranchStatuses() error = %v", err)
+	}
+	if got := statuses["merged"]; len(got) != 1 || got[0].Name != "into-main" {
+		t.Errorf("merged = %v, want [into-main]", got)
+	}
+	if got := statuses["merged-into-other"]; len(got) != 1 || got[0].Name != "into-parent" {
+		t.Errorf("merged-into-other = %v, want [into-parent]", got)
+	}
+	if got := statuses["merged-not-pulled"]; len(got) != 1 || got[0].Name != "not-pulled" {
+		t.Errorf("merged-not-pulled = %v, want [not-pulled]", got)
+	}
+}
+
+func TestService_GetMergedBranches_VerifiesMergeCommit(t *testing.T) {
+	pr := func(n int, sha string) *github.PRInfo {
+		return &github.PRInfo{Number: n, State: "MERGED", BaseRefName: "main", MergeCommit: &github.Commit{OID: sha}}
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		setupMocks func(*git.MockClient)
+		want       []string
+	}{
+		{
+			name: "skips branches whose merge commit isn't local",
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "pulled-sha", "main").Return(true, nil)
+				gitMock.EXPECT().IsAncestor(".", "fetched-sha", "main").Return(false, nil)
+				gitMock.EXPECT().IsAncestor(".", "fetched-sha", "origin/main").Return(true, nil)
+				gitMock.EXPECT().IsAncestor(".", "missing-sha", "main").Return(false, errors.New("unknown revision"))
+				gitMock.EXPECT().IsAncestor(".", "missing-sha", "origin/main").Return(false, errors.New("unknown revision"))
+			},
+			want: []string{"pulled", "fetched"},
+		},
+		{
+			name: "trusts GitHub without local verification",
+			opts: []Option{WithoutLocalVerification()},
+			want: []string{"pulled", "fetched", "missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			gitMock := git.NewMockClient(ctrl)
+			ghMock := github.NewMockClient(ctrl)
+			gitMock.EXPECT().ListBranches(".").Return(trackedList("main", "pulled", "fetched", "missing"), nil)
+			gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil).AnyTimes()
+			ghMock.EXPECT().GetMergedPR(".", "pulled").Return(pr(1, "pulled-sha"), nil)
+			ghMock.EXPECT().GetMergedPR(".", "fetched").Return(pr(2, "fetched-sha"), nil)
+			ghMock.EXPECT().GetMergedPR(".", "missing").Return(pr(3, "missing-sha"), nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(gitMock)
+			}
+
+			expectPlainRepo(gitMock)
+			service := NewService(gitMock, ghMock, tt.opts...)
+			branches, err := service.GetMergedBranches(".", &mockReporter{})
+			if err != nil {
+				t.Fatalf("GetMergedBranches() error = %v", err)
+			}
+
+			var got []string
+			for _, mb := range branches {
+				got = append(got, mb.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMergedBranches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_GetMergedBranches_UnknownDefaultBranch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "feature"), nil)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("", errors.New("no default branch")).AnyTimes()
+	ghMock.EXPECT().GetMergedPR(".", "feature").Return(&github.PRInfo{Number: 1, State: "MERGED", MergeCommit: &github.Commit{OID: "abc123"}}, nil)
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, ghMock)
+	branches, err := service.GetMergedBranches(".", &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+	if len(branches) != 1 || branches[0].Name != "feature" {
+		t.Errorf("GetMergedBranches() = %v, want feature trusted without local verification", branches)
+	}
+	if warnings := service.Warnings(); len(warnings) != 1 {
+		t.Errorf("Warnings() = %v, want the skipped verification", warnings)
+	}
+}
+
+func TestService_UnsafeMerges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(trackedList("main"), nil)
+	gitMock.EXPECT().IsAncestor(".", "pulled1", "main").Return(true, nil)
+	gitMock.EXPECT().IsAncestor(".", "remote1", "main").Return(false, nil)
+	gitMock.EXPECT().IsAncestor(".", "remote1", "origin/main").Return(false, nil)
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, github.NewMockClient(ctrl))
+	unsafe := service.UnsafeMerges(".", map[string]*github.PRInfo{
+		"pulled":   {Number: 1, State: "MERGED", BaseRefName: "main", MergeCommit: &github.Commit{OID: "pulled1"}},
+		"unpulled": {Number: 2, State: "MERGED", BaseRefName: "main", MergeCommit: &github.Commit{OID: "remote1"}},
+		"other":    {Number: 3, State: "MERGED", BaseRefName: "develop"},
+		"open":     {Number: 4, State: "OPEN", BaseRefName: "develop"},
+		"local":    nil,
+	})
+	want := map[string]string{
+		"unpulled": "PR #2's merge commit remote1 hasn't been pulled",
+		"other":    "PR #3 was merged into develop, not the default branch",
+	}
+	if !reflect.DeepEqual(unsafe, want) {
+		t.Errorf("UnsafeMerges() = %v, want %v", unsafe, want)
+	}
+}
+
+func TestService_FailedLookups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().ListBranches(".").Return(branchList("main", "flaky", "fine"), nil).Times(2)
+	ghMock.EXPECT().GetMergedPR(".", "flaky").Return(nil, errors.New("not authenticated"))
+	ghMock.EXPECT().GetMergedPR(".", "fine").Return(nil, nil)
+	ghMock.EXPECT().GetPRStatus(".", "flaky").Return(nil, nil)
+	ghMock.EXPECT().GetPRStatus(".", "fine").Return(nil, nil)
+	expectPlainRepo(gitMock)
+
+	service := NewService(gitMock, ghMock, WithScheduler(NewScheduler(SchedulerConfig{Concurrency: 1})))
+	if _, err := service.GetMergedBranches(".", &mockReporter{}); err != nil {
+		t.Fatalf("GetMergedBranches() error = %v", err)
+	}
+	if failed := service.FailedLookups(); len(failed) != 1 || failed["flaky"] == nil {
+		t.Errorf("FailedLookups() = %v, want flaky", failed)
+	}
+
+	// A later successful lookup clears the failure
+	if _, err := service.GetAllBranchStatuses(".", &mockReporter{}); err != nil {
+		t.Fatalf("GetAllBranchStatuses() error = %v", err)
+	}
+	if failed := service.FailedLookups(); len(failed) != 0 {
+		t.Errorf("FailedLookups() after a successful lookup = %v, want none", failed)
+	}
+}
@@ -0,0 +1,249 @@
+package branch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+)
+
+// Stack is a merged branch and the local branches built on top of it
+type Stack struct {
+	Parent MergedBranch
+	// Children are the branches forked from Parent, directly or through
+	// other children, each listed after the branch it was forked from
+	Children []StackedBranch
+}
+
+// StackedBranch is a branch forked from another local branch
+type StackedBranch struct {
+	git.Branch
+	// Parent is the branch it was forked from
+	Parent string
+	// ForkPoint is the newest commit it shares with Parent. It stays put
+	// when the parent is amended or rebased after the branch forked.
+	ForkPoint string
+}
+
+// RestackStep is the outcome of rebasing one branch of a stack
+type RestackStep struct {
+	Branch string
+	// Onto describes what the branch was rebased onto
+	Onto string
+	Err  error
+}
+
+// GetMergedStacks returns the merged branches that other local branches were
+// forked from. Those children still carry the parent's original commits, so
+// they need restacking before the parent is chopped.
+func (s *Service) GetMergedStacks(repoPath string, reporter ProgressReporter) ([]Stack, error) {
+	merged, err := s.GetMergedBranches(repoPath, reporter)
+	if err != nil || len(merged) == 0 {
+		return nil, err
+	}
+
+	reporter.Start("Looking for stacked branches...")
+	parents, err := s.findParents(repoPath)
+	if err != nil {
+		reporter.StopWithError(fmt.Sprintf("Failed to find stacked branches: %v", err))
+		return nil, err
+	}
+
+	children := make(map[string][]StackedBranch)
+	for _, child := range parents {
+		children[child.Parent] = append(children[child.Parent], child)
+	}
+	for _, c := range children {
+		slices.SortFunc(c, func(a, b StackedBranch) int { return strings.Compare(a.Name, b.Name) })
+	}
+
+	var stacks []Stack
+	for _, mb := range merged {
+		stack := Stack{Parent: mb}
+		// Walk the tree breadth first so parents come before their children
+		seen := map[string]bool{mb.Name: true}
+		queue := children[mb.Name]
+		for len(queue) > 0 {
+			child := queue[0]
+			queue = queue[1:]
+			if seen[child.Name] {
+				continue
+			}
+			seen[child.Name] = true
+			queue = append(queue, children[child.Name]...)
+			stack.Children = append(stack.Children, child)
+		}
+		if len(stack.Children) > 0 {
+			stacks = append(stacks, stack)
+		}
+	}
+	reporter.Stop(fmt.Sprintf("Found %d merged branches with stacked branches", len(stacks)))
+	return stacks, nil
+}
+
+// findParents works out which local branch each branch was forked from. The
+// fork point with another branch is their merge base, which must be one of
+// the branch's commits that aren't on the default branch. The parent is the
+// branch with the nearest fork point that has fewer commits of its own past
+// it, so a parent that was amended after the branch forked is still found,
+// and neither of two siblings is taken for the other's parent.
+func (s *Service) findParents(repoPath string) (map[string]StackedBranch, error) {
+	defaultBranch, err := s.gitClient.GetDefaultBranch(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := s.gitClient.ListBranches(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// How far each commit not on the default branch is from each branch's
+	// tip, which is 0
+	depths := make(map[string]map[string]int)
+	for _, b := range branches {
+		if b.Name == defaultBranch {
+			continue
+		}
+		commits, err := s.gitClient.GetCommits(repoPath, b.SHA, defaultBranch, 0)
+		if err != nil {
+			return nil, err
+		}
+		depths[b.Name] = make(map[string]int, len(commits))
+		for i, c := range commits {
+			depths[b.Name][c.SHA] = i
+		}
+	}
+
+	parents := make(map[string]StackedBranch)
+	for _, b := range branches {
+		own, ok := depths[b.Name]
+		if !ok {
+			continue
+		}
+		bestDepth, bestOther := -1, -1
+		for _, other := range branches {
+			theirs, ok := depths[other.Name]
+			if !ok || other.Name == b.Name || !sharesCommits(own, theirs) {
+				continue
+			}
+			fork, err := s.gitClient.MergeBase(repoPath, b.SHA, other.SHA)
+			if err != nil {
+				return nil, err
+			}
+			depth, onOwn := own[fork]
+			otherDepth, onTheirs := theirs[fork]
+			if !onOwn || !onTheirs || otherDepth >= depth {
+				continue
+			}
+			// Several branches may share a tip; the first name wins
+			if bestDepth < 0 || depth < bestDepth || (depth == bestDepth && otherDepth < bestOther) {
+				parents[b.Name] = StackedBranch{Branch: b, Parent: other.Name, ForkPoint: fork}
+				bestDepth, bestOther = depth, otherDepth
+			}
+		}
+	}
+	return parents, nil
+}
+
+// sharesCommits reports whether two branches have any commit in common
+func sharesCommits(a, b map[string]int) bool {
+	for sha := range a {
+		if _, ok := b[sha]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Restack rebases the children of a merged branch onto the commit it was
+// merged as, and the grandchildren onto the rebased children, keeping only
+// each branch's own commits. A rebase that fails is aborted, and the
+// branches stacked on it are left alone.
+func (s *Service) Restack(repoPath string, stack Stack, reporter ProgressReporter) ([]RestackStep, error) {
+	onto, ontoDesc, err := s.mergedAs(repoPath, stack.Parent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Where each branch's children go now. Only the commits past their fork
+	// point are replayed.
+	newTips := map[string]string{stack.Parent.Name: onto}
+	descs := map[string]string{stack.Parent.Name: ontoDesc}
+	for _, c := range stack.Children {
+		descs[c.Name] = c.Name
+	}
+
+	reporter.Start(fmt.Sprintf("Restacking %d branches on %s...", len(stack.Children), stack.Parent.Name))
+	var steps []RestackStep
+	for i, c := range stack.Children {
+		step := RestackStep{Branch: c.Name, Onto: descs[c.Parent]}
+		target, ok := newTips[c.Parent]
+		if !ok {
+			step.Err = fmt.Errorf("skipped because %s couldn't be restacked", c.Parent)
+			steps = append(steps, step)
+			continue
+		}
+
+		reporter.Update(fmt.Sprintf("Restacking (%d/%d): %s onto %s", i+1, len(stack.Children), c.Name, step.Onto))
+		if step.Err = s.gitClient.RebaseOnto(repoPath, c.Name, target, c.ForkPoint); step.Err == nil {
+			tip, err := s.gitClient.GetCommits(repoPath, c.Name, "", 1)
+			if err == nil && len(tip) == 1 {
+				newTips[c.Name] = tip[0].SHA
+			} else {
+				step.Err = fmt.Errorf("rebased, but failed to read the new tip: %v", err)
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	failed := 0
+	for _, step := range steps {
+		if step.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		reporter.StopWithError(fmt.Sprintf("Restacked %d of %d branches on %s", len(steps)-failed, len(steps), stack.Parent.Name))
+	} else {
+		reporter.Stop(fmt.Sprintf("Restacked %d branches on %s", len(steps), stack.Parent.Name))
+	}
+	return steps, nil
+}
+
+// mergedAs finds the commit on the default branch a merged branch landed
+// as. For squash merges that is the squash commit, found from the PR's merge
+// commit or its "Title (#123)" subject. Branches merged with their own
+// commits are already part of the default branch, so its tip is used.
+func (s *Service) mergedAs(repoPath string, mb MergedBranch) (sha, desc string, err error) {
+	defaultBranch, err := s.gitClient.GetDefaultBranch(repoPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find where %s was merged: %w", mb.Name, err)
+	}
+	if mb.MergedBy != "squash" {
+		return defaultBranch, defaultBranch, nil
+	}
+
+	pr := mb.PR
+	if pr == nil {
+		return "", "", fmt.Errorf("failed to find where %s was merged: no PR", mb.Name)
+	}
+	desc = fmt.Sprintf("%s (squash of #%d)", defaultBranch, pr.Number)
+	if pr.MergeCommit != nil && pr.MergeCommit.OID != "" {
+		if ok, err := s.gitClient.IsAncestor(repoPath, pr.MergeCommit.OID, defaultBranch); err == nil && ok {
+			return pr.MergeCommit.OID, desc, nil
+		}
+	}
+
+	commits, err := s.gitClient.GetCommits(repoPath, defaultBranch, "", squashScanLimit)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find where %s was merged: %w", mb.Name, err)
+	}
+	for _, c := range commits {
+		if m := squashSubject.FindStringSubmatch(c.Subject); m != nil && m[2] == strconv.Itoa(pr.Number) {
+			return c.SHA, desc, nil
+		}
+	}
+	return "", "", fmt.Errorf("the squash commit of PR #%d isn't on %s; pull %s and try again", pr.Number, defaultBranch, defaultBranch)
+}
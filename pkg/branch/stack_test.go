@@ -0,0 +1,262 @@
+package branch
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func commitList(shas ...string) []git.Commit {
+	commits := make([]git.Commit, len(shas))
+	for i, sha := range shas {
+		commits[i] = git.Commit{SHA: sha}
+	}
+	return commits
+}
+
+// expectStack answers GetCommits for each branch with its commits that
+// aren't on main, newest first, and MergeBase with the first of a's commits
+// that b also has
+func expectStack(gitMock *git.MockClient, commits map[string][]string) {
+	for name, shas := range commits {
+		gitMock.EXPECT().GetCommits(".", name, "main", 0).Return(commitList(shas...), nil)
+	}
+	gitMock.EXPECT().MergeBase(".", gomock.Any(), gomock.Any()).DoAndReturn(func(_, a, b string) (string, error) {
+		for _, sha := range commits[a] {
+			if slices.Contains(commits[b], sha) {
+				return sha, nil
+			}
+		}
+		return "main", nil
+	}).AnyTimes()
+}
+
+func TestService_GetMergedStacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		branches []string
+		commits  map[string][]string
+		want     []string
+	}{
+		{
+			name:     "finds children, grandchildren and siblings by the parent's original commits",
+			branches: []string{"main", "parent", "child", "grandchild", "sibling", "other"},
+			commits: map[string][]string{
+				"parent":     {"parent", "p1"},
+				"child":      {"child", "parent", "p1"},
+				"grandchild": {"grandchild", "child", "parent", "p1"},
+				"sibling":    {"sibling", "parent", "p1"},
+				"other":      {"other"},
+			},
+			want: []string{"child on parent at parent", "sibling on parent at parent", "grandchild on child at child"},
+		},
+		{
+			name:     "finds a child whose parent was amended after it forked",
+			branches: []string{"main", "parent", "child", "sibling"},
+			commits: map[string][]string{
+				// parent's tip was amended from p2
+				"parent":  {"parent", "p1"},
+				"child":   {"child", "p2", "p1"},
+				"sibling": {"sibling", "p2", "p1"},
+			},
+			want: []string{"child on parent at p1", "sibling on parent at p1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			gitMock := git.NewMockClient(ctrl)
+			ghMock := github.NewMockClient(ctrl)
+
+			gitMock.EXPECT().ListBranches(".").Return(branchList(tt.branches...), nil).Times(2)
+			gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil).AnyTimes()
+
+			ghMock.EXPECT().GetMergedPR(".", "parent").Return(&github.PRInfo{Number: 1, State: "MERGED"}, nil)
+			for _, name := range tt.branches[2:] {
+				ghMock.EXPECT().GetMergedPR(".", name).Return(nil, nil)
+			}
+
+			expectStack(gitMock, tt.commits)
+			expectPlainRepo(gitMock)
+			service := NewService(gitMock, ghMock, WithScheduler(NewScheduler(SchedulerConfig{Concurrency: 1})))
+			stacks, err := service.GetMergedStacks(".", &mockReporter{})
+			if err != nil {
+				t.Fatalf("GetMergedStacks() error = %v", err)
+			}
+			if len(stacks) != 1 || stacks[0].Parent.Name != "parent" {
+				t.Fatalf("GetMergedStacks() = %+v, want one stack on parent", stacks)
+			}
+
+			var got []string
+			for _, c := range stacks[0].Children {
+				got = append(got, c.Name+" on "+c.Parent+" at "+c.ForkPoint)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMergedStacks() children = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Restack(t *testing.T) {
+	parent := MergedBranch{
+		Branch:   git.Branch{Name: "parent", SHA: "parent"},
+		PR:       &github.PRInfo{Number: 1, State: "MERGED", MergeCommit: &github.Commit{OID: "squash"}},
+		MergedBy: "squash",
+	}
+	stack := Stack{Parent: parent, Children: []StackedBranch{
+		{Branch: git.Branch{Name: "child", SHA: "child"}, Parent: "parent", ForkPoint: "parent"},
+		{Branch: git.Branch{Name: "sibling", SHA: "sibling"}, Parent: "parent", ForkPoint: "parent"},
+		{Branch: git.Branch{Name: "grandchild", SHA: "grandchild"}, Parent: "child", ForkPoint: "child"},
+	}}
+
+	tests := []struct {
+		name       string
+		stack      Stack
+		setupMocks func(*git.MockClient)
+		wantSteps  []string
+	}{
+		{
+			name:  "rebases children onto the squash commit and grandchildren onto them",
+			stack: stack,
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "squash", "main").Return(true, nil)
+				gitMock.EXPECT().RebaseOnto(".", "child", "squash", "parent").Return(nil)
+				gitMock.EXPECT().GetCommits(".", "child", "", 1).Return(commitList("child2"), nil)
+				gitMock.EXPECT().RebaseOnto(".", "sibling", "squash", "parent").Return(nil)
+				gitMock.EXPECT().GetCommits(".", "sibling", "", 1).Return(commitList("sibling2"), nil)
+				gitMock.EXPECT().RebaseOnto(".", "grandchild", "child2", "child").Return(nil)
+				gitMock.EXPECT().GetCommits(".", "grandchild", "", 1).Return(commitList("grandchild2"), nil)
+			},
+			wantSteps: []string{
+				"child onto main (squash of #1)",
+				"sibling onto main (squash of #1)",
+				"grandchild onto child",
+			},
+		},
+		{
+			name:  "leaves branches stacked on a conflict alone",
+			stack: stack,
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "squash", "main").Return(true, nil)
+				gitMock.EXPECT().RebaseOnto(".", "child", "squash", "parent").Return(errors.New("CONFLICT"))
+				gitMock.EXPECT().RebaseOnto(".", "sibling", "squash", "parent").Return(nil)
+				gitMock.EXPECT().GetCommits(".", "sibling", "", 1).Return(commitList("sibling2"), nil)
+			},
+			wantSteps: []string{
+				"child onto main (squash of #1): CONFLICT",
+				"sibling onto main (squash of #1)",
+				"grandchild onto child: skipped because child couldn't be restacked",
+			},
+		},
+		{
+			name: "finds the squash commit by its subject when the merge commit is unknown",
+			stack: Stack{
+				Parent: MergedBranch{Branch: parent.Branch, PR: &github.PRInfo{Number: 1, State: "MERGED"}, MergedBy: "squash"},
+				Children: []StackedBranch{
+					{Branch: git.Branch{Name: "child", SHA: "child"}, Parent: "parent", ForkPoint: "parent"},
+				},
+			},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().GetCommits(".", "main", "", squashScanLimit).Return([]git.Commit{
+					{SHA: "other", Subject: "Other (#11)"},
+					{SHA: "squash", Subject: "Parent (#1)"},
+				}, nil)
+				gitMock.EXPECT().RebaseOnto(".", "child", "squash", "parent").Return(nil)
+				gitMock.EXPECT().GetCommits(".", "child", "", 1).Return(commitList("child2"), nil)
+			},
+			wantSteps: []string{"child onto main (squash of #1)"},
+		},
+		{
+			name: "replays only the commits past the fork point when the parent was amended",
+			stack: Stack{
+				Parent: parent,
+				Children: []StackedBranch{
+					{Branch: git.Branch{Name: "child", SHA: "child"}, Parent: "parent", ForkPoint: "p1"},
+				},
+			},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "squash", "main").Return(true, nil)
+				gitMock.EXPECT().RebaseOnto(".", "child", "squash", "p1").Return(nil)
+				gitMock.EXPECT().GetCommits(".", "child", "", 1).Return(commitList("child2"), nil)
+			},
+			wantSteps: []string{"child onto main (squash of #1)"},
+		},
+		{
+			name: "rebases onto the default branch after a rebase merge",
+			stack: Stack{
+				Parent: MergedBranch{Branch: parent.Branch, MergedBy: "rebase"},
+				Children: []StackedBranch{
+					{Branch: git.Branch{Name: "child", SHA: "child"}, Parent: "parent", ForkPoint: "parent"},
+				},
+			},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().RebaseOnto(".", "child", "main", "parent").Return(nil)
+				gitMock.EXPECT().GetCommits(".", "child", "", 1).Return(commitList("child2"), nil)
+			},
+			wantSteps: []string{"child onto main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			gitMock := git.NewMockClient(ctrl)
+			ghMock := github.NewMockClient(ctrl)
+			gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil).AnyTimes()
+			tt.setupMocks(gitMock)
+
+			service := NewService(gitMock, ghMock)
+			steps, err := service.Restack(".", tt.stack, &mockReporter{})
+			if err != nil {
+				t.Fatalf("Restack() error = %v", err)
+			}
+
+			var got []string
+			for _, step := range steps {
+				desc := step.Branch + " onto " + step.Onto
+				if step.Err != nil {
+					desc += ": " + step.Err.Error()
+				}
+				got = append(got, desc)
+			}
+			if !reflect.DeepEqual(got, tt.wantSteps) {
+				t.Errorf("Restack() steps = %v, want %v", got, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestService_Restack_SquashNotPulled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+	gitMock.EXPECT().IsAncestor(".", "squash", "main").Return(false, nil)
+	gitMock.EXPECT().GetCommits(".", "main", "", squashScanLimit).Return(nil, nil)
+
+	stack := Stack{
+		Parent: MergedBranch{
+			Branch:   git.Branch{Name: "parent", SHA: "parent"},
+			PR:       &github.PRInfo{Number: 1, State: "MERGED", MergeCommit: &github.Commit{OID: "squash"}},
+			MergedBy: "squash",
+		},
+		Children: []StackedBranch{{Branch: git.Branch{Name: "child", SHA: "child"}, Parent: "parent", ForkPoint: "parent"}},
+	}
+	service := NewService(gitMock, ghMock)
+	if _, err := service.Restack(".", stack, &mockReporter{}); err == nil {
+		t.Error("Restack() without the squash commit = nil, want error")
+	}
+}
@@ -0,0 +1,89 @@
+package branch
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/cache"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// StaleBranch is a branch that never had a PR and has seen no commits for a
+// while
+type StaleBranch struct {
+	git.Branch
+	// UniqueCommits counts the commits not on the default branch
+	UniqueCommits int
+	// UnpushedCommits counts the unique commits not on any remote, which
+	// would be lost for good if the branch were deleted
+	UnpushedCommits int
+}
+
+// HasUnpushedWork reports whether deleting the branch would lose commits
+// that exist nowhere else
+func (b StaleBranch) HasUnpushedWork() bool {
+	return b.UnpushedCommits > 0
+}
+
+// GetStaleBranches returns local branches without a PR whose last commit is
+// at least idleFor old, oldest first. Branches kept with axe keep are left
+// out.
+func (s *Service) GetStaleBranches(repoPath string, idleFor time.Duration, reporter ProgressReporter) ([]StaleBranch, error) {
+	local, err := s.localCandidates(repoPath, reporter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(local.candidates) == 0 || !s.filter.allowsState("no-pr") {
+		return []StaleBranch{}, nil
+	}
+
+	// The last commit comes with the branch listing, so only idle branches
+	// are looked up. Stale branches have no PR to carry a keep label, so
+	// only keep markers can keep them.
+	var idle []string
+	for _, b := range local.candidates {
+		if s.now().Sub(b.CommitDate) >= idleFor && s.KeepReason(repoPath, b.Name, nil) == "" {
+			idle = append(idle, b.Name)
+		}
+	}
+	if len(idle) == 0 {
+		return []StaleBranch{}, nil
+	}
+
+	lookup := func(branch string) (*github.PRInfo, error) {
+		return s.githubClient.GetPRStatus(repoPath, branch)
+	}
+
+	reporter.Start(fmt.Sprintf("Checking PR status for %d branches...", len(idle)))
+	defaultBranch, _ := s.gitClient.GetDefaultBranch(repoPath)
+	stale := []StaleBranch{}
+	results := s.lookupCached(repoPath, local, idle, cache.KindStatus, lookup, reporter)
+	s.recordLookups(results)
+	for _, result := range results {
+		// A failed lookup doesn't prove the branch has no PR
+		if result.Err != nil || result.PR != nil || !s.postFilter("no-pr", nil) {
+			continue
+		}
+
+		sb := StaleBranch{Branch: local.byName[result.Branch]}
+		if defaultBranch != "" && defaultBranch != result.Branch {
+			sb.UniqueCommits, _ = s.gitClient.CountCommits(repoPath, result.Branch, defaultBranch)
+		}
+		unpushed, err := s.gitClient.CountUnpushedCommits(repoPath, result.Branch, defaultBranch)
+		if err != nil {
+			// Assume the worst when it can't be checked
+			unpushed = max(sb.UniqueCommits, 1)
+		}
+		sb.UnpushedCommits = unpushed
+		stale = append(stale, sb)
+	}
+	reporter.Stop(fmt.Sprintf("Found %d stale branches", len(stale)))
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].CommitDate.Before(stale[j].CommitDate)
+	})
+	return stale, nil
+}
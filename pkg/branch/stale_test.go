@@ -0,0 +1,65 @@
+package branch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_GetStaleBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	ghMock := github.NewMockClient(ctrl)
+	now := time.Unix(1700000000, 0)
+	day := 24 * time.Hour
+
+	gitMock.EXPECT().ListBranches(".").Return([]git.Branch{
+		{Name: "main", CommitDate: now},
+		{Name: "old", CommitDate: now.Add(-100 * day), Author: "Dana"},
+		{Name: "older", CommitDate: now.Add(-400 * day), Author: "Sam"},
+		{Name: "recent", CommitDate: now.Add(-day)},
+		{Name: "has-pr", CommitDate: now.Add(-200 * day)},
+		{Name: "lookup-failed", CommitDate: now.Add(-200 * day)},
+		{Name: "kept", CommitDate: now.Add(-300 * day)},
+	}, nil)
+
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+	gitMock.EXPECT().GetConfig(".", KeepConfigKey("kept")).Return("true", nil)
+	gitMock.EXPECT().GetConfig(".", gomock.Any()).Return("", nil).AnyTimes()
+
+	// Recent and kept branches are never looked up
+	ghMock.EXPECT().GetPRStatus(".", "old").Return(nil, nil)
+	ghMock.EXPECT().GetPRStatus(".", "older").Return(nil, nil)
+	ghMock.EXPECT().GetPRStatus(".", "has-pr").Return(&github.PRInfo{Number: 7, State: "OPEN"}, nil)
+	ghMock.EXPECT().GetPRStatus(".", "lookup-failed").Return(nil, errors.New("boom"))
+
+	gitMock.EXPECT().CountCommits(".", "old", "main").Return(3, nil)
+	gitMock.EXPECT().CountUnpushedCommits(".", "old", "main").Return(2, nil)
+	gitMock.EXPECT().CountCommits(".", "older", "main").Return(1, nil)
+	gitMock.EXPECT().CountUnpushedCommits(".", "older", "main").Return(0, nil)
+
+	service := NewService(gitMock, ghMock, WithScheduler(NewScheduler(SchedulerConfig{Concurrency: 1})))
+	service.now = func() time.Time { return now }
+
+	stale, err := service.GetStaleBranches(".", 90*day, &mockReporter{})
+	if err != nil {
+		t.Fatalf("GetStaleBranches() error = %v", err)
+	}
+	if len(stale) != 2 {
+		t.Fatalf("GetStaleBranches() = %v, want old and older", stale)
+	}
+
+	// Oldest first
+	if stale[0].Name != "older" || stale[0].Author != "Sam" || stale[0].UniqueCommits != 1 || stale[0].HasUnpushedWork() {
+		t.Errorf("stale[0] = %+v, want older with 1 unique and no unpushed commits", stale[0])
+	}
+	if stale[1].Name != "old" || stale[1].UniqueCommits != 3 || !stale[1].HasUnpushedWork() {
+		t.Errorf("stale[1] = %+v, want old with 3 unique and unpushed commits", stale[1])
+	}
+}
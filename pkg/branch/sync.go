@@ -0,0 +1,89 @@
+package branch
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+)
+
+// Fetch fetches every branch from origin, pruning remote-tracking branches
+// that were deleted there when prune is set
+func (s *Service) Fetch(repoPath string, prune bool, reporter ProgressReporter) error {
+	reporter.Start("Fetching origin...")
+	if err := s.gitClient.Fetch(repoPath, "origin", prune); err != nil {
+		reporter.StopWithError(err.Error())
+		return err
+	}
+	if prune {
+		reporter.Stop("Fetched origin and pruned deleted branches")
+	} else {
+		reporter.Stop("Fetched origin")
+	}
+	return nil
+}
+
+// FastForwardDefault fast-forwards the default branch to its upstream, or to
+// origin's copy of it when it has none, like git pull --ff-only. It returns
+// the default branch.
+func (s *Service) FastForwardDefault(repoPath string, reporter ProgressReporter) (string, error) {
+	reporter.Start("Updating the default branch...")
+	defaultBranch, err := s.gitClient.GetDefaultBranch(repoPath)
+	if err != nil {
+		reporter.StopWithError(fmt.Sprintf("Failed to find the default branch: %v", err))
+		return "", err
+	}
+	branches, err := s.gitClient.ListBranches(repoPath)
+	if err != nil {
+		reporter.StopWithError(fmt.Sprintf("Failed to update %s: %v", defaultBranch, err))
+		return defaultBranch, err
+	}
+	i := slices.IndexFunc(branches, func(b git.Branch) bool { return b.Name == defaultBranch })
+	if i < 0 {
+		err := fmt.Errorf("failed to update %s: no local branch", defaultBranch)
+		reporter.StopWithError(err.Error())
+		return defaultBranch, err
+	}
+
+	target := branches[i].Upstream.Ref
+	if target == "" {
+		target = "origin/" + defaultBranch
+	}
+	if ok, err := s.gitClient.IsAncestor(repoPath, target, defaultBranch); err == nil && ok {
+		reporter.Stop(fmt.Sprintf("%s is up to date with %s", defaultBranch, target))
+		return defaultBranch, nil
+	}
+	if err := s.gitClient.FastForward(repoPath, defaultBranch, target); err != nil {
+		reporter.StopWithError(err.Error())
+		return defaultBranch, err
+	}
+	reporter.Stop(fmt.Sprintf("Fast-forwarded %s to %s", defaultBranch, target))
+	return defaultBranch, nil
+}
+
+// LeaveMergedHead checks out the default branch when the branch checked out
+// here is one of the merged branches, so it can be chopped too. The branch
+// is marked as no longer checked out in merged. It returns the branch that
+// was left, or "" if HEAD wasn't on a merged branch.
+func (s *Service) LeaveMergedHead(repoPath string, merged []MergedBranch, reporter ProgressReporter) (string, error) {
+	i := slices.IndexFunc(merged, func(mb MergedBranch) bool { return mb.IsHead })
+	if i < 0 {
+		return "", nil
+	}
+	name := merged[i].Name
+
+	reporter.Start(fmt.Sprintf("Switching off %s...", name))
+	defaultBranch, err := s.gitClient.GetDefaultBranch(repoPath)
+	if err != nil {
+		reporter.StopWithError(fmt.Sprintf("Failed to find the default branch: %v", err))
+		return "", err
+	}
+	if err := s.gitClient.Checkout(repoPath, defaultBranch); err != nil {
+		reporter.StopWithError(err.Error())
+		return "", err
+	}
+	merged[i].IsHead = false
+	merged[i].WorktreePath = ""
+	reporter.Stop(fmt.Sprintf("Switched from %s to %s", name, defaultBranch))
+	return name, nil
+}
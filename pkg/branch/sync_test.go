@@ -0,0 +1,97 @@
+package branch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_FastForwardDefault(t *testing.T) {
+	tests := []struct {
+		name       string
+		main       git.Branch
+		setupMocks func(*git.MockClient)
+		wantErr    bool
+	}{
+		{
+			name: "fast-forwards to the upstream",
+			main: git.Branch{Name: "main", Upstream: git.Upstream{Ref: "upstream/main"}},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "upstream/main", "main").Return(false, nil)
+				gitMock.EXPECT().FastForward(".", "main", "upstream/main").Return(nil)
+			},
+		},
+		{
+			name: "falls back to origin without an upstream",
+			main: git.Branch{Name: "main"},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "origin/main", "main").Return(false, nil)
+				gitMock.EXPECT().FastForward(".", "main", "origin/main").Return(nil)
+			},
+		},
+		{
+			name: "leaves an up to date branch alone",
+			main: git.Branch{Name: "main", Upstream: git.Upstream{Ref: "origin/main"}},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "origin/main", "main").Return(true, nil)
+			},
+		},
+		{
+			name: "reports a branch that diverged",
+			main: git.Branch{Name: "main", Upstream: git.Upstream{Ref: "origin/main"}},
+			setupMocks: func(gitMock *git.MockClient) {
+				gitMock.EXPECT().IsAncestor(".", "origin/main", "main").Return(false, nil)
+				gitMock.EXPECT().FastForward(".", "main", "origin/main").Return(errors.New("diverged"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			gitMock := git.NewMockClient(ctrl)
+			gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+			gitMock.EXPECT().ListBranches(".").Return([]git.Branch{tt.main, {Name: "feature"}}, nil)
+			tt.setupMocks(gitMock)
+
+			service := NewService(gitMock, github.NewMockClient(ctrl))
+			defaultBranch, err := service.FastForwardDefault(".", &mockReporter{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FastForwardDefault() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if defaultBranch != "main" {
+				t.Errorf("FastForwardDefault() = %q, want main", defaultBranch)
+			}
+		})
+	}
+}
+
+func TestService_LeaveMergedHead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gitMock := git.NewMockClient(ctrl)
+	service := NewService(gitMock, github.NewMockClient(ctrl))
+
+	merged := []MergedBranch{{Branch: git.Branch{Name: "done"}}}
+	if left, err := service.LeaveMergedHead(".", merged, &mockReporter{}); err != nil || left != "" {
+		t.Errorf("LeaveMergedHead() without HEAD = %q, %v, want nothing", left, err)
+	}
+
+	gitMock.EXPECT().GetDefaultBranch(".").Return("main", nil)
+	gitMock.EXPECT().Checkout(".", "main").Return(nil)
+	merged = append(merged, MergedBranch{Branch: git.Branch{Name: "here", IsHead: true, WorktreePath: "/src"}})
+	left, err := service.LeaveMergedHead(".", merged, &mockReporter{})
+	if err != nil || left != "here" {
+		t.Fatalf("LeaveMergedHead() = %q, %v, want here", left, err)
+	}
+	if merged[1].IsHead || merged[1].WorktreePath != "" {
+		t.Errorf("LeaveMergedHead() left %+v marked as checked out", merged[1].Branch)
+	}
+}
@@ -0,0 +1,191 @@
+package branch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// mergeCheck decides whether a merged PR proves its branch is safe to chop.
+// The default branch is looked up, and scanned for reverts, the first time
+// it's needed.
+type mergeCheck struct {
+	s        *Service
+	repoPath string
+	// branches are the local branches by name, listed the first time they
+	// are needed when nil
+	branches      map[string]git.Branch
+	defaultBranch string
+	looked        bool
+	reverts       *reverts
+}
+
+func (s *Service) newMergeCheck(repoPath string, branches map[string]git.Branch) *mergeCheck {
+	return &mergeCheck{s: s, repoPath: repoPath, branches: branches}
+}
+
+// status classifies a merged PR as "merged", "merged-into-other" when it was
+// merged into a branch other than the default branch or an accepted base, or
+// "merged-not-pulled" when its merge commit hasn't been pulled, so the work
+// would only exist on GitHub.
+func (c *mergeCheck) status(pr *github.PRInfo) string {
+	switch {
+	case !c.intoDefault(pr):
+		return "merged-into-other"
+	case !c.pulled(pr):
+		return "merged-not-pulled"
+	default:
+		return "merged"
+	}
+}
+
+// UnsafeMerges checks merged PRs the way GetMergedBranches does, for callers
+// holding PRs from elsewhere, like a plan. It returns, by branch, why each PR
+// that doesn't prove its branch safe to chop falls short: it was merged into
+// another base, or its merge commit hasn't been pulled.
+func (s *Service) UnsafeMerges(repoPath string, prs map[string]*github.PRInfo) map[string]string {
+	check := s.newMergeCheck(repoPath, nil)
+	unsafe := make(map[string]string)
+	for name, pr := range prs {
+		if pr == nil || pr.State != "MERGED" {
+			continue
+		}
+		switch check.status(pr) {
+		case "merged-into-other":
+			unsafe[name] = fmt.Sprintf("PR #%d was merged into %s, not the default branch", pr.Number, pr.BaseRefName)
+		case "merged-not-pulled":
+			unsafe[name] = fmt.Sprintf("PR #%d's merge commit %s hasn't been pulled", pr.Number, git.ShortSHA(pr.MergeCommit.OID))
+		}
+	}
+	return unsafe
+}
+
+// reverted reports whether the merge of a branch, identified by its PR or
+// its tip, was reverted on the default branch. Without a PR, a reverted
+// merge commit is matched to the branches it brought in: those reachable
+// from the merge but not from its first parent.
+func (c *mergeCheck) reverted(pr *github.PRInfo, tip string) bool {
+	if c.reverts == nil {
+		var commits []git.Commit
+		if defaultBranch := c.defaultBranchName(); defaultBranch != "" {
+			commits, _ = c.s.gitClient.GetCommits(c.repoPath, defaultBranch, "", squashScanLimit)
+		}
+		r := scanReverts(commits)
+		c.reverts = &r
+	}
+	if c.reverts.reverted(pr, tip) {
+		return true
+	}
+	if pr != nil || tip == "" {
+		return false
+	}
+	for merge, parent := range c.reverts.merges {
+		merged, err := c.s.gitClient.IsAncestor(c.repoPath, tip, merge)
+		if err != nil || !merged {
+			continue
+		}
+		if before, err := c.s.gitClient.IsAncestor(c.repoPath, tip, parent); err == nil && !before {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *mergeCheck) defaultBranchName() string {
+	if !c.looked {
+		c.defaultBranch, _ = c.s.gitClient.GetDefaultBranch(c.repoPath)
+		c.looked = true
+	}
+	return c.defaultBranch
+}
+
+// localBranches returns the local branches by name, listing them the first
+// time they are needed
+func (c *mergeCheck) localBranches() map[string]git.Branch {
+	if c.branches == nil {
+		c.branches = make(map[string]git.Branch)
+		if branches, err := c.s.gitClient.ListBranches(c.repoPath); err == nil {
+			for _, b := range branches {
+				c.branches[b.Name] = b
+			}
+		}
+	}
+	return c.branches
+}
+
+// upstream returns the short name of the branch the default branch pulls
+// from, or "" if it has none
+func (c *mergeCheck) upstream() string {
+	return c.localBranches()[c.defaultBranchName()].Upstream.Ref
+}
+
+// baseTargets returns where a merge into an accepted base shows up locally:
+// the local branch and its upstream or, without a local branch, its
+// remote-tracking branch on the default branch's remote
+func (c *mergeCheck) baseTargets(base string) []string {
+	b, ok := c.localBranches()[base]
+	if !ok {
+		remote, _, found := strings.Cut(c.upstream(), "/")
+		if !found {
+			remote = "origin"
+		}
+		return []string{remote + "/" + base}
+	}
+	targets := []string{base}
+	if b.Upstream.Ref != "" {
+		targets = append(targets, b.Upstream.Ref)
+	}
+	return targets
+}
+
+// fetchUpstream fetches the default branch's upstream from its remote
+func (c *mergeCheck) fetchUpstream() error {
+	remote, branch, ok := strings.Cut(c.upstream(), "/")
+	if !ok {
+		return nil
+	}
+	return c.s.gitClient.FetchBranch(c.repoPath, remote, branch)
+}
+
+// intoDefault reports whether a PR was merged into the default branch or a
+// base accepted by WithAcceptedBases. PRs without a known base, like those
+// identified offline, are assumed to be merged into the default branch.
+func (c *mergeCheck) intoDefault(pr *github.PRInfo) bool {
+	if pr == nil || pr.BaseRefName == "" || matchAny(c.s.acceptBases, pr.BaseRefName) {
+		return true
+	}
+	if defaultBranch := c.defaultBranchName(); defaultBranch != "" {
+		return pr.BaseRefName == defaultBranch
+	}
+	return pr.BaseRefName == "main" || pr.BaseRefName == "master"
+}
+
+// pulled reports whether a PR's merge commit is reachable from the default
+// branch or its upstream or, for PRs merged into an accepted base, from that
+// base or its remote-tracking branch. PRs without a known merge commit aren't
+// checked, and neither is any PR when the default branch is unknown.
+func (c *mergeCheck) pulled(pr *github.PRInfo) bool {
+	if c.s.noVerify || pr == nil || pr.MergeCommit == nil || pr.MergeCommit.OID == "" {
+		return true
+	}
+	defaultBranch := c.defaultBranchName()
+	if defaultBranch == "" {
+		c.s.warn("Couldn't find the default branch, so merged PRs weren't checked against it locally")
+		return true
+	}
+	targets := []string{defaultBranch}
+	if upstream := c.upstream(); upstream != "" {
+		targets = append(targets, upstream)
+	}
+	if base := pr.BaseRefName; base != "" && base != defaultBranch && matchAny(c.s.acceptBases, base) {
+		targets = append(c.baseTargets(base), targets...)
+	}
+	for _, target := range targets {
+		if ok, err := c.s.gitClient.IsAncestor(c.repoPath, pr.MergeCommit.OID, target); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// Lookup kinds distinguish merged-only lookups from full status lookups,
+// since a branch without a merged PR may still have an open one
+const (
+	KindMerged = "merged"
+	KindStatus = "status"
+)
+
+// fileVersion is bumped whenever the on-disk format changes incompatibly
+const fileVersion = 7
+
+// Key identifies a cached PR lookup. Including the branch tip SHA means a
+// branch that gains new commits is looked up again.
+type Key struct {
+	Repo string `json:"repo"`
+	Head string `json:"head"`
+	SHA  string `json:"sha"`
+	Kind string `json:"kind"`
+}
+
+func (k Key) String() string {
+	return k.Kind + "\x00" + k.Repo + "\x00" + k.Head + "\x00" + k.SHA
+}
+
+// Entry is a single cached lookup result. A nil PR records that no PR was found.
+type Entry struct {
+	Key       Key            `json:"key"`
+	PR        *github.PRInfo `json:"pr"`
+	FetchedAt time.Time      `json:"fetched_at"`
+}
+
+// TTLs controls how long entries stay fresh, by PR state. Zero means the
+// entry never expires.
+type TTLs struct {
+	Merged time.Duration
+	Closed time.Duration
+	Open   time.Duration
+	NoPR   time.Duration
+}
+
+// DefaultTTLs treats merged PRs as permanent, closed PRs as long-lived (they
+// can be reopened) and open or missing PRs as short-lived
+func DefaultTTLs() TTLs {
+	return TTLs{
+		Merged: 0,
+		Closed: 30 * 24 * time.Hour,
+		Open:   10 * time.Minute,
+		NoPR:   10 * time.Minute,
+	}
+}
+
+// ttlFor returns the TTL that applies to a cached PR
+func (t TTLs) ttlFor(pr *github.PRInfo) time.Duration {
+	if pr == nil {
+		return t.NoPR
+	}
+	switch pr.State {
+	case "MERGED":
+		return t.Merged
+	case "CLOSED":
+		return t.Closed
+	default:
+		return t.Open
+	}
+}
+
+// Stats summarizes the contents and usage of a Store
+type Stats struct {
+	Path    string
+	Entries int
+	Expired int
+	ByState map[string]int
+	Size    int64
+	Hits    int
+	Misses  int
+}
+
+// Store is an on-disk cache of PR lookups
+type Store struct {
+	path    string
+	ttls    TTLs
+	refresh bool
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	dirty   bool
+	hits    int
+	misses  int
+}
+
+type cacheFile struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// DefaultPath returns the cache file location inside a git directory
+func DefaultPath(gitDir string) string {
+	return filepath.Join(gitDir, "axe", "cache", "prs.json")
+}
+
+// Open loads the cache at path. A missing or unreadable cache file yields an
+// empty cache rather than an error, since the cache can always be rebuilt.
+func Open(path string, ttls TTLs) (*Store, error) {
+	s := &Store{
+		path:    path,
+		ttls:    ttls,
+		now:     time.Now,
+		entries: make(map[string]Entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache %s: %w", path, err)
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != fileVersion {
+		// Corrupt or outdated cache, start fresh
+		s.dirty = true
+		return s, nil
+	}
+
+	for _, e := range file.Entries {
+		s.entries[e.Key.String()] = e
+	}
+	return s, nil
+}
+
+// SetRefresh makes every lookup miss so results are fetched again, while
+// still recording the fresh results
+func (s *Store) SetRefresh(refresh bool) {
+	s.refresh = refresh
+}
+
+// Get returns the cached PR for key if a fresh entry exists
+func (s *Store) Get(key Key) (*github.PRInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key.String()]
+	if s.refresh || !ok || s.expired(e) {
+		s.misses++
+		return nil, false
+	}
+	s.hits++
+	return e.PR, true
+}
+
+// Put records the result of a lookup
+func (s *Store) Put(key Key, pr *github.PRInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key.String()] = Entry{Key: key, PR: pr, FetchedAt: s.now()}
+	s.dirty = true
+}
+
+// Save writes the cache to disk if it changed, dropping expired entries
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	file := cacheFile{Version: fileVersion}
+	for _, e := range s.entries {
+		if !s.expired(e) {
+			file.Entries = append(file.Entries, e)
+		}
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write atomically so concurrent runs never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".prs-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// Clear removes every entry and deletes the cache file
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]Entry)
+	s.dirty = false
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove cache %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Stats returns a summary of the cache
+func (s *Store) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{
+		Path:    s.path,
+		ByState: make(map[string]int),
+		Hits:    s.hits,
+		Misses:  s.misses,
+	}
+	for _, e := range s.entries {
+		if s.expired(e) {
+			stats.Expired++
+			continue
+		}
+		stats.Entries++
+		state := "NO_PR"
+		if e.PR != nil {
+			state = e.PR.State
+		}
+		stats.ByState[state]++
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		stats.Size = info.Size()
+	}
+	return stats
+}
+
+// expired reports whether an entry has outlived its TTL
+func (s *Store) expired(e Entry) bool {
+	ttl := s.ttls.ttlFor(e.PR)
+	return ttl > 0 && s.now().Sub(e.FetchedAt) > ttl
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "axe", "cache", "prs.json")
+	store, err := Open(path, DefaultTTLs())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestStore_GetPut(t *testing.T) {
+	store, _ := newTestStore(t)
+	key := Key{Repo: "/repo/.git", Head: "feature", SHA: "abc123", Kind: KindMerged}
+
+	if _, ok := store.Get(key); ok {
+		t.Fatal("Get() on empty store returned a hit")
+	}
+
+	pr := &github.PRInfo{Number: 1, State: "MERGED", Title: "Feature"}
+	store.Put(key, pr)
+
+	got, ok := store.Get(key)
+	if !ok || got.Number != 1 {
+		t.Errorf("Get() = %v, %v, want PR #1", got, ok)
+	}
+
+	moved := key
+	moved.SHA = "def456"
+	if _, ok := store.Get(moved); ok {
+		t.Error("Get() with a different tip SHA returned a hit")
+	}
+}
+
+func TestStore_StateAwareTTLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		pr      *github.PRInfo
+		age     time.Duration
+		wantHit bool
+	}{
+		{"merged never expires", &github.PRInfo{State: "MERGED"}, 365 * 24 * time.Hour, true},
+		{"closed is long-lived", &github.PRInfo{State: "CLOSED"}, 7 * 24 * time.Hour, true},
+		{"closed eventually expires", &github.PRInfo{State: "CLOSED"}, 31 * 24 * time.Hour, false},
+		{"open is fresh briefly", &github.PRInfo{State: "OPEN"}, 5 * time.Minute, true},
+		{"open expires quickly", &github.PRInfo{State: "OPEN"}, time.Hour, false},
+		{"no PR expires quickly", nil, time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, now := newTestStore(t)
+			key := Key{Repo: "r", Head: "b", SHA: "s", Kind: KindStatus}
+			store.Put(key, tt.pr)
+
+			*now = now.Add(tt.age)
+			if _, ok := store.Get(key); ok != tt.wantHit {
+				t.Errorf("Get() after %v hit = %v, want %v", tt.age, ok, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestStore_SaveAndReopen(t *testing.T) {
+	store, _ := newTestStore(t)
+	key := Key{Repo: "r", Head: "feature", SHA: "abc", Kind: KindMerged}
+	store.Put(key, &github.PRInfo{Number: 42, State: "MERGED"})
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened, err := Open(store.path, DefaultTTLs())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, ok := reopened.Get(key)
+	if !ok || got.Number != 42 {
+		t.Errorf("Get() after reopen = %v, %v, want PR #42", got, ok)
+	}
+}
+
+func TestStore_Refresh(t *testing.T) {
+	store, _ := newTestStore(t)
+	key := Key{Repo: "r", Head: "feature", SHA: "abc", Kind: KindMerged}
+	store.Put(key, &github.PRInfo{Number: 1, State: "MERGED"})
+	store.SetRefresh(true)
+
+	if _, ok := store.Get(key); ok {
+		t.Error("Get() in refresh mode returned a hit")
+	}
+}
+
+func TestStore_CorruptFileStartsEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prs.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(path, DefaultTTLs())
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+	if stats := store.Stats(); stats.Entries != 0 {
+		t.Errorf("Stats().Entries = %d, want 0", stats.Entries)
+	}
+}
+
+func TestStore_ClearAndStats(t *testing.T) {
+	store, _ := newTestStore(t)
+	store.Put(Key{Head: "a", Kind: KindStatus}, &github.PRInfo{State: "MERGED"})
+	store.Put(Key{Head: "b", Kind: KindStatus}, &github.PRInfo{State: "OPEN"})
+	store.Put(Key{Head: "c", Kind: KindStatus}, nil)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := store.Stats()
+	if stats.Entries != 3 || stats.ByState["MERGED"] != 1 || stats.ByState["NO_PR"] != 1 {
+		t.Errorf("Stats() = %+v, want 3 entries with 1 merged and 1 without PR", stats)
+	}
+	if stats.Size == 0 {
+		t.Error("Stats().Size = 0, want size of saved file")
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, err := os.Stat(store.path); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists after Clear()")
+	}
+	if stats := store.Stats(); stats.Entries != 0 {
+		t.Errorf("Stats().Entries after Clear() = %d, want 0", stats.Entries)
+	}
+}
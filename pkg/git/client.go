@@ -3,19 +3,135 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Commit describes a single commit
+type Commit struct {
+	SHA     string
+	Subject string
+	// Body is the rest of the message after the subject, without
+	// surrounding blank lines
+	Body        string
+	Author      string
+	AuthorEmail string
+	Date        time.Time
+}
+
+// ShortSHA abbreviates a SHA to seven characters for display
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// Upstream describes a local branch's remote-tracking branch
+type Upstream struct {
+	// Ref is the short name of the upstream, e.g. origin/feature
+	Ref string
+	// Gone is set when the upstream was deleted on the remote
+	Gone   bool
+	Ahead  int
+	Behind int
+}
+
+// Branch describes a local branch
+type Branch struct {
+	Name string
+	// SHA is the commit the branch points at
+	SHA string
+	// Upstream is the branch's remote-tracking branch. Its Ref is empty when
+	// no upstream is configured.
+	Upstream Upstream
+	// CommitDate is the committer date of the tip commit
+	CommitDate time.Time
+	// Subject is the subject line of the tip commit
+	Subject string
+	// Author is the author name of the tip commit
+	Author string
+	// IsHead is set for the branch checked out in this worktree
+	IsHead bool
+	// WorktreePath is where the branch is checked out, in this or another
+	// worktree, or empty if it isn't checked out
+	WorktreePath string
+}
+
 // Client provides an interface for git operations
 type Client interface {
 	// ValidateRepository checks if the path is a valid git repository
 	ValidateRepository(repoPath string) error
-	// GetLocalBranches returns all local branch names
-	GetLocalBranches(repoPath string) ([]string, error)
-	// DeleteBranch force-deletes a branch
-	DeleteBranch(repoPath, branch string) error
+	// ListBranches returns every local branch
+	ListBranches(repoPath string) ([]Branch, error)
+	// DeleteBranches deletes the branches in one transaction. Each branch is
+	// only deleted if it still points at its SHA, and when any of them can't
+	// be deleted none are. Like git branch -D, it removes their branch.<name>
+	// config.
+	DeleteBranches(repoPath string, branches []Branch) error
+	// GetPullRefs maps commit SHAs to the number of the PR whose head they
+	// are, from fetched refs/pull/<n>/head or refs/remotes/<remote>/pr/<n> refs
+	GetPullRefs(repoPath string) (map[string]int, error)
+	// FetchPullRefs fetches every PR head from remote into refs/pull/<n>/head
+	FetchPullRefs(repoPath, remote string) error
+	// Fetch fetches every branch from remote, deleting remote-tracking
+	// branches that no longer exist on the remote when prune is set
+	Fetch(repoPath, remote string, prune bool) error
+	// FetchBranch fetches branch from remote into its remote-tracking branch
+	FetchBranch(repoPath, remote, branch string) error
+	// GetGitDir returns the absolute path of the repository's common git directory
+	GetGitDir(repoPath string) (string, error)
+	// GetHooksDir returns the absolute path of the directory git runs hooks
+	// from, which is core.hooksPath when it is set
+	GetHooksDir(repoPath string) (string, error)
+	// GetDefaultBranch returns the name of the repository's default branch
+	GetDefaultBranch(repoPath string) (string, error)
+	// GetCommits returns up to limit commits reachable from rev, newest first.
+	// Commits reachable from exclude are omitted when exclude is not empty.
+	GetCommits(repoPath, rev, exclude string, limit int) ([]Commit, error)
+	// CountCommits returns how many commits are reachable from rev but not
+	// from exclude
+	CountCommits(repoPath, rev, exclude string) (int, error)
+	// CountUnpushedCommits returns how many commits reachable from rev are
+	// neither on any remote-tracking branch nor, if not empty, on exclude
+	CountUnpushedCommits(repoPath, rev, exclude string) (int, error)
+	// IsAncestor reports whether rev is reachable from target, i.e. it was
+	// merged into target with a merge commit or fast-forward
+	IsAncestor(repoPath, rev, target string) (bool, error)
+	// MergeBase returns the best common ancestor of a and b, like git
+	// merge-base, or "" if they share no history
+	MergeBase(repoPath, a, b string) (string, error)
+	// CountUnappliedCommits returns how many commits on rev but not on target
+	// have no equivalent patch on target. Zero means rev was rebase-merged.
+	CountUnappliedCommits(repoPath, target, rev string) (int, error)
+	// CreateRef creates a new ref pointing at sha, failing if it already exists
+	CreateRef(repoPath, ref, sha string) error
+	// GetConfig returns the value of a git config key, or "" if it is unset
+	GetConfig(repoPath, key string) (string, error)
+	// SetConfig sets a git config key in the repository's config
+	SetConfig(repoPath, key, value string) error
+	// UnsetConfig removes a git config key from the repository's config. A
+	// key that isn't set is not an error.
+	UnsetConfig(repoPath, key string) error
+	// RebaseOnto replays the commits of branch that aren't on upstream onto
+	// onto, like git rebase --onto, then checks out the branch that was
+	// checked out before. A rebase that fails is aborted, leaving the branch
+	// as it was.
+	RebaseOnto(repoPath, branch, onto, upstream string) error
+	// FastForward moves branch to target, failing unless that is a
+	// fast-forward. A branch that isn't checked out is moved with update-ref;
+	// one that is checked out is merged with --ff-only in its worktree so
+	// the files follow.
+	FastForward(repoPath, branch, target string) error
+	// Checkout switches the worktree at repoPath to branch
+	Checkout(repoPath, branch string) error
 }
 
 // DefaultClient implements Client using git commands
@@ -34,21 +150,461 @@ func (c *DefaultClient) ValidateRepository(repoPath string) error {
 	return nil
 }
 
-func (c *DefaultClient) GetLocalBranches(repoPath string) ([]string, error) {
-	cmd := exec.Command("git", "-C", repoPath, "branch", "--format=%(refname:short)")
+// branchFormat lists the fields ListBranches reads for each branch. Fields
+// are separated by NUL and records end with a record separator, so no branch
+// name or commit subject can break parsing. The subject comes last.
+var branchFormat = strings.Join([]string{
+	"%(refname)",
+	"%(objectname)",
+	"%(HEAD)",
+	"%(committerdate:unix)",
+	"%(upstream:short)",
+	"%(upstream:track,nobracket)",
+	"%(worktreepath)",
+	"%(authorname)",
+	"%(contents:subject)",
+}, "%00") + "%1e"
+
+func (c *DefaultClient) ListBranches(repoPath string) ([]Branch, error) {
+	cmd := exec.Command("git", "-C", repoPath, "for-each-ref", "--format="+branchFormat, "refs/heads")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local branches: %w", err)
+	}
+	return parseBranches(string(output)), nil
+}
+
+// parseBranches parses ListBranches' for-each-ref output
+func parseBranches(output string) []Branch {
+	branches := []Branch{}
+	for _, record := range strings.Split(output, "\x1e\n") {
+		fields := strings.Split(record, "\x00")
+		if len(fields) != 9 {
+			continue
+		}
+
+		b := Branch{
+			Name:         strings.TrimPrefix(fields[0], "refs/heads/"),
+			SHA:          fields[1],
+			IsHead:       fields[2] == "*",
+			WorktreePath: fields[6],
+			Author:       fields[7],
+			Subject:      fields[8],
+		}
+		if secs, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			b.CommitDate = time.Unix(secs, 0)
+		}
+		if fields[4] != "" {
+			b.Upstream = parseUpstream(fields[4], fields[5])
+		}
+		branches = append(branches, b)
+	}
+	return branches
+}
+
+// parseUpstream parses an upstream's tracking state, which is "gone",
+// "ahead N", "behind N", "ahead N, behind M" or empty when up to date
+func parseUpstream(ref, track string) Upstream {
+	upstream := Upstream{Ref: ref}
+	for _, part := range strings.Split(track, ", ") {
+		word, count, _ := strings.Cut(part, " ")
+		n, _ := strconv.Atoi(count)
+		switch word {
+		case "gone":
+			upstream.Gone = true
+		case "ahead":
+			upstream.Ahead = n
+		case "behind":
+			upstream.Behind = n
+		}
+	}
+	return upstream
+}
+
+func (c *DefaultClient) DeleteBranches(repoPath string, branches []Branch) error {
+	// Every delete is verified against the old SHA before any is committed
+	var input strings.Builder
+	input.WriteString("start\n")
+	for _, b := range branches {
+		fmt.Fprintf(&input, "delete refs/heads/%s %s\n", b.Name, b.SHA)
+	}
+	input.WriteString("prepare\ncommit\n")
+
+	cmd := exec.Command("git", "-C", repoPath, "update-ref", "--stdin")
+	cmd.Stdin = strings.NewReader(input.String())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete branches: %s", strings.TrimSpace(string(output)))
+	}
+
+	// Drop their upstream and keep config like git branch -D does, so a
+	// branch recreated under the same name starts clean. Most branches have
+	// no such section, and the branches are gone either way.
+	for _, b := range branches {
+		exec.Command("git", "-C", repoPath, "config", "--local", "--remove-section", "branch."+b.Name).Run()
+	}
+	return nil
+}
+
+func (c *DefaultClient) GetPullRefs(repoPath string) (map[string]int, error) {
+	cmd := exec.Command("git", "-C", repoPath, "for-each-ref", "--format=%(objectname) %(refname)",
+		"refs/pull/*/head", "refs/remotes/*/pr/*")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get local branches: %w", err)
+		return nil, fmt.Errorf("failed to get pull refs: %w", err)
+	}
+	return parsePullRefs(string(output)), nil
+}
+
+// parsePullRefs parses GetPullRefs' for-each-ref output. When one commit is
+// the head of several PRs the newest PR wins.
+func parsePullRefs(output string) map[string]int {
+	prs := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		sha, ref, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		var number string
+		if rest, ok := strings.CutPrefix(ref, "refs/pull/"); ok {
+			number, _ = strings.CutSuffix(rest, "/head")
+		} else {
+			number = ref[strings.LastIndex(ref, "/")+1:]
+		}
+		if n, err := strconv.Atoi(number); err == nil && n > prs[sha] {
+			prs[sha] = n
+		}
+	}
+	return prs
+}
+
+func (c *DefaultClient) FetchPullRefs(repoPath, remote string) error {
+	cmd := exec.Command("git", "-C", repoPath, "fetch", "--quiet", remote, "+refs/pull/*/head:refs/pull/*/head")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch pull refs from %s: %s", remote, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *DefaultClient) Fetch(repoPath, remote string, prune bool) error {
+	args := []string{"-C", repoPath, "fetch", "--quiet"}
+	if prune {
+		args = append(args, "--prune")
+	}
+	cmd := exec.Command("git", append(args, remote)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch from %s: %s", remote, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *DefaultClient) FetchBranch(repoPath, remote, branch string) error {
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch)
+	cmd := exec.Command("git", "-C", repoPath, "fetch", "--quiet", remote, refspec)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch %s from %s: %s", branch, remote, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *DefaultClient) GetGitDir(repoPath string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *DefaultClient) GetHooksDir(repoPath string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *DefaultClient) GetDefaultBranch(repoPath string) (string, error) {
+	// Prefer the remote's HEAD, which tracks the default branch on GitHub
+	cmd := exec.Command("git", "-C", repoPath, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	if output, err := cmd.Output(); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/"), nil
+	}
+
+	for _, name := range []string{"main", "master"} {
+		cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+		if err := cmd.Run(); err == nil {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to determine default branch")
+}
+
+// commitFormat separates commit fields with unit separators and ends each
+// commit with a record separator, so subjects and bodies can contain any
+// printable character and newlines
+const commitFormat = "--format=%H%x1f%s%x1f%an%x1f%ae%x1f%at%x1f%b%x1e"
+
+func (c *DefaultClient) GetCommits(repoPath, rev, exclude string, limit int) ([]Commit, error) {
+	args := []string{"-C", repoPath, "log", commitFormat}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	args = append(args, rev)
+	if exclude != "" {
+		args = append(args, "^"+exclude)
+	}
+	args = append(args, "--")
+
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits for %q: %w", rev, err)
+	}
+
+	return parseCommits(string(output)), nil
+}
+
+func (c *DefaultClient) CountCommits(repoPath, rev, exclude string) (int, error) {
+	revs := []string{rev}
+	if exclude != "" {
+		revs = append(revs, "^"+exclude)
 	}
+	return c.countRevList(repoPath, append(revs, "--")...)
+}
 
-	branches := strings.Split(strings.TrimSpace(string(output)), "\n")
-	return branches, nil
+func (c *DefaultClient) CountUnpushedCommits(repoPath, rev, exclude string) (int, error) {
+	revs := []string{rev, "--not", "--remotes"}
+	if exclude != "" {
+		revs = append(revs, exclude)
+	}
+	return c.countRevList(repoPath, append(revs, "--")...)
 }
 
-func (c *DefaultClient) DeleteBranch(repoPath, branch string) error {
-	cmd := exec.Command("git", "-C", repoPath, "branch", "-D", branch)
+func (c *DefaultClient) IsAncestor(repoPath, rev, target string) (bool, error) {
+	cmd := exec.Command("git", "-C", repoPath, "merge-base", "--is-ancestor", rev, target)
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to delete branch %q: %w", branch, err)
+		// Exit code 1 means rev is not an ancestor
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check whether %q is merged into %q: %w", rev, target, err)
+	}
+	return true, nil
+}
+
+func (c *DefaultClient) MergeBase(repoPath, a, b string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "merge-base", a, b)
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means they share no history
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to find the merge base of %q and %q: %w", a, b, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *DefaultClient) CountUnappliedCommits(repoPath, target, rev string) (int, error) {
+	// git cherry marks commits with an equivalent on target with "-"
+	cmd := exec.Command("git", "-C", repoPath, "cherry", target, rev)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to compare %q with %q: %w", rev, target, err)
+	}
+
+	count := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "+") {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// countRevList runs git rev-list --count with the given revisions
+func (c *DefaultClient) countRevList(repoPath string, revs ...string) (int, error) {
+	args := append([]string{"-C", repoPath, "rev-list", "--count"}, revs...)
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", revs[0], err)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", revs[0], err)
+	}
+	return count, nil
+}
+
+// parseCommits parses git log output produced with commitFormat
+func parseCommits(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimPrefix(record, "\n"), "\x1f")
+		if len(fields) != 6 {
+			continue
+		}
+		commit := Commit{
+			SHA:         fields[0],
+			Subject:     fields[1],
+			Body:        strings.Trim(fields[5], "\n"),
+			Author:      fields[2],
+			AuthorEmail: fields[3],
+		}
+		if secs, err := strconv.ParseInt(fields[4], 10, 64); err == nil {
+			commit.Date = time.Unix(secs, 0)
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+func (c *DefaultClient) GetConfig(repoPath, key string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "config", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means the key is not set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read git config %q: %w", key, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *DefaultClient) SetConfig(repoPath, key, value string) error {
+	cmd := exec.Command("git", "-C", repoPath, "config", "--local", key, value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set git config %q: %s", key, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *DefaultClient) UnsetConfig(repoPath, key string) error {
+	cmd := exec.Command("git", "-C", repoPath, "config", "--local", "--unset-all", key)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		// Exit code 5 means the key is not set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 5 {
+			return nil
+		}
+		return fmt.Errorf("failed to unset git config %q: %s", key, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *DefaultClient) RebaseOnto(repoPath, branch, onto, upstream string) error {
+	// Never touch a rebase the user already has in progress
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		output, err := exec.Command("git", "-C", repoPath, "rev-parse", "--git-path", dir).Output()
+		if err != nil {
+			return fmt.Errorf("failed to rebase %q: %w", branch, err)
+		}
+		path := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repoPath, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("failed to rebase %q: a rebase is already in progress", branch)
+		}
+	}
+
+	// Remember what to check out again, by name unless HEAD is detached
+	head, err := exec.Command("git", "-C", repoPath, "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		if head, err = exec.Command("git", "-C", repoPath, "rev-parse", "HEAD").Output(); err != nil {
+			return fmt.Errorf("failed to rebase %q: %w", branch, err)
+		}
+	}
+	previous := strings.TrimSpace(string(head))
+
+	cmd := exec.Command("git", "-C", repoPath, "rebase", "--onto", onto, upstream, branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		exec.Command("git", "-C", repoPath, "rebase", "--abort").Run()
+		exec.Command("git", "-C", repoPath, "checkout", "--quiet", previous).Run()
+		return fmt.Errorf("failed to rebase %q onto %s: %s", branch, onto, rebaseFailure(string(output)))
+	}
+
+	if previous != branch {
+		if output, err := exec.Command("git", "-C", repoPath, "checkout", "--quiet", previous).CombinedOutput(); err != nil {
+			return fmt.Errorf("rebased %q but failed to check out %q again: %s", branch, previous, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+func (c *DefaultClient) FastForward(repoPath, branch, target string) error {
+	branches, err := c.ListBranches(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to fast-forward %q: %w", branch, err)
+	}
+	i := slices.IndexFunc(branches, func(b Branch) bool { return b.Name == branch })
+	if i < 0 {
+		return fmt.Errorf("failed to fast-forward %q: no such branch", branch)
+	}
+	b := branches[i]
+
+	if b.WorktreePath != "" {
+		cmd := exec.Command("git", "-C", b.WorktreePath, "merge", "--ff-only", "--quiet", target)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to fast-forward %q to %s: %s", branch, target, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	output, err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", target+"^{commit}").Output()
+	if err != nil {
+		return fmt.Errorf("failed to fast-forward %q: unknown revision %q", branch, target)
+	}
+	sha := strings.TrimSpace(string(output))
+	if sha == b.SHA {
+		return nil
+	}
+	if ok, err := c.IsAncestor(repoPath, b.SHA, sha); err != nil || !ok {
+		return fmt.Errorf("failed to fast-forward %q to %s: the branches have diverged", branch, target)
+	}
+
+	// The old value makes update-ref fail if the branch moved meanwhile
+	cmd := exec.Command("git", "-C", repoPath, "update-ref", "-m", "axe: fast-forward to "+target, "refs/heads/"+branch, sha, b.SHA)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fast-forward %q: %s", branch, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *DefaultClient) Checkout(repoPath, branch string) error {
+	cmd := exec.Command("git", "-C", repoPath, "checkout", "--quiet", branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out %q: %s", branch, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// rebaseFailure picks the line explaining why a rebase stopped: the first
+// conflict, or else git's last word
+func rebaseFailure(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "CONFLICT") {
+			return line
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func (c *DefaultClient) CreateRef(repoPath, ref, sha string) error {
+	// An empty old value makes update-ref refuse to overwrite an existing ref
+	cmd := exec.Command("git", "-C", repoPath, "update-ref", ref, sha, "")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create ref %q: %s", ref, strings.TrimSpace(string(output)))
 	}
 	return nil
 }
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// gitConfig holds parsed config files in the order git applies them
+type gitConfig []*format.Config
+
+// get returns the last value set for key, or "" if it is unset
+func (c gitConfig) get(key string) string {
+	values := c.getAll(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// getAll returns every value set for a "section.subsection.name" key, in
+// order. Section and variable names are case-insensitive, subsections aren't.
+func (c gitConfig) getAll(key string) []string {
+	section, subsection, name, ok := splitKey(key)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, cfg := range c {
+		for _, s := range cfg.Sections {
+			if !s.IsName(section) {
+				continue
+			}
+			if subsection == "" {
+				values = append(values, s.Options.GetAll(name)...)
+				continue
+			}
+			for _, sub := range s.Subsections {
+				if sub.IsName(subsection) {
+					values = append(values, sub.Options.GetAll(name)...)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// splitKey splits a "section.subsection.name" or "section.name" key
+func splitKey(key string) (section, subsection, name string, ok bool) {
+	section, rest, ok := strings.Cut(key, ".")
+	if !ok || section == "" || rest == "" {
+		return "", "", "", false
+	}
+	name = rest
+	if i := strings.LastIndex(rest, "."); i >= 0 {
+		subsection, name = rest[:i], rest[i+1:]
+	}
+	return section, subsection, name, name != ""
+}
+
+// maxIncludeDepth bounds nested include.path directives, like git does
+const maxIncludeDepth = 10
+
+// globalConfig reads the system and global config files
+func globalConfig() gitConfig {
+	var paths []string
+	if os.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		paths = append(paths, "/etc/gitconfig")
+	}
+	if global := os.Getenv("GIT_CONFIG_GLOBAL"); global != "" {
+		paths = append(paths, global)
+	} else {
+		home, _ := os.UserHomeDir()
+		xdg := os.Getenv("XDG_CONFIG_HOME")
+		if xdg == "" && home != "" {
+			xdg = filepath.Join(home, ".config")
+		}
+		if xdg != "" {
+			paths = append(paths, filepath.Join(xdg, "git", "config"))
+		}
+		if home != "" {
+			paths = append(paths, filepath.Join(home, ".gitconfig"))
+		}
+	}
+
+	var cfg gitConfig
+	for _, path := range paths {
+		cfg = append(cfg, readConfigFile(path)...)
+	}
+	return cfg
+}
+
+// readConfigFile parses a git config file and the files it includes. A file
+// that is missing or can't be parsed is skipped, as git config --get does
+// for missing files.
+func readConfigFile(path string) gitConfig {
+	return readConfigFileDepth(path, 0)
+}
+
+func readConfigFileDepth(path string, depth int) gitConfig {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	cfg := format.New()
+	if err := format.NewDecoder(f).Decode(cfg); err != nil {
+		return nil
+	}
+
+	files := gitConfig{cfg}
+	if depth >= maxIncludeDepth {
+		return files
+	}
+	for _, include := range files.getAll("include.path") {
+		if strings.HasPrefix(include, "~/") {
+			home, _ := os.UserHomeDir()
+			include = filepath.Join(home, include[2:])
+		} else if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		files = append(files, readConfigFileDepth(include, depth+1)...)
+	}
+	return files
+}
+
+// editConfig applies edit to the repository's config file, holding git's
+// config.lock so it can't race a git config running alongside. The file is
+// rewritten from its parsed form, which drops comments, so it is only
+// written when edit reports a change. A file that can't be parsed is left
+// alone.
+func (r *nativeRepo) editConfig(edit func(cfg *format.Config) bool) error {
+	path := filepath.Join(r.commonDir, "config")
+	lock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := format.New()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		rollbackLock(lock)
+		return err
+	}
+	if err := format.NewDecoder(bytes.NewReader(data)).Decode(cfg); err != nil {
+		rollbackLock(lock)
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if !edit(cfg) {
+		rollbackLock(lock)
+		return nil
+	}
+
+	var out bytes.Buffer
+	if err := format.NewEncoder(&out).Encode(cfg); err != nil {
+		rollbackLock(lock)
+		return err
+	}
+	return commitLock(lock, out.Bytes())
+}
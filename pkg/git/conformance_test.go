@@ -0,0 +1,553 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixture is a temporary repository every backend is checked against
+type fixture struct {
+	t        *testing.T
+	dir      string
+	worktree string
+	commits  int
+}
+
+// fixtureEpoch is the date of the fixture's first commit. Every later commit
+// is a minute newer.
+var fixtureEpoch = time.Unix(1700000000, 0)
+
+// newFixture builds a repository with merged, diverged and gone branches, a
+// linked worktree, remote-tracking refs and PR refs. With packed set, its refs
+// and objects are packed the way git gc leaves them.
+func newFixture(t *testing.T, packed bool) *fixture {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	f := &fixture{t: t, dir: filepath.Join(root, "repo"), worktree: filepath.Join(root, "wt")}
+	f.git("init", "--quiet", "--initial-branch=main", f.dir)
+	f.git("config", "user.name", "Dana")
+	f.git("config", "user.email", "dana@example.com")
+	f.git("config", "remote.origin.url", "https://github.com/example/repo.git")
+	f.git("config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*")
+
+	f.commit("Initial commit")
+	f.commit("Add login")
+	f.git("branch", "feature/merged")
+	f.git("branch", "behind")
+	f.commit("Add logout")
+	f.git("update-ref", "refs/remotes/origin/main", "main")
+	f.git("update-ref", "refs/remotes/origin/behind", "main")
+	f.git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	f.git("checkout", "--quiet", "-b", "feature/wip")
+	f.commit("Start the dashboard")
+	f.git("update-ref", "refs/remotes/origin/feature/wip", "HEAD")
+	f.commit("Tweak the dashboard\nacross two lines\n\nWith a body\n\nin two paragraphs")
+	f.git("update-ref", "refs/pull/7/head", "HEAD")
+
+	f.git("checkout", "--quiet", "-b", "gone", "main")
+	f.commit("Fix typo")
+	f.git("update-ref", "refs/remotes/origin/pr/9", "HEAD")
+	f.git("checkout", "--quiet", "main")
+
+	for _, b := range []string{"feature/wip", "gone", "behind"} {
+		f.git("config", "branch."+b+".remote", "origin")
+		f.git("config", "branch."+b+".merge", "refs/heads/"+b)
+	}
+	f.git("worktree", "add", "--quiet", f.worktree, "feature/merged")
+
+	if packed {
+		f.git("gc", "--quiet", "--prune=now")
+		f.git("pack-refs", "--all")
+	}
+	return f
+}
+
+// git runs a git command in the fixture and returns its trimmed output
+func (f *fixture) git(args ...string) string {
+	f.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", f.dir}, args...)...)
+	if args[0] == "init" {
+		cmd = exec.Command("git", args...)
+	}
+	date := fixtureEpoch.Add(time.Duration(f.commits) * time.Minute)
+	stamp := fmt.Sprintf("%d +0000", date.Unix())
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_AUTHOR_DATE="+stamp,
+		"GIT_COMMITTER_DATE="+stamp,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commit commits a change to a file with the given message
+func (f *fixture) commit(message string) {
+	f.t.Helper()
+	// Every commit grows the same file, which gives git gc deltas to write
+	var content strings.Builder
+	for i := range 200 * (f.commits + 1) {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	if err := os.WriteFile(filepath.Join(f.dir, "notes.txt"), []byte(content.String()), 0o644); err != nil {
+		f.t.Fatal(err)
+	}
+	f.git("add", "notes.txt")
+	f.git("commit", "--quiet", "-m", message)
+	f.commits++
+}
+
+// date returns the date of the nth commit made in the fixture
+func (f *fixture) date(n int) time.Time {
+	return fixtureEpoch.Add(time.Duration(n) * time.Minute)
+}
+
+// conformanceBackends creates a client for every backend
+func conformanceBackends(t *testing.T) map[string]Client {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	clients := make(map[string]Client)
+	for _, backend := range Backends {
+		client, err := NewClient(backend)
+		if err != nil {
+			t.Fatalf("NewClient(%q) error = %v", backend, err)
+		}
+		clients[backend] = client
+	}
+	return clients
+}
+
+func TestConformance(t *testing.T) {
+	// Keep the user's config out of config lookups
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	for _, layout := range []string{"loose", "packed"} {
+		for name, client := range conformanceBackends(t) {
+			t.Run(name+"/"+layout, func(t *testing.T) {
+				f := newFixture(t, layout == "packed")
+				testReading(t, f, client)
+				testWriting(t, f, client)
+			})
+		}
+	}
+}
+
+// testReading checks every read-only operation
+func testReading(t *testing.T, f *fixture, client Client) {
+	if err := client.ValidateRepository(f.dir); err != nil {
+		t.Errorf("ValidateRepository() error = %v", err)
+	}
+	if err := client.ValidateRepository(t.TempDir()); err == nil {
+		t.Error("ValidateRepository() outside a repository = nil, want error")
+	}
+
+	branches, err := client.ListBranches(f.dir)
+	if err != nil {
+		t.Fatalf("ListBranches() error = %v", err)
+	}
+	for i := range branches {
+		if branches[i].WorktreePath != "" {
+			branches[i].WorktreePath = realPath(branches[i].WorktreePath)
+		}
+	}
+	want := []Branch{
+		{Name: "behind", SHA: f.git("rev-parse", "behind"), CommitDate: f.date(1), Subject: "Add login", Author: "Dana",
+			Upstream: Upstream{Ref: "origin/behind", Behind: 1}},
+		{Name: "feature/merged", SHA: f.git("rev-parse", "feature/merged"), CommitDate: f.date(1), Subject: "Add login", Author: "Dana",
+			WorktreePath: realPath(f.worktree)},
+		{Name: "feature/wip", SHA: f.git("rev-parse", "feature/wip"), CommitDate: f.date(4), Subject: "Tweak the dashboard across two lines", Author: "Dana",
+			Upstream: Upstream{Ref: "origin/feature/wip", Ahead: 1}},
+		{Name: "gone", SHA: f.git("rev-parse", "gone"), CommitDate: f.date(5), Subject: "Fix typo", Author: "Dana",
+			Upstream: Upstream{Ref: "origin/gone", Gone: true}},
+		{Name: "main", SHA: f.git("rev-parse", "main"), CommitDate: f.date(2), Subject: "Add logout", Author: "Dana",
+			IsHead: true, WorktreePath: realPath(f.dir)},
+	}
+	if !reflect.DeepEqual(branches, want) {
+		t.Errorf("ListBranches() =\n%+v\nwant\n%+v", branches, want)
+	}
+
+	if got, err := client.GetDefaultBranch(f.dir); err != nil || got != "main" {
+		t.Errorf("GetDefaultBranch() = %q, %v, want main", got, err)
+	}
+
+	commits, err := client.GetCommits(f.dir, "feature/wip", "main", 0)
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	wantCommits := []Commit{
+		{SHA: f.git("rev-parse", "feature/wip"), Subject: "Tweak the dashboard across two lines", Body: "With a body\n\nin two paragraphs", Author: "Dana", AuthorEmail: "dana@example.com", Date: f.date(4)},
+		{SHA: f.git("rev-parse", "feature/wip~1"), Subject: "Start the dashboard", Author: "Dana", AuthorEmail: "dana@example.com", Date: f.date(3)},
+	}
+	if !reflect.DeepEqual(commits, wantCommits) {
+		t.Errorf("GetCommits() = %+v, want %+v", commits, wantCommits)
+	}
+	if commits, err := client.GetCommits(f.dir, "main", "", 1); err != nil || len(commits) != 1 || commits[0].Subject != "Add logout" {
+		t.Errorf("GetCommits() with limit = %+v, %v, want the tip of main", commits, err)
+	}
+	if _, err := client.GetCommits(f.dir, "missing", "", 1); err == nil {
+		t.Error("GetCommits() for a missing revision = nil, want error")
+	}
+
+	counts := []struct {
+		name string
+		fn   func(repoPath, rev, exclude string) (int, error)
+		rev  string
+		excl string
+		want int
+	}{
+		{"CountCommits", client.CountCommits, "feature/wip", "main", 2},
+		{"CountCommits", client.CountCommits, "main", "", 3},
+		{"CountCommits", client.CountCommits, "feature/merged", "main", 0},
+		{"CountUnpushedCommits", client.CountUnpushedCommits, "feature/wip", "main", 1},
+		// gone's tip is only on origin as a PR ref
+		{"CountUnpushedCommits", client.CountUnpushedCommits, "gone", "", 0},
+		{"CountUnpushedCommits", client.CountUnpushedCommits, "behind", "main", 0},
+	}
+	for _, tt := range counts {
+		if got, err := tt.fn(f.dir, tt.rev, tt.excl); err != nil || got != tt.want {
+			t.Errorf("%s(%q, %q) = %d, %v, want %d", tt.name, tt.rev, tt.excl, got, err, tt.want)
+		}
+	}
+
+	ancestors := []struct {
+		rev, target string
+		want        bool
+	}{
+		{"feature/merged", "main", true},
+		{"main", "feature/wip", true},
+		{"feature/wip", "main", false},
+		{"gone", "feature/wip", false},
+	}
+	for _, tt := range ancestors {
+		if got, err := client.IsAncestor(f.dir, tt.rev, tt.target); err != nil || got != tt.want {
+			t.Errorf("IsAncestor(%q, %q) = %v, %v, want %v", tt.rev, tt.target, got, err, tt.want)
+		}
+	}
+
+	bases := []struct {
+		a, b, want string
+	}{
+		{"feature/wip", "gone", f.git("rev-parse", "main")},
+		{"feature/merged", "main", f.git("rev-parse", "feature/merged")},
+	}
+	for _, tt := range bases {
+		if got, err := client.MergeBase(f.dir, tt.a, tt.b); err != nil || got != tt.want {
+			t.Errorf("MergeBase(%q, %q) = %q, %v, want %q", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+
+	pulls, err := client.GetPullRefs(f.dir)
+	wantPulls := map[string]int{f.git("rev-parse", "feature/wip"): 7, f.git("rev-parse", "gone"): 9}
+	if err != nil || !reflect.DeepEqual(pulls, wantPulls) {
+		t.Errorf("GetPullRefs() = %v, %v, want %v", pulls, err, wantPulls)
+	}
+
+	if got, err := client.GetConfig(f.dir, "user.email"); err != nil || got != "dana@example.com" {
+		t.Errorf("GetConfig(user.email) = %q, %v", got, err)
+	}
+	if got, err := client.GetConfig(f.dir, "branch.feature/wip.merge"); err != nil || got != "refs/heads/feature/wip" {
+		t.Errorf("GetConfig(branch.feature/wip.merge) = %q, %v", got, err)
+	}
+	if got, err := client.GetConfig(f.dir, "axe.unset"); err != nil || got != "" {
+		t.Errorf("GetConfig(axe.unset) = %q, %v, want empty", got, err)
+	}
+
+	for _, path := range []string{f.dir, f.worktree} {
+		if got, err := client.GetGitDir(path); err != nil || realPath(got) != realPath(filepath.Join(f.dir, ".git")) {
+			t.Errorf("GetGitDir(%q) = %q, %v", path, got, err)
+		}
+	}
+	if got, err := client.GetHooksDir(f.dir); err != nil || realPath(got) != realPath(filepath.Join(f.dir, ".git", "hooks")) {
+		t.Errorf("GetHooksDir() = %q, %v", got, err)
+	}
+}
+
+// testWriting checks the operations that create and delete refs
+func testWriting(t *testing.T, f *fixture, client Client) {
+	sha := f.git("rev-parse", "gone")
+	ref := "refs/axe/recovery/20231114-221320/gone"
+	if err := client.CreateRef(f.dir, ref, sha); err != nil {
+		t.Fatalf("CreateRef() error = %v", err)
+	}
+	if got := f.git("rev-parse", ref); got != sha {
+		t.Errorf("CreateRef() wrote %s, want %s", got, sha)
+	}
+	if err := client.CreateRef(f.dir, ref, sha); err == nil {
+		t.Error("CreateRef() for an existing ref = nil, want error")
+	}
+
+	gone := []Branch{{Name: "gone", SHA: sha}}
+	if err := client.DeleteBranches(f.dir, gone); err != nil {
+		t.Fatalf("DeleteBranches() error = %v", err)
+	}
+	if output := f.git("branch", "--list", "gone"); output != "" {
+		t.Errorf("DeleteBranches() left the branch behind: %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(f.dir, ".git", "logs", "refs", "heads", "gone")); !os.IsNotExist(err) {
+		t.Errorf("DeleteBranches() left the reflog behind: %v", err)
+	}
+	if err := client.DeleteBranches(f.dir, gone); err == nil {
+		t.Error("DeleteBranches() for a missing branch = nil, want error")
+	}
+
+	// A branch that moved since it was read aborts the whole batch
+	batch := []Branch{
+		{Name: "behind", SHA: f.git("rev-parse", "behind")},
+		{Name: "feature/wip", SHA: f.git("rev-parse", "main")},
+	}
+	if err := client.DeleteBranches(f.dir, batch); err == nil {
+		t.Error("DeleteBranches() with a stale SHA = nil, want error")
+	}
+	if output := f.git("branch", "--list", "behind", "feature/wip"); strings.Count(output, "\n") != 1 {
+		t.Errorf("DeleteBranches() with a stale SHA deleted branches, left %q", output)
+	}
+
+	// So does a branch another git command has locked
+	batch[1].SHA = f.git("rev-parse", "feature/wip")
+	lock := filepath.Join(f.dir, ".git", "refs", "heads", "behind.lock")
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteBranches(f.dir, batch); err == nil {
+		t.Error("DeleteBranches() with a locked branch = nil, want error")
+	}
+	if output := f.git("branch", "--list", "behind", "feature/wip"); strings.Count(output, "\n") != 1 {
+		t.Errorf("DeleteBranches() with a locked branch deleted branches, left %q", output)
+	}
+	if err := os.Remove(lock); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteBranches(f.dir, batch); err != nil {
+		t.Fatalf("DeleteBranches() error = %v", err)
+	}
+	for _, b := range batch {
+		if got, err := client.GetConfig(f.dir, "branch."+b.Name+".remote"); err != nil || got != "" {
+			t.Errorf("DeleteBranches() left branch.%s.remote = %q, %v behind", b.Name, got, err)
+		}
+	}
+
+	// Config writes are read back, and unsetting twice is fine
+	if err := client.SetConfig(f.dir, "branch.main.axeKeep", "2026-12-01"); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	if got, err := client.GetConfig(f.dir, "branch.main.axekeep"); err != nil || got != "2026-12-01" {
+		t.Errorf("GetConfig() after SetConfig() = %q, %v, want 2026-12-01", got, err)
+	}
+	if got := f.git("config", "branch.main.axeKeep"); got != "2026-12-01" {
+		t.Errorf("git config after SetConfig() = %q, want 2026-12-01", got)
+	}
+	for range 2 {
+		if err := client.UnsetConfig(f.dir, "branch.main.axeKeep"); err != nil {
+			t.Fatalf("UnsetConfig() error = %v", err)
+		}
+	}
+	if got, err := client.GetConfig(f.dir, "branch.main.axeKeep"); err != nil || got != "" {
+		t.Errorf("GetConfig() after UnsetConfig() = %q, %v, want empty", got, err)
+	}
+
+	// A relative core.hooksPath is relative to the worktree
+	if err := client.SetConfig(f.dir, "core.hooksPath", ".githooks"); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	if got, err := client.GetHooksDir(f.dir); err != nil || realPath(filepath.Dir(got)) != realPath(f.dir) || filepath.Base(got) != ".githooks" {
+		t.Errorf("GetHooksDir() with core.hooksPath = %q, %v, want %s", got, err, filepath.Join(f.dir, ".githooks"))
+	}
+	if err := client.UnsetConfig(f.dir, "core.hooksPath"); err != nil {
+		t.Fatalf("UnsetConfig() error = %v", err)
+	}
+
+	// Other refs survive, including packed ones
+	branches, err := client.ListBranches(f.dir)
+	if err != nil || len(branches) != 2 {
+		t.Errorf("ListBranches() after delete = %+v, %v, want 2 branches", branches, err)
+	}
+	if output := f.git("for-each-ref", "--format=%(refname)", "refs/heads/"); strings.Contains(output, "gone") || strings.Contains(output, "behind") || strings.Contains(output, "wip") {
+		t.Errorf("git still lists deleted branches: %q", output)
+	}
+	f.git("fsck", "--no-dangling")
+}
+
+func TestConformance_EmptyRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	empty := t.TempDir()
+	if output, err := exec.Command("git", "init", "--quiet", empty).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+
+	for name, client := range conformanceBackends(t) {
+		branches, err := client.ListBranches(empty)
+		if err != nil || branches == nil || len(branches) != 0 {
+			t.Errorf("%s: ListBranches() on an empty repository = %#v, %v, want no branches", name, branches, err)
+		}
+	}
+}
+
+func TestNewClient_InvalidBackend(t *testing.T) {
+	if _, err := NewClient("libgit2"); err == nil {
+		t.Error("NewClient(libgit2) = nil, want error")
+	}
+}
+
+func TestNewClient_MissingGit(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	for _, backend := range Backends {
+		if _, err := NewClient(backend); err == nil {
+			t.Errorf("NewClient(%q) without git = nil, want error", backend)
+		}
+	}
+}
+
+func TestConformance_RebaseOnto(t *testing.T) {
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	for name, client := range conformanceBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := exec.LookPath("git"); err != nil {
+				t.Skip("git is not installed")
+			}
+			f := &fixture{t: t, dir: t.TempDir()}
+			f.git("init", "--quiet", "--initial-branch=main", f.dir)
+			f.git("config", "user.name", "Dana")
+			f.git("config", "user.email", "dana@example.com")
+			f.commit("Initial commit")
+			base := f.git("rev-parse", "HEAD")
+
+			// A stack of parent and child, then the parent squash-merged
+			f.git("checkout", "--quiet", "-b", "parent")
+			f.writeAndCommit("parent.txt", "Add parent")
+			parentTip := f.git("rev-parse", "HEAD")
+			f.git("checkout", "--quiet", "-b", "child")
+			f.writeAndCommit("child.txt", "Add child")
+			f.git("checkout", "--quiet", "-b", "clash", base)
+			f.commit("Rewrite notes")
+			f.git("checkout", "--quiet", "main")
+			f.writeAndCommit("parent.txt", "Add parent (#1)")
+			squash := f.git("rev-parse", "HEAD")
+			f.commit("Rewrite notes differently")
+
+			if err := client.RebaseOnto(f.dir, "child", squash, parentTip); err != nil {
+				t.Fatalf("RebaseOnto() error = %v", err)
+			}
+			if got := f.git("rev-list", "--count", squash+"..child"); got != "1" {
+				t.Errorf("child has %s commits on top of the squash commit, want 1", got)
+			}
+			if got := f.git("rev-parse", "child~1"); got != squash {
+				t.Errorf("child is based on %s, want %s", got, squash)
+			}
+			if got := f.git("symbolic-ref", "--short", "HEAD"); got != "main" {
+				t.Errorf("HEAD = %s after RebaseOnto(), want main", got)
+			}
+
+			// A conflict is aborted and leaves everything as it was
+			clash := f.git("rev-parse", "clash")
+			if err := client.RebaseOnto(f.dir, "clash", "main", base); err == nil {
+				t.Error("RebaseOnto() with a conflict = nil, want error")
+			}
+			if got := f.git("rev-parse", "clash"); got != clash {
+				t.Errorf("clash moved to %s after a failed rebase, want %s", got, clash)
+			}
+			if got := f.git("symbolic-ref", "--short", "HEAD"); got != "main" {
+				t.Errorf("HEAD = %s after a failed RebaseOnto(), want main", got)
+			}
+			if got := f.git("status", "--porcelain"); got != "" {
+				t.Errorf("worktree is dirty after a failed RebaseOnto(): %q", got)
+			}
+		})
+	}
+}
+
+// writeAndCommit commits a new file named after itself
+func (f *fixture) writeAndCommit(name, message string) {
+	f.t.Helper()
+	if err := os.WriteFile(filepath.Join(f.dir, name), []byte(name+"\n"), 0o644); err != nil {
+		f.t.Fatal(err)
+	}
+	f.git("add", name)
+	f.git("commit", "--quiet", "-m", message)
+	f.commits++
+}
+
+func TestConformance_SyncOperations(t *testing.T) {
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	for name, client := range conformanceBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := exec.LookPath("git"); err != nil {
+				t.Skip("git is not installed")
+			}
+			f := &fixture{t: t, dir: t.TempDir()}
+			f.git("init", "--quiet", "--initial-branch=main", f.dir)
+			f.git("config", "user.name", "Dana")
+			f.git("config", "user.email", "dana@example.com")
+			f.commit("Initial commit")
+			f.git("branch", "behind")
+			f.git("branch", "diverged")
+			f.git("branch", "merged")
+			f.writeAndCommit("main.txt", "Add main")
+			main := f.git("rev-parse", "main")
+
+			// A clone, so there is a remote to fetch and prune
+			clone := &fixture{t: t, dir: t.TempDir()}
+			clone.git("clone", "--quiet", f.dir, clone.dir)
+			f.git("branch", "-D", "merged")
+			f.writeAndCommit("more.txt", "Add more")
+			if err := client.Fetch(clone.dir, "origin", true); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if got := clone.git("for-each-ref", "refs/remotes/origin/merged"); got != "" {
+				t.Errorf("Fetch() with prune left %q", got)
+			}
+
+			// A branch that isn't checked out moves with update-ref
+			if err := client.FastForward(f.dir, "behind", "main"); err != nil {
+				t.Fatalf("FastForward() error = %v", err)
+			}
+			if got, want := f.git("rev-parse", "behind"), f.git("rev-parse", "main"); got != want {
+				t.Errorf("behind = %s after FastForward(), want %s", got, want)
+			}
+			f.git("checkout", "--quiet", "diverged")
+			f.commit("Diverge")
+			f.git("checkout", "--quiet", "main")
+			if err := client.FastForward(f.dir, "diverged", "main"); err == nil {
+				t.Error("FastForward() of a diverged branch = nil, want error")
+			}
+
+			// The checked out branch takes its files with it
+			f.git("reset", "--quiet", "--hard", main)
+			if err := client.FastForward(f.dir, "main", "behind"); err != nil {
+				t.Fatalf("FastForward() of the checked out branch error = %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(f.dir, "more.txt")); err != nil {
+				t.Errorf("worktree didn't follow the fast-forward: %v", err)
+			}
+
+			if err := client.Checkout(f.dir, "behind"); err != nil {
+				t.Fatalf("Checkout() error = %v", err)
+			}
+			if got := f.git("symbolic-ref", "--short", "HEAD"); got != "behind" {
+				t.Errorf("HEAD = %s after Checkout(), want behind", got)
+			}
+		})
+	}
+}
@@ -39,33 +39,324 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
-// DeleteBranch mocks base method.
-func (m *MockClient) DeleteBranch(repoPath, branch string) error {
+// Checkout mocks base method.
+func (m *MockClient) Checkout(repoPath, branch string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBranch", repoPath, branch)
+	ret := m.ctrl.Call(m, "Checkout", repoPath, branch)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteBranch indicates an expected call of DeleteBranch.
-func (mr *MockClientMockRecorder) DeleteBranch(repoPath, branch any) *gomock.Call {
+// Checkout indicates an expected call of Checkout.
+func (mr *MockClientMockRecorder) Checkout(repoPath, branch any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBranch", reflect.TypeOf((*MockClient)(nil).DeleteBranch), repoPath, branch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockClient)(nil).Checkout), repoPath, branch)
 }
 
-// GetLocalBranches mocks base method.
-func (m *MockClient) GetLocalBranches(repoPath string) ([]string, error) {
+// CountCommits mocks base method.
+func (m *MockClient) CountCommits(repoPath, rev, exclude string) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLocalBranches", repoPath)
-	ret0, _ := ret[0].([]string)
+	ret := m.ctrl.Call(m, "CountCommits", repoPath, rev, exclude)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetLocalBranches indicates an expected call of GetLocalBranches.
-func (mr *MockClientMockRecorder) GetLocalBranches(repoPath any) *gomock.Call {
+// CountCommits indicates an expected call of CountCommits.
+func (mr *MockClientMockRecorder) CountCommits(repoPath, rev, exclude any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocalBranches", reflect.TypeOf((*MockClient)(nil).GetLocalBranches), repoPath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCommits", reflect.TypeOf((*MockClient)(nil).CountCommits), repoPath, rev, exclude)
+}
+
+// CountUnappliedCommits mocks base method.
+func (m *MockClient) CountUnappliedCommits(repoPath, target, rev string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnappliedCommits", repoPath, target, rev)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnappliedCommits indicates an expected call of CountUnappliedCommits.
+func (mr *MockClientMockRecorder) CountUnappliedCommits(repoPath, target, rev any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnappliedCommits", reflect.TypeOf((*MockClient)(nil).CountUnappliedCommits), repoPath, target, rev)
+}
+
+// CountUnpushedCommits mocks base method.
+func (m *MockClient) CountUnpushedCommits(repoPath, rev, exclude string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnpushedCommits", repoPath, rev, exclude)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnpushedCommits indicates an expected call of CountUnpushedCommits.
+func (mr *MockClientMockRecorder) CountUnpushedCommits(repoPath, rev, exclude any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnpushedCommits", reflect.TypeOf((*MockClient)(nil).CountUnpushedCommits), repoPath, rev, exclude)
+}
+
+// CreateRef mocks base method.
+func (m *MockClient) CreateRef(repoPath, ref, sha string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRef", repoPath, ref, sha)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRef indicates an expected call of CreateRef.
+func (mr *MockClientMockRecorder) CreateRef(repoPath, ref, sha any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRef", reflect.TypeOf((*MockClient)(nil).CreateRef), repoPath, ref, sha)
+}
+
+// DeleteBranches mocks base method.
+func (m *MockClient) DeleteBranches(repoPath string, branches []Branch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBranches", repoPath, branches)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBranches indicates an expected call of DeleteBranches.
+func (mr *MockClientMockRecorder) DeleteBranches(repoPath, branches any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBranches", reflect.TypeOf((*MockClient)(nil).DeleteBranches), repoPath, branches)
+}
+
+// FastForward mocks base method.
+func (m *MockClient) FastForward(repoPath, branch, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FastForward", repoPath, branch, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FastForward indicates an expected call of FastForward.
+func (mr *MockClientMockRecorder) FastForward(repoPath, branch, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FastForward", reflect.TypeOf((*MockClient)(nil).FastForward), repoPath, branch, target)
+}
+
+// Fetch mocks base method.
+func (m *MockClient) Fetch(repoPath, remote string, prune bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", repoPath, remote, prune)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockClientMockRecorder) Fetch(repoPath, remote, prune any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockClient)(nil).Fetch), repoPath, remote, prune)
+}
+
+// FetchBranch mocks base method.
+func (m *MockClient) FetchBranch(repoPath, remote, branch string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchBranch", repoPath, remote, branch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FetchBranch indicates an expected call of FetchBranch.
+func (mr *MockClientMockRecorder) FetchBranch(repoPath, remote, branch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchBranch", reflect.TypeOf((*MockClient)(nil).FetchBranch), repoPath, remote, branch)
+}
+
+// FetchPullRefs mocks base method.
+func (m *MockClient) FetchPullRefs(repoPath, remote string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchPullRefs", repoPath, remote)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FetchPullRefs indicates an expected call of FetchPullRefs.
+func (mr *MockClientMockRecorder) FetchPullRefs(repoPath, remote any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPullRefs", reflect.TypeOf((*MockClient)(nil).FetchPullRefs), repoPath, remote)
+}
+
+// GetCommits mocks base method.
+func (m *MockClient) GetCommits(repoPath, rev, exclude string, limit int) ([]Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommits", repoPath, rev, exclude, limit)
+	ret0, _ := ret[0].([]Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommits indicates an expected call of GetCommits.
+func (mr *MockClientMockRecorder) GetCommits(repoPath, rev, exclude, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommits", reflect.TypeOf((*MockClient)(nil).GetCommits), repoPath, rev, exclude, limit)
+}
+
+// GetConfig mocks base method.
+func (m *MockClient) GetConfig(repoPath, key string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfig", repoPath, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfig indicates an expected call of GetConfig.
+func (mr *MockClientMockRecorder) GetConfig(repoPath, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockClient)(nil).GetConfig), repoPath, key)
+}
+
+// GetDefaultBranch mocks base method.
+func (m *MockClient) GetDefaultBranch(repoPath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultBranch", repoPath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultBranch indicates an expected call of GetDefaultBranch.
+func (mr *MockClientMockRecorder) GetDefaultBranch(repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultBranch", reflect.TypeOf((*MockClient)(nil).GetDefaultBranch), repoPath)
+}
+
+// GetGitDir mocks base method.
+func (m *MockClient) GetGitDir(repoPath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitDir", repoPath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitDir indicates an expected call of GetGitDir.
+func (mr *MockClientMockRecorder) GetGitDir(repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitDir", reflect.TypeOf((*MockClient)(nil).GetGitDir), repoPath)
+}
+
+// GetHooksDir mocks base method.
+func (m *MockClient) GetHooksDir(repoPath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHooksDir", repoPath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHooksDir indicates an expected call of GetHooksDir.
+func (mr *MockClientMockRecorder) GetHooksDir(repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHooksDir", reflect.TypeOf((*MockClient)(nil).GetHooksDir), repoPath)
+}
+
+// GetPullRefs mocks base method.
+func (m *MockClient) GetPullRefs(repoPath string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPullRefs", repoPath)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPullRefs indicates an expected call of GetPullRefs.
+func (mr *MockClientMockRecorder) GetPullRefs(repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRefs", reflect.TypeOf((*MockClient)(nil).GetPullRefs), repoPath)
+}
+
+// IsAncestor mocks base method.
+func (m *MockClient) IsAncestor(repoPath, rev, target string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAncestor", repoPath, rev, target)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAncestor indicates an expected call of IsAncestor.
+func (mr *MockClientMockRecorder) IsAncestor(repoPath, rev, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAncestor", reflect.TypeOf((*MockClient)(nil).IsAncestor), repoPath, rev, target)
+}
+
+// ListBranches mocks base method.
+func (m *MockClient) ListBranches(repoPath string) ([]Branch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBranches", repoPath)
+	ret0, _ := ret[0].([]Branch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBranches indicates an expected call of ListBranches.
+func (mr *MockClientMockRecorder) ListBranches(repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranches", reflect.TypeOf((*MockClient)(nil).ListBranches), repoPath)
+}
+
+// MergeBase mocks base method.
+func (m *MockClient) MergeBase(repoPath, a, b string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeBase", repoPath, a, b)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeBase indicates an expected call of MergeBase.
+func (mr *MockClientMockRecorder) MergeBase(repoPath, a, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeBase", reflect.TypeOf((*MockClient)(nil).MergeBase), repoPath, a, b)
+}
+
+// RebaseOnto mocks base method.
+func (m *MockClient) RebaseOnto(repoPath, branch, onto, upstream string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebaseOnto", repoPath, branch, onto, upstream)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebaseOnto indicates an expected call of RebaseOnto.
+func (mr *MockClientMockRecorder) RebaseOnto(repoPath, branch, onto, upstream any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebaseOnto", reflect.TypeOf((*MockClient)(nil).RebaseOnto), repoPath, branch, onto, upstream)
+}
+
+// SetConfig mocks base method.
+func (m *MockClient) SetConfig(repoPath, key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetConfig", repoPath, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetConfig indicates an expected call of SetConfig.
+func (mr *MockClientMockRecorder) SetConfig(repoPath, key, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConfig", reflect.TypeOf((*MockClient)(nil).SetConfig), repoPath, key, value)
+}
+
+// UnsetConfig mocks base method.
+func (m *MockClient) UnsetConfig(repoPath, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsetConfig", repoPath, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsetConfig indicates an expected call of UnsetConfig.
+func (mr *MockClientMockRecorder) UnsetConfig(repoPath, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsetConfig", reflect.TypeOf((*MockClient)(nil).UnsetConfig), repoPath, key)
 }
 
 // ValidateRepository mocks base method.
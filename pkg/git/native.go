@@ -0,0 +1,816 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Backends select how git operations are carried out
+const (
+	// BackendExec runs the git binary for every operation
+	BackendExec = "git"
+	// BackendNative reads the repository in-process with go-git
+	BackendNative = "native"
+)
+
+// Backends lists every backend
+var Backends = []string{BackendExec, BackendNative}
+
+// NewClient creates the Client for a backend. An empty backend selects
+// BackendExec. Both backends need the git binary, the native one for the
+// operations it leaves to git, so NewClient fails up front when it isn't
+// installed rather than partway through a chop.
+func NewClient(backend string) (Client, error) {
+	switch backend {
+	case "", BackendExec, BackendNative:
+	default:
+		return nil, fmt.Errorf("invalid git backend %q (valid backends: %s)", backend, strings.Join(Backends, ", "))
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		if backend == BackendNative {
+			return nil, fmt.Errorf("the native git backend still runs git to fetch, rebase, fast-forward and check out branches, but git isn't installed: %w", err)
+		}
+		return nil, fmt.Errorf("git isn't installed: %w", err)
+	}
+	if backend == BackendNative {
+		return NewNativeClient(), nil
+	}
+	return NewDefaultClient(), nil
+}
+
+// NativeClient implements Client with go-git, so reading branches, commits
+// and config doesn't start a git process per call. Branches are deleted and
+// refs and config written in-process too, under the same lock files git
+// takes, so they stay safe alongside other git commands. Fetching, patch
+// equivalence (git cherry), rebasing, fast-forwards and checkouts go to the
+// fallback client, which runs the git binary.
+type NativeClient struct {
+	fallback Client
+
+	mu    sync.Mutex
+	repos map[string]*nativeRepo
+}
+
+// NewNativeClient creates a NativeClient that falls back to a DefaultClient
+func NewNativeClient() *NativeClient {
+	return &NativeClient{fallback: NewDefaultClient(), repos: make(map[string]*nativeRepo)}
+}
+
+// nativeRepo is a repository opened with go-git
+type nativeRepo struct {
+	// mu serializes go-git calls, which aren't safe for concurrent use
+	mu   sync.Mutex
+	repo *gogit.Repository
+	// gitDir is the git directory of the worktree the repository was opened
+	// from, and commonDir the one holding what all worktrees share
+	gitDir    string
+	commonDir string
+	// workTree is the top level of the worktree, or empty for bare repositories
+	workTree string
+	// reachable caches the commits reachable from sets of commits, keyed
+	// by their sorted hashes. History never changes under a hash.
+	reachable map[string]map[plumbing.Hash]bool
+}
+
+// open returns the repository at repoPath, reusing it between calls
+func (c *NativeClient) open(repoPath string) (*nativeRepo, error) {
+	key, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r, ok := c.repos[key]; ok {
+		return r, nil
+	}
+
+	gitDir, workTree, err := locate(key)
+	if err != nil {
+		return nil, err
+	}
+	path := workTree
+	if path == "" {
+		path = gitDir
+	}
+	repo, err := gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, err
+	}
+	r := &nativeRepo{
+		repo:      repo,
+		gitDir:    gitDir,
+		commonDir: commonDir(gitDir),
+		workTree:  workTree,
+		reachable: make(map[string]map[plumbing.Hash]bool),
+	}
+	c.repos[key] = r
+	return r, nil
+}
+
+// locate finds the git directory and worktree of the repository containing
+// path the way git does, by looking for a .git directory or file in path and
+// its parents
+func locate(path string) (gitDir, workTree string, err error) {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if isGitDir(dir) {
+			// path is a bare repository or a git directory itself
+			return dir, "", nil
+		}
+
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dotGit, dir, nil
+			}
+			// Linked worktrees and submodules have a "gitdir: <path>" file
+			data, err := os.ReadFile(dotGit)
+			if err != nil {
+				return "", "", err
+			}
+			target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+			if !ok {
+				return "", "", fmt.Errorf("invalid gitfile format: %s", dotGit)
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			return filepath.Clean(target), dir, nil
+		}
+
+		if filepath.Dir(dir) == dir {
+			return "", "", fmt.Errorf("not a git repository: %s", path)
+		}
+	}
+}
+
+// isGitDir reports whether dir looks like a git directory
+func isGitDir(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(commonDir(dir), "objects"))
+	return err == nil && info.IsDir()
+}
+
+// commonDir returns the directory holding the refs, objects and config that
+// the worktree with git directory gitDir shares with the others
+func commonDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// headRef returns the branch ref the HEAD in gitDir points at, or "" when it
+// is detached
+func headRef(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: ")
+	if !ok {
+		return ""
+	}
+	return target
+}
+
+// worktrees maps each checked out branch ref to the top level of the
+// worktree it is checked out in. go-git doesn't know about linked worktrees,
+// so their administrative files are read directly.
+func (r *nativeRepo) worktrees(cfg gitConfig) map[string]string {
+	checkedOut := make(map[string]string)
+
+	// The main worktree's HEAD lives in the common directory
+	if main, ok := r.mainWorkTree(cfg); ok {
+		if ref := headRef(r.commonDir); ref != "" {
+			checkedOut[ref] = main
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(r.commonDir, "worktrees"))
+	for _, entry := range entries {
+		dir := filepath.Join(r.commonDir, "worktrees", entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, "gitdir"))
+		if err != nil {
+			continue
+		}
+		if ref := headRef(dir); ref != "" {
+			checkedOut[ref] = filepath.Dir(strings.TrimSpace(string(data)))
+		}
+	}
+	return checkedOut
+}
+
+// mainWorkTree returns the top level of the main worktree, unless the
+// repository is bare
+func (r *nativeRepo) mainWorkTree(cfg gitConfig) (string, bool) {
+	if r.gitDir == r.commonDir && r.workTree != "" {
+		return realPath(r.workTree), true
+	}
+	if cfg.get("core.bare") == "true" {
+		return "", false
+	}
+	if wt := cfg.get("core.worktree"); wt != "" {
+		if !filepath.IsAbs(wt) {
+			wt = filepath.Join(r.commonDir, wt)
+		}
+		return realPath(wt), true
+	}
+	if filepath.Base(r.commonDir) != ".git" {
+		return "", false
+	}
+	return realPath(filepath.Dir(r.commonDir)), true
+}
+
+// realPath resolves symlinks in path, returning it unchanged on failure
+func realPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// config reads the config files git would for the repository
+func (r *nativeRepo) config() gitConfig {
+	cfg := append(globalConfig(), readConfigFile(filepath.Join(r.commonDir, "config"))...)
+	if cfg.get("extensions.worktreeconfig") == "true" {
+		cfg = append(cfg, readConfigFile(filepath.Join(r.gitDir, "config.worktree"))...)
+	}
+	return cfg
+}
+
+// refs returns every ref under prefix, sorted by name, with symbolic refs
+// resolved
+func (r *nativeRepo) refs(prefix string) ([]*plumbing.Reference, error) {
+	iter, err := r.repo.Storer.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+	var refs []*plumbing.Reference
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if !strings.HasPrefix(ref.Name().String(), prefix) {
+			return nil
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			resolved, err := storer.ResolveReference(r.repo.Storer, ref.Name())
+			if err != nil {
+				return nil
+			}
+			ref = plumbing.NewHashReference(ref.Name(), resolved.Hash())
+		}
+		refs = append(refs, ref)
+		return nil
+	})
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name() < refs[j].Name() })
+	return refs, err
+}
+
+// resolve returns the commit a revision names
+func (r *nativeRepo) resolve(rev string) (plumbing.Hash, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("unknown revision %q: %w", rev, err)
+	}
+	return *hash, nil
+}
+
+// commit returns the commit at hash, peeling annotated tags
+func (r *nativeRepo) commit(hash plumbing.Hash) (*object.Commit, error) {
+	commit, err := r.repo.CommitObject(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		if tag, tagErr := r.repo.TagObject(hash); tagErr == nil {
+			return tag.Commit()
+		}
+	}
+	return commit, err
+}
+
+// reachableFrom returns every commit reachable from tips
+func (r *nativeRepo) reachableFrom(tips []plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	keys := make([]string, len(tips))
+	for i, tip := range tips {
+		keys[i] = tip.String()
+	}
+	sort.Strings(keys)
+	key := strings.Join(keys, " ")
+	if set, ok := r.reachable[key]; ok {
+		return set, nil
+	}
+
+	set := make(map[plumbing.Hash]bool)
+	for _, tip := range tips {
+		if set[tip] {
+			continue
+		}
+		commit, err := r.commit(tip)
+		if err != nil {
+			return nil, err
+		}
+		// Commits already in the set are skipped along with their history
+		err = object.NewCommitPreorderIter(commit, set, nil).ForEach(func(c *object.Commit) error {
+			set[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.reachable[key] = set
+	return set, nil
+}
+
+// walk returns up to limit commits reachable from rev but not from excludes,
+// newest first like git log. A limit of 0 means no limit.
+func (r *nativeRepo) walk(rev plumbing.Hash, excludes []plumbing.Hash, limit int) ([]*object.Commit, error) {
+	start, err := r.commit(rev)
+	if err != nil {
+		return nil, err
+	}
+	var excluded map[plumbing.Hash]bool
+	if len(excludes) > 0 {
+		if excluded, err = r.reachableFrom(excludes); err != nil {
+			return nil, err
+		}
+	}
+
+	var commits []*object.Commit
+	err = object.NewCommitIterCTime(start, excluded, nil).ForEach(func(c *object.Commit) error {
+		commits = append(commits, c)
+		if limit > 0 && len(commits) == limit {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return commits, err
+}
+
+// count counts the commits reachable from rev but not from excludes
+func (r *nativeRepo) count(rev plumbing.Hash, excludes []plumbing.Hash) (int, error) {
+	commits, err := r.walk(rev, excludes, 0)
+	return len(commits), err
+}
+
+// resolveAll resolves rev and, if not empty, exclude
+func (r *nativeRepo) resolveAll(rev, exclude string) (plumbing.Hash, []plumbing.Hash, error) {
+	hash, err := r.resolve(rev)
+	if err != nil {
+		return plumbing.ZeroHash, nil, err
+	}
+	var excludes []plumbing.Hash
+	if exclude != "" {
+		excluded, err := r.resolve(exclude)
+		if err != nil {
+			return plumbing.ZeroHash, nil, err
+		}
+		excludes = append(excludes, excluded)
+	}
+	return hash, excludes, nil
+}
+
+// splitMessage splits a commit message the way git's %s and %b do: the
+// subject is the first paragraph joined into one line, and the body is the
+// rest
+func splitMessage(message string) (subject, body string) {
+	message = strings.TrimLeft(message, "\n")
+	paragraph, body, _ := strings.Cut(message, "\n\n")
+	subject = strings.Join(strings.Split(strings.TrimRight(paragraph, "\n"), "\n"), " ")
+	return subject, strings.Trim(body, "\n")
+}
+
+func (c *NativeClient) ValidateRepository(repoPath string) error {
+	if _, err := c.open(repoPath); err != nil {
+		return fmt.Errorf("not a git repository: %s", repoPath)
+	}
+	return nil
+}
+
+func (c *NativeClient) ListBranches(repoPath string) ([]Branch, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local branches: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	heads, err := r.refs("refs/heads/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local branches: %w", err)
+	}
+	cfg := r.config()
+	head := headRef(r.gitDir)
+	worktrees := r.worktrees(cfg)
+
+	branches := []Branch{}
+	for _, ref := range heads {
+		name := ref.Name().String()
+		b := Branch{
+			Name:         ref.Name().Short(),
+			SHA:          ref.Hash().String(),
+			IsHead:       name == head,
+			WorktreePath: worktrees[name],
+		}
+		if commit, err := r.commit(ref.Hash()); err == nil {
+			b.CommitDate = time.Unix(commit.Committer.When.Unix(), 0)
+			b.Subject, _ = splitMessage(commit.Message)
+			b.Author = commit.Author.Name
+		}
+		if b.Upstream, err = r.upstream(cfg, b.Name, ref.Hash()); err != nil {
+			return nil, fmt.Errorf("failed to list local branches: %w", err)
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// upstream works out a branch's upstream from its branch.<name>.remote and
+// branch.<name>.merge config, and how far the two have diverged
+func (r *nativeRepo) upstream(cfg gitConfig, branch string, tip plumbing.Hash) (Upstream, error) {
+	remote := cfg.get("branch." + branch + ".remote")
+	merge := cfg.get("branch." + branch + ".merge")
+	if remote == "" || merge == "" {
+		return Upstream{}, nil
+	}
+
+	ref := plumbing.ReferenceName(merge)
+	if remote != "." {
+		mapped := false
+		for _, spec := range cfg.getAll("remote." + remote + ".fetch") {
+			refspec := gitconfig.RefSpec(spec)
+			if strings.HasPrefix(spec, "^") || refspec.Validate() != nil || !refspec.Match(ref) {
+				continue
+			}
+			ref, mapped = refspec.Dst(ref), true
+			break
+		}
+		if !mapped {
+			return Upstream{}, nil
+		}
+	}
+
+	upstream := Upstream{Ref: ref.Short()}
+	resolved, err := storer.ResolveReference(r.repo.Storer, ref)
+	if err != nil {
+		upstream.Gone = true
+		return upstream, nil
+	}
+	if upstream.Ahead, err = r.count(tip, []plumbing.Hash{resolved.Hash()}); err != nil {
+		return upstream, err
+	}
+	if upstream.Behind, err = r.count(resolved.Hash(), []plumbing.Hash{tip}); err != nil {
+		return upstream, err
+	}
+	return upstream, nil
+}
+
+func (c *NativeClient) DeleteBranches(repoPath string, branches []Branch) error {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to delete branches: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	refs := make(map[plumbing.ReferenceName]plumbing.Hash)
+	for _, b := range branches {
+		refs[plumbing.NewBranchReferenceName(b.Name)] = plumbing.NewHash(b.SHA)
+	}
+	if err := r.deleteRefs(refs); err != nil {
+		return fmt.Errorf("failed to delete branches: %w", err)
+	}
+
+	// Drop their branch.<name> config like git branch -D does. The branches
+	// are gone either way.
+	r.editConfig(func(cfg *format.Config) bool {
+		changed := false
+		for _, b := range branches {
+			if cfg.Section("branch").HasSubsection(b.Name) {
+				cfg.RemoveSubsection("branch", b.Name)
+				changed = true
+			}
+		}
+		return changed
+	})
+	return nil
+}
+
+func (c *NativeClient) GetPullRefs(repoPath string) (map[string]int, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull refs: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	refs, err := r.refs("refs/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull refs: %w", err)
+	}
+
+	// Build the same listing for-each-ref would, then parse it the same way
+	var lines []string
+	for _, ref := range refs {
+		name := ref.Name().String()
+		isPull := strings.HasPrefix(name, "refs/pull/") && strings.HasSuffix(name, "/head")
+		remote, isRemote := strings.CutPrefix(name, "refs/remotes/")
+		if isPull || (isRemote && strings.Contains(remote, "/pr/")) {
+			lines = append(lines, ref.Hash().String()+" "+name)
+		}
+	}
+	return parsePullRefs(strings.Join(lines, "\n")), nil
+}
+
+func (c *NativeClient) FetchPullRefs(repoPath, remote string) error {
+	return c.fallback.FetchPullRefs(repoPath, remote)
+}
+
+func (c *NativeClient) Fetch(repoPath, remote string, prune bool) error {
+	return c.fallback.Fetch(repoPath, remote, prune)
+}
+
+func (c *NativeClient) FetchBranch(repoPath, remote, branch string) error {
+	return c.fallback.FetchBranch(repoPath, remote, branch)
+}
+
+func (c *NativeClient) GetGitDir(repoPath string) (string, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	return r.commonDir, nil
+}
+
+func (c *NativeClient) GetHooksDir(repoPath string) (string, error) {
+	return c.fallback.GetHooksDir(repoPath)
+}
+
+func (c *NativeClient) GetDefaultBranch(repoPath string) (string, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine default branch: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Prefer the remote's HEAD, which tracks the default branch on GitHub
+	if ref, err := r.repo.Storer.Reference("refs/remotes/origin/HEAD"); err == nil && ref.Type() == plumbing.SymbolicReference {
+		return strings.TrimPrefix(ref.Target().Short(), "origin/"), nil
+	}
+
+	for _, name := range []string{"main", "master"} {
+		if _, err := r.repo.Storer.Reference(plumbing.NewBranchReferenceName(name)); err == nil {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to determine default branch")
+}
+
+func (c *NativeClient) GetCommits(repoPath, rev, exclude string, limit int) ([]Commit, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits for %q: %w", rev, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash, excludes, err := r.resolveAll(rev, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits for %q: %w", rev, err)
+	}
+	walked, err := r.walk(hash, excludes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits for %q: %w", rev, err)
+	}
+
+	var commits []Commit
+	for _, c := range walked {
+		subject, body := splitMessage(c.Message)
+		commits = append(commits, Commit{
+			SHA:         c.Hash.String(),
+			Subject:     subject,
+			Body:        body,
+			Author:      c.Author.Name,
+			AuthorEmail: c.Author.Email,
+			Date:        time.Unix(c.Author.When.Unix(), 0),
+		})
+	}
+	return commits, nil
+}
+
+func (c *NativeClient) CountCommits(repoPath, rev, exclude string) (int, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", rev, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash, excludes, err := r.resolveAll(rev, exclude)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", rev, err)
+	}
+	count, err := r.count(hash, excludes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", rev, err)
+	}
+	return count, nil
+}
+
+func (c *NativeClient) CountUnpushedCommits(repoPath, rev, exclude string) (int, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", rev, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash, excludes, err := r.resolveAll(rev, exclude)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", rev, err)
+	}
+	remotes, err := r.refs("refs/remotes/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", rev, err)
+	}
+	for _, ref := range remotes {
+		excludes = append(excludes, ref.Hash())
+	}
+
+	count, err := r.count(hash, excludes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits for %q: %w", rev, err)
+	}
+	return count, nil
+}
+
+func (c *NativeClient) IsAncestor(repoPath, rev, target string) (bool, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %q is merged into %q: %w", rev, target, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash, targets, err := r.resolveAll(rev, target)
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %q is merged into %q: %w", rev, target, err)
+	}
+	// Every branch is checked against the same target, whose history is
+	// walked once and cached
+	reachable, err := r.reachableFrom(targets)
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %q is merged into %q: %w", rev, target, err)
+	}
+	return reachable[hash], nil
+}
+
+func (c *NativeClient) MergeBase(repoPath, a, b string) (string, error) {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the merge base of %q and %q: %w", a, b, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash, others, err := r.resolveAll(a, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the merge base of %q and %q: %w", a, b, err)
+	}
+	first, err := r.commit(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the merge base of %q and %q: %w", a, b, err)
+	}
+	second, err := r.commit(others[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to find the merge base of %q and %q: %w", a, b, err)
+	}
+	bases, err := first.MergeBase(second)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the merge base of %q and %q: %w", a, b, err)
+	}
+	if len(bases) == 0 {
+		return "", nil
+	}
+	return bases[0].Hash.String(), nil
+}
+
+func (c *NativeClient) CountUnappliedCommits(repoPath, target, rev string) (int, error) {
+	return c.fallback.CountUnappliedCommits(repoPath, target, rev)
+}
+
+func (c *NativeClient) RebaseOnto(repoPath, branch, onto, upstream string) error {
+	return c.fallback.RebaseOnto(repoPath, branch, onto, upstream)
+}
+
+func (c *NativeClient) FastForward(repoPath, branch, target string) error {
+	return c.fallback.FastForward(repoPath, branch, target)
+}
+
+func (c *NativeClient) Checkout(repoPath, branch string) error {
+	return c.fallback.Checkout(repoPath, branch)
+}
+
+func (c *NativeClient) GetConfig(repoPath, key string) (string, error) {
+	// Like git config, fall back to the global config outside a repository
+	r, err := c.open(repoPath)
+	if err != nil {
+		return globalConfig().get(key), nil
+	}
+	return r.config().get(key), nil
+}
+
+func (c *NativeClient) SetConfig(repoPath, key, value string) error {
+	section, subsection, name, ok := splitKey(key)
+	if !ok {
+		return fmt.Errorf("failed to set git config %q: invalid key", key)
+	}
+	r, err := c.open(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to set git config %q: %w", key, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err = r.editConfig(func(cfg *format.Config) bool {
+		cfg.SetOption(section, subsection, name, value)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set git config %q: %w", key, err)
+	}
+	return nil
+}
+
+func (c *NativeClient) UnsetConfig(repoPath, key string) error {
+	section, subsection, name, ok := splitKey(key)
+	if !ok {
+		return fmt.Errorf("failed to unset git config %q: invalid key", key)
+	}
+	r, err := c.open(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to unset git config %q: %w", key, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err = r.editConfig(func(cfg *format.Config) bool {
+		changed := false
+		for _, s := range cfg.Sections {
+			if !s.IsName(section) {
+				continue
+			}
+			if subsection == "" {
+				changed = changed || s.HasOption(name)
+				s.RemoveOption(name)
+				continue
+			}
+			for _, sub := range s.Subsections {
+				if sub.IsName(subsection) {
+					changed = changed || sub.HasOption(name)
+					sub.RemoveOption(name)
+				}
+			}
+		}
+		return changed
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unset git config %q: %w", key, err)
+	}
+	return nil
+}
+
+func (c *NativeClient) CreateRef(repoPath, ref, sha string) error {
+	r, err := c.open(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to create ref %q: %w", ref, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.createRef(plumbing.ReferenceName(ref), plumbing.NewHash(sha)); err != nil {
+		return fmt.Errorf("failed to create ref %q: %w", ref, err)
+	}
+	return nil
+}
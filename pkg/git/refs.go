@@ -0,0 +1,190 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// lockFile takes git's lock for path by creating path.lock, which every git
+// command respects. It fails if another process holds the lock.
+func lockFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("unable to create '%s.lock': file exists", path)
+	}
+	return f, err
+}
+
+// commitLock writes data to a lock taken with lockFile and renames it over
+// the locked file
+func commitLock(f *os.File, data []byte) error {
+	lock := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(lock)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(lock)
+		return err
+	}
+	return os.Rename(lock, strings.TrimSuffix(lock, ".lock"))
+}
+
+// rollbackLock releases a lock taken with lockFile without changing the
+// locked file
+func rollbackLock(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// deleteRefs deletes refs the way a git update-ref --stdin transaction does.
+// Every ref is locked and checked against its expected hash before any is
+// touched, so either all of them are deleted or none are. Packed copies are
+// dropped from packed-refs, and loose refs are removed along with their
+// reflogs.
+func (r *nativeRepo) deleteRefs(refs map[plumbing.ReferenceName]plumbing.Hash) error {
+	names := make([]plumbing.ReferenceName, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	var locks []*os.File
+	defer func() {
+		for _, lock := range locks {
+			rollbackLock(lock)
+		}
+		for _, name := range names {
+			r.pruneRefDirs(name)
+		}
+	}()
+	for _, name := range names {
+		lock, err := lockFile(r.refPath(name))
+		if err != nil {
+			return fmt.Errorf("cannot lock ref '%s': %w", name, err)
+		}
+		locks = append(locks, lock)
+	}
+
+	// Only verify once every lock is held, so nothing moves in between
+	for _, name := range names {
+		ref, err := r.repo.Storer.Reference(name)
+		if err != nil {
+			return fmt.Errorf("cannot lock ref '%s': %w", name, err)
+		}
+		if ref.Type() != plumbing.HashReference || ref.Hash() != refs[name] {
+			return fmt.Errorf("cannot lock ref '%s': is at %s but expected %s", name, ref.Hash(), refs[name])
+		}
+	}
+
+	if err := r.removePackedRefs(refs); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := os.Remove(r.refPath(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove ref '%s': %w", name, err)
+		}
+		os.Remove(filepath.Join(r.commonDir, "logs", filepath.FromSlash(name.String())))
+	}
+	return nil
+}
+
+// createRef creates a loose ref pointing at hash, failing if the ref already
+// exists
+func (r *nativeRepo) createRef(name plumbing.ReferenceName, hash plumbing.Hash) error {
+	path := r.refPath(name)
+	lock, err := lockFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot lock ref '%s': %w", name, err)
+	}
+	if _, err := r.repo.Storer.Reference(name); !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		rollbackLock(lock)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("cannot lock ref '%s': reference already exists", name)
+	}
+	if _, err := r.commit(hash); err != nil {
+		rollbackLock(lock)
+		return fmt.Errorf("%s: not a valid commit: %w", hash, err)
+	}
+	return commitLock(lock, []byte(hash.String()+"\n"))
+}
+
+// refPath returns where a loose ref lives in the common git directory
+func (r *nativeRepo) refPath(name plumbing.ReferenceName) string {
+	return filepath.Join(r.commonDir, filepath.FromSlash(name.String()))
+}
+
+// removePackedRefs rewrites packed-refs without refs and their peeled lines,
+// leaving the file alone when it holds none of them
+func (r *nativeRepo) removePackedRefs(refs map[plumbing.ReferenceName]plumbing.Hash) error {
+	path := filepath.Join(r.commonDir, "packed-refs")
+	lock, err := lockFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to lock packed-refs: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		rollbackLock(lock)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read packed-refs: %w", err)
+	}
+
+	var kept []string
+	removed, dropping := false, false
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		// A peeled line belongs to the ref on the line before it
+		if strings.HasPrefix(line, "^") {
+			if !dropping {
+				kept = append(kept, line)
+			}
+			continue
+		}
+		_, name, _ := strings.Cut(strings.TrimSuffix(line, "\n"), " ")
+		if _, dropping = refs[plumbing.ReferenceName(name)]; dropping {
+			removed = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if !removed {
+		rollbackLock(lock)
+		return nil
+	}
+	if err := commitLock(lock, []byte(strings.Join(kept, ""))); err != nil {
+		return fmt.Errorf("failed to write packed-refs: %w", err)
+	}
+	return nil
+}
+
+// pruneRefDirs removes the directories a deleted ref leaves empty, like
+// refs/heads/feature after refs/heads/feature/login is gone. The top level
+// directory, such as refs/heads, stays.
+func (r *nativeRepo) pruneRefDirs(name plumbing.ReferenceName) {
+	parts := strings.SplitN(name.String(), "/", 3)
+	if len(parts) < 3 {
+		return
+	}
+	for _, root := range []string{r.commonDir, filepath.Join(r.commonDir, "logs")} {
+		stop := filepath.Join(root, parts[0], parts[1])
+		for dir := filepath.Dir(filepath.Join(root, filepath.FromSlash(name.String()))); len(dir) > len(stop); dir = filepath.Dir(dir) {
+			// Remove fails on directories that aren't empty
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+}
@@ -4,16 +4,117 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strings"
+	"time"
 )
 
+// prFields is the list of PR fields requested from gh
+const prFields = "number,state,title,isDraft,closedAt,mergeCommit,baseRefName,autoMergeRequest,labels"
+
+// statusFields adds the review and CI state of open PRs, which only status
+// lookups need
+const statusFields = prFields + ",reviewDecision,statusCheckRollup,mergeable,updatedAt"
+
 // PRInfo represents information about a pull request
 type PRInfo struct {
-	Number  int    `json:"number"`
-	State   string `json:"state"`
-	Title   string `json:"title"`
-	IsDraft bool   `json:"isDraft"`
+	Number   int       `json:"number"`
+	State    string    `json:"state"`
+	Title    string    `json:"title"`
+	IsDraft  bool      `json:"isDraft"`
+	ClosedAt time.Time `json:"closedAt"`
+	// MergeCommit is the commit the PR landed as, nil unless it was merged
+	MergeCommit *Commit `json:"mergeCommit,omitempty"`
+	// BaseRefName is the branch the PR targets, or was merged into
+	BaseRefName string `json:"baseRefName,omitempty"`
+	// AutoMergeRequest is set when auto-merge is enabled on an open PR
+	AutoMergeRequest *AutoMergeRequest `json:"autoMergeRequest,omitempty"`
+	// InMergeQueue is set when an open PR is waiting in a merge queue. gh
+	// pr list doesn't report it, Client.InMergeQueue does.
+	InMergeQueue bool `json:"isInMergeQueue,omitempty"`
+	// ReviewDecision is APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED or empty
+	ReviewDecision string `json:"reviewDecision,omitempty"`
+	// Checks are the PR head's check runs and commit statuses
+	Checks []Check `json:"statusCheckRollup,omitempty"`
+	// Mergeable is MERGEABLE, CONFLICTING or UNKNOWN
+	Mergeable string    `json:"mergeable,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Labels    []Label   `json:"labels,omitempty"`
+}
+
+// Label is a label on a PR
+type Label struct {
+	Name string `json:"name"`
+}
+
+// HasLabel reports whether the PR carries the label, ignoring case like
+// GitHub does
+func (pr *PRInfo) HasLabel(name string) bool {
+	for _, l := range pr.Labels {
+		if strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check is a check run or a commit status. Check runs have a Status and,
+// once completed, a Conclusion; commit statuses only have a State.
+type Check struct {
+	Status     string `json:"status,omitempty"`
+	Conclusion string `json:"conclusion,omitempty"`
+	State      string `json:"state,omitempty"`
+}
+
+// AutoMergeRequest describes the auto-merge enabled on a PR
+type AutoMergeRequest struct {
+	EnabledAt   time.Time `json:"enabledAt"`
+	MergeMethod string    `json:"mergeMethod"`
+}
+
+// Combined check states returned by CheckState
+const (
+	ChecksPassing = "passing"
+	ChecksPending = "pending"
+	ChecksFailing = "failing"
+)
+
+// CheckState combines a PR's checks like GitHub's status rollup: failing if
+// any check failed, pending if any hasn't finished, passing otherwise. It
+// returns "" when the PR has no checks.
+func (pr *PRInfo) CheckState() string {
+	if len(pr.Checks) == 0 {
+		return ""
+	}
+	state := ChecksPassing
+	for _, c := range pr.Checks {
+		switch {
+		case c.State == "FAILURE" || c.State == "ERROR":
+			return ChecksFailing
+		case c.State == "PENDING" || c.State == "EXPECTED":
+			state = ChecksPending
+		case c.State != "":
+		case c.Status != "" && c.Status != "COMPLETED":
+			state = ChecksPending
+		case c.Conclusion == "FAILURE" || c.Conclusion == "TIMED_OUT" || c.Conclusion == "CANCELLED" ||
+			c.Conclusion == "ACTION_REQUIRED" || c.Conclusion == "STARTUP_FAILURE":
+			return ChecksFailing
+		}
+	}
+	return state
+}
+
+// Queued reports whether an open PR will be merged without further action,
+// because it's in a merge queue or has auto-merge enabled
+func (pr *PRInfo) Queued() bool {
+	return pr.State == "OPEN" && (pr.InMergeQueue || pr.AutoMergeRequest != nil)
+}
+
+// Commit identifies a commit on GitHub
+type Commit struct {
+	OID string `json:"oid"`
 }
 
 // Client provides an interface for GitHub operations
@@ -23,8 +124,17 @@ type Client interface {
 	GetMergedPR(repoPath, branch string) (*PRInfo, error)
 
 	// GetPRStatus returns PR info for any PR associated with the branch
-	// Returns nil if no PR is found, otherwise returns the most recent PR
+	// Returns nil if no PR is found, otherwise returns the most recent PR.
+	// Open PRs include their auto-merge, review and check state, but not
+	// whether they are in a merge queue.
 	GetPRStatus(repoPath, branch string) (*PRInfo, error)
+
+	// InMergeQueue reports which of the PRs with the given numbers are
+	// waiting in a merge queue, asking GitHub once for all of them
+	InMergeQueue(repoPath string, numbers []int) (map[int]bool, error)
+	// GetLabels returns the current labels of the PRs with the given
+	// numbers, asking GitHub once for all of them
+	GetLabels(repoPath string, numbers []int) (map[int][]Label, error)
 }
 
 // DefaultClient implements Client using gh CLI
@@ -36,46 +146,153 @@ func NewDefaultClient() *DefaultClient {
 }
 
 func (c *DefaultClient) GetMergedPR(repoPath, branch string) (*PRInfo, error) {
-	cmd := exec.Command("gh", "pr", "list",
-		"--state", "merged",
-		"--head", branch,
-		"--json", "number,state,title,isDraft",
-		"--limit", "1")
+	pr, err := c.listPRs(repoPath, branch, "merged", prFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check PR for branch %q: %w", branch, err)
+	}
+	return pr, nil
+}
+
+func (c *DefaultClient) GetPRStatus(repoPath, branch string) (*PRInfo, error) {
+	pr, err := c.listPRs(repoPath, branch, "all", statusFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check PR status for branch %q: %w", branch, err)
+	}
+	return pr, nil
+}
+
+// pullRequestBatch bounds how many PRs one GraphQL query asks about
+const pullRequestBatch = 100
+
+func (c *DefaultClient) InMergeQueue(repoPath string, numbers []int) (map[int]bool, error) {
+	queued := make(map[int]bool, len(numbers))
+	for start := 0; start < len(numbers); start += pullRequestBatch {
+		batch := numbers[start:min(start+pullRequestBatch, len(numbers))]
+		if err := c.inMergeQueue(repoPath, batch, queued); err != nil {
+			return nil, fmt.Errorf("failed to check merge queues: %w", err)
+		}
+	}
+	return queued, nil
+}
+
+// GetLabels returns the current labels of the PRs with the given numbers,
+// asking GitHub once for all of them
+func (c *DefaultClient) GetLabels(repoPath string, numbers []int) (map[int][]Label, error) {
+	labels := make(map[int][]Label, len(numbers))
+	for start := 0; start < len(numbers); start += pullRequestBatch {
+		batch := numbers[start:min(start+pullRequestBatch, len(numbers))]
+		if err := c.getLabels(repoPath, batch, labels); err != nil {
+			return nil, fmt.Errorf("failed to get labels: %w", err)
+		}
+	}
+	return labels, nil
+}
+
+// mergeQueueQuery asks whether each of the PRs is in its base branch's
+// merge queue. gh pr list can't report it.
+func mergeQueueQuery(numbers []int) string {
+	return pullRequestsQuery(numbers, "isInMergeQueue")
+}
+
+// labelsQuery asks for the labels of each of the PRs
+func labelsQuery(numbers []int) string {
+	return pullRequestsQuery(numbers, "labels(first: 100) { nodes { name } }")
+}
+
+// pullRequestsQuery selects fields on each of the PRs, aliased prN so the
+// answers can be told apart
+func pullRequestsQuery(numbers []int, fields string) string {
+	var b strings.Builder
+	b.WriteString("query($owner: String!, $repo: String!) {\n  repository(owner: $owner, name: $repo) {\n")
+	for _, n := range numbers {
+		fmt.Fprintf(&b, "    pr%d: pullRequest(number: %d) { %s }\n", n, n, fields)
+	}
+	b.WriteString("  }\n}")
+	return b.String()
+}
+
+// queryPullRequests runs a query built by pullRequestsQuery and returns the
+// repository object of the answer
+func (c *DefaultClient) queryPullRequests(repoPath, query string) ([]byte, error) {
+	cmd := exec.Command("gh", "api", "graphql",
+		"-F", "owner={owner}",
+		"-F", "repo={repo}",
+		"-f", "query="+query,
+		"--jq", ".data.repository")
 	cmd.Dir = repoPath
 
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to check PR for branch %q: %w", branch, err)
+		return nil, c.wrapError(repoPath, err)
 	}
+	return output, nil
+}
 
-	var prs []PRInfo
+// inMergeQueue records in queued whether each PR in numbers is waiting in a
+// merge queue
+func (c *DefaultClient) inMergeQueue(repoPath string, numbers []int, queued map[int]bool) error {
+	output, err := c.queryPullRequests(repoPath, mergeQueueQuery(numbers))
+	if err != nil {
+		return err
+	}
+
+	var prs map[string]*struct {
+		IsInMergeQueue bool `json:"isInMergeQueue"`
+	}
 	if err := json.Unmarshal(output, &prs); err != nil {
-		return nil, fmt.Errorf("failed to parse PR data for branch %q: %w", branch, err)
+		return fmt.Errorf("failed to parse merge queue data: %w", err)
 	}
+	for _, n := range numbers {
+		// A PR that doesn't exist comes back as null
+		if pr := prs[fmt.Sprintf("pr%d", n)]; pr != nil {
+			queued[n] = pr.IsInMergeQueue
+		}
+	}
+	return nil
+}
 
-	if len(prs) == 0 {
-		return nil, nil
+// getLabels records in labels the labels of each PR in numbers
+func (c *DefaultClient) getLabels(repoPath string, numbers []int, labels map[int][]Label) error {
+	output, err := c.queryPullRequests(repoPath, labelsQuery(numbers))
+	if err != nil {
+		return err
 	}
 
-	return &prs[0], nil
+	var prs map[string]*struct {
+		Labels struct {
+			Nodes []Label `json:"nodes"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return fmt.Errorf("failed to parse label data: %w", err)
+	}
+	for _, n := range numbers {
+		// A PR that doesn't exist comes back as null
+		if pr := prs[fmt.Sprintf("pr%d", n)]; pr != nil {
+			labels[n] = pr.Labels.Nodes
+		}
+	}
+	return nil
 }
 
-func (c *DefaultClient) GetPRStatus(repoPath, branch string) (*PRInfo, error) {
+// listPRs returns the most recent PR in the given state whose head is branch,
+// with the requested fields
+func (c *DefaultClient) listPRs(repoPath, branch, state, fields string) (*PRInfo, error) {
 	cmd := exec.Command("gh", "pr", "list",
-		"--state", "all",
+		"--state", state,
 		"--head", branch,
-		"--json", "number,state,title,isDraft",
+		"--json", fields,
 		"--limit", "1")
 	cmd.Dir = repoPath
 
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to check PR status for branch %q: %w", branch, err)
+		return nil, c.wrapError(repoPath, err)
 	}
 
 	var prs []PRInfo
 	if err := json.Unmarshal(output, &prs); err != nil {
-		return nil, fmt.Errorf("failed to parse PR data for branch %q: %w", branch, err)
+		return nil, fmt.Errorf("failed to parse PR data: %w", err)
 	}
 
 	if len(prs) == 0 {
@@ -85,3 +302,46 @@ func (c *DefaultClient) GetPRStatus(repoPath, branch string) (*PRInfo, error) {
 	return &prs[0], nil
 }
 
+// wrapError classifies a failed gh invocation. When GitHub reports a primary
+// rate limit without a reset hint, the reset time is looked up via
+// `gh api rate_limit` so callers can wait instead of failing.
+func (c *DefaultClient) wrapError(repoPath string, err error) error {
+	var stderr string
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		stderr = string(exitErr.Stderr)
+	}
+
+	classified := classifyError(stderr, err)
+
+	var rle *RateLimitError
+	if errors.As(classified, &rle) && !rle.Secondary && rle.Reset.IsZero() && rle.RetryAfter == 0 {
+		if reset, ok := c.rateLimitReset(repoPath); ok {
+			rle.Reset = reset
+		}
+	}
+
+	return classified
+}
+
+// rateLimitReset queries the GraphQL rate limit used by `gh pr list` and
+// returns its reset time if the quota is exhausted
+func (c *DefaultClient) rateLimitReset(repoPath string) (time.Time, bool) {
+	cmd := exec.Command("gh", "api", "rate_limit", "--jq", ".resources.graphql")
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var limit struct {
+		Remaining int   `json:"remaining"`
+		Reset     int64 `json:"reset"`
+	}
+	if err := json.Unmarshal(output, &limit); err != nil || limit.Remaining > 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(limit.Reset, 0), true
+}
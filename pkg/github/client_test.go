@@ -29,3 +29,70 @@ func TestGetPRStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestPRInfo_CheckState(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   string
+	}{
+		{name: "no checks", want: ""},
+		{
+			name:   "all passing",
+			checks: []Check{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {Status: "COMPLETED", Conclusion: "SKIPPED"}, {State: "SUCCESS"}},
+			want:   ChecksPassing,
+		},
+		{
+			name:   "check run in progress",
+			checks: []Check{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {Status: "IN_PROGRESS"}},
+			want:   ChecksPending,
+		},
+		{
+			name:   "commit status pending",
+			checks: []Check{{State: "PENDING"}},
+			want:   ChecksPending,
+		},
+		{
+			name:   "failure beats pending",
+			checks: []Check{{Status: "QUEUED"}, {Status: "COMPLETED", Conclusion: "TIMED_OUT"}},
+			want:   ChecksFailing,
+		},
+		{
+			name:   "commit status error",
+			checks: []Check{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {State: "ERROR"}},
+			want:   ChecksFailing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &PRInfo{Checks: tt.checks}
+			if got := pr.CheckState(); got != tt.want {
+				t.Errorf("CheckState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeQueueQuery(t *testing.T) {
+	want := `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    pr7: pullRequest(number: 7) { isInMergeQueue }
+    pr12: pullRequest(number: 12) { isInMergeQueue }
+  }
+}`
+	if got := mergeQueueQuery([]int{7, 12}); got != want {
+		t.Errorf("mergeQueueQuery() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLabelsQuery(t *testing.T) {
+	want := `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    pr3: pullRequest(number: 3) { labels(first: 100) { nodes { name } } }
+  }
+}`
+	if got := labelsQuery([]int{3}); got != want {
+		t.Errorf("labelsQuery() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitError indicates that GitHub rejected a request because a primary
+// or secondary rate limit was hit
+type RateLimitError struct {
+	// Secondary is true for abuse/secondary rate limits
+	Secondary bool
+	// Reset is when the primary rate limit window resets (zero if unknown)
+	Reset time.Time
+	// RetryAfter is the server-requested delay (zero if unknown)
+	RetryAfter time.Duration
+	// Message is the raw error text reported by gh
+	Message string
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "primary"
+	if e.Secondary {
+		kind = "secondary"
+	}
+	return fmt.Sprintf("GitHub %s rate limit exceeded: %s", kind, e.Message)
+}
+
+// Wait returns how long the caller should wait before retrying, relative to now.
+// It returns zero when GitHub gave no hint.
+func (e *RateLimitError) Wait(now time.Time) time.Duration {
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	if !e.Reset.IsZero() && e.Reset.After(now) {
+		return e.Reset.Sub(now)
+	}
+	return 0
+}
+
+// TransientError wraps a failure that is likely to succeed when retried,
+// such as a network timeout or a 5xx response
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("transient GitHub error: %v", e.Err)
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err is a rate limit or transient failure
+func IsRetryable(err error) bool {
+	var rle *RateLimitError
+	var te *TransientError
+	return errors.As(err, &rle) || errors.As(err, &te)
+}
+
+var (
+	retryAfterPattern = regexp.MustCompile(`(?i)retry-after:\s*(\d+)`)
+	resetPattern      = regexp.MustCompile(`(?i)x-ratelimit-reset:\s*(\d+)`)
+	remainingPattern  = regexp.MustCompile(`(?i)x-ratelimit-remaining:\s*(\d+)`)
+)
+
+// transientMarkers are substrings of gh error output that indicate a failure
+// worth retrying
+var transientMarkers = []string{
+	"timeout",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"unexpected eof",
+	"tls handshake",
+	"502",
+	"503",
+	"504",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	"something went wrong",
+}
+
+// classifyError inspects the stderr of a failed gh invocation and wraps err in
+// a RateLimitError or TransientError when appropriate
+func classifyError(stderr string, err error) error {
+	lower := strings.ToLower(stderr)
+	message := strings.TrimSpace(stderr)
+
+	if strings.Contains(lower, "rate limit") || isExhausted(stderr) {
+		rle := &RateLimitError{
+			Secondary: strings.Contains(lower, "secondary") || strings.Contains(lower, "abuse"),
+			Message:   firstLine(message),
+		}
+		rle.RetryAfter, rle.Reset = parseRateLimitHeaders(stderr)
+		return rle
+	}
+
+	for _, marker := range transientMarkers {
+		if strings.Contains(lower, marker) {
+			return &TransientError{Err: fmt.Errorf("%s: %w", firstLine(message), err)}
+		}
+	}
+
+	if message != "" {
+		return fmt.Errorf("%s: %w", firstLine(message), err)
+	}
+	return err
+}
+
+// parseRateLimitHeaders extracts Retry-After and X-RateLimit-Reset values from
+// gh debug output, if present
+func parseRateLimitHeaders(text string) (retryAfter time.Duration, reset time.Time) {
+	if m := retryAfterPattern.FindStringSubmatch(text); m != nil {
+		if secs, err := strconv.Atoi(m[1]); err == nil {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	if m := resetPattern.FindStringSubmatch(text); m != nil {
+		if epoch, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			reset = time.Unix(epoch, 0)
+		}
+	}
+	return retryAfter, reset
+}
+
+// isExhausted reports whether debug output shows zero remaining requests
+func isExhausted(text string) bool {
+	m := remainingPattern.FindStringSubmatch(text)
+	return m != nil && m[1] == "0"
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	base := errors.New("exit status 1")
+
+	tests := []struct {
+		name          string
+		stderr        string
+		wantRateLimit bool
+		wantSecondary bool
+		wantTransient bool
+		wantRetryable bool
+	}{
+		{
+			name:          "primary rate limit",
+			stderr:        "GraphQL: API rate limit exceeded for user ID 1234.",
+			wantRateLimit: true,
+			wantRetryable: true,
+		},
+		{
+			name:          "secondary rate limit",
+			stderr:        "HTTP 403: You have exceeded a secondary rate limit.",
+			wantRateLimit: true,
+			wantSecondary: true,
+			wantRetryable: true,
+		},
+		{
+			name:          "network timeout",
+			stderr:        "Post \"https://api.github.com/graphql\": net/http: TLS handshake timeout",
+			wantTransient: true,
+			wantRetryable: true,
+		},
+		{
+			name:          "server error",
+			stderr:        "HTTP 502: Bad Gateway",
+			wantTransient: true,
+			wantRetryable: true,
+		},
+		{
+			name:   "authentication failure",
+			stderr: "To get started with GitHub CLI, please run:  gh auth login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.stderr, base)
+
+			var rle *RateLimitError
+			if got := errors.As(err, &rle); got != tt.wantRateLimit {
+				t.Errorf("classifyError() rate limit = %v, want %v", got, tt.wantRateLimit)
+			}
+			if rle != nil && rle.Secondary != tt.wantSecondary {
+				t.Errorf("classifyError() secondary = %v, want %v", rle.Secondary, tt.wantSecondary)
+			}
+
+			var te *TransientError
+			if got := errors.As(err, &te); got != tt.wantTransient {
+				t.Errorf("classifyError() transient = %v, want %v", got, tt.wantTransient)
+			}
+
+			if got := IsRetryable(err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestClassifyError_ParsesHeaders(t *testing.T) {
+	stderr := "< HTTP/2.0 403 Forbidden\n< Retry-After: 60\n< X-Ratelimit-Remaining: 0\n< X-Ratelimit-Reset: 1700000000\n"
+
+	err := classifyError(stderr, errors.New("exit status 1"))
+
+	var rle *RateLimitError
+	if !errors.As(err, &rle) {
+		t.Fatalf("classifyError() = %v, want RateLimitError", err)
+	}
+	if rle.RetryAfter != time.Minute {
+		t.Errorf("RetryAfter = %v, want 1m", rle.RetryAfter)
+	}
+	if !rle.Reset.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Reset = %v, want %v", rle.Reset, time.Unix(1700000000, 0))
+	}
+	if got := rle.Wait(time.Unix(1699999000, 0)); got != time.Minute {
+		t.Errorf("Wait() = %v, want Retry-After to take precedence", got)
+	}
+}
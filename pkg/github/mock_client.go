@@ -39,6 +39,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// GetLabels mocks base method.
+func (m *MockClient) GetLabels(repoPath string, numbers []int) (map[int][]Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLabels", repoPath, numbers)
+	ret0, _ := ret[0].(map[int][]Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLabels indicates an expected call of GetLabels.
+func (mr *MockClientMockRecorder) GetLabels(repoPath, numbers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLabels", reflect.TypeOf((*MockClient)(nil).GetLabels), repoPath, numbers)
+}
+
 // GetMergedPR mocks base method.
 func (m *MockClient) GetMergedPR(repoPath, branch string) (*PRInfo, error) {
 	m.ctrl.T.Helper()
@@ -68,3 +83,18 @@ func (mr *MockClientMockRecorder) GetPRStatus(repoPath, branch any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPRStatus", reflect.TypeOf((*MockClient)(nil).GetPRStatus), repoPath, branch)
 }
+
+// InMergeQueue mocks base method.
+func (m *MockClient) InMergeQueue(repoPath string, numbers []int) (map[int]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InMergeQueue", repoPath, numbers)
+	ret0, _ := ret[0].(map[int]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InMergeQueue indicates an expected call of InMergeQueue.
+func (mr *MockClientMockRecorder) InMergeQueue(repoPath, numbers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InMergeQueue", reflect.TypeOf((*MockClient)(nil).InMergeQueue), repoPath, numbers)
+}
@@ -0,0 +1,346 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Git hooks that axe can run from
+const (
+	// EventPostMerge runs after git merge and git pull
+	EventPostMerge = "post-merge"
+	// EventPostCheckout runs after a branch is checked out, including by
+	// git pull --rebase
+	EventPostCheckout = "post-checkout"
+	// EventReferenceTransaction runs whenever refs change. Axe only runs
+	// when remote-tracking branches changed, as after a fetch or pull.
+	EventReferenceTransaction = "reference-transaction"
+)
+
+// Events lists every supported hook
+var Events = []string{EventPostMerge, EventPostCheckout, EventReferenceTransaction}
+
+// Policies for what the hook runs
+const (
+	// PolicyReport lists the branches ready to chop
+	PolicyReport = "report"
+	// PolicyChop chops them without asking
+	PolicyChop = "chop"
+)
+
+// Policies lists every supported policy
+var Policies = []string{PolicyReport, PolicyChop}
+
+// Hook managers that own a repository's hooks, so axe leaves them alone
+const (
+	ManagerHusky    = "husky"
+	ManagerLefthook = "lefthook"
+)
+
+// lefthookConfigs are the files lefthook reads its config from
+var lefthookConfigs = []string{
+	"lefthook.yml", ".lefthook.yml", "lefthook.yaml", ".lefthook.yaml",
+	"lefthook.toml", ".lefthook.toml", "lefthook.json", ".lefthook.json",
+}
+
+// The axe block in a hook script sits between these lines
+const (
+	beginMarker = "# >>> axe hook >>>"
+	endMarker   = "# <<< axe hook <<<"
+)
+
+// ValidateEvent checks that axe can run from the hook
+func ValidateEvent(event string) error {
+	if slices.Contains(Events, event) {
+		return nil
+	}
+	return fmt.Errorf("invalid hook %q (valid hooks: %s)", event, strings.Join(Events, ", "))
+}
+
+// Command returns the axe command a hook runs for policy
+func Command(policy string) (string, error) {
+	switch policy {
+	case PolicyReport:
+		return "axe branches --quiet", nil
+	case PolicyChop:
+		return "axe chop --force --no-color", nil
+	}
+	return "", fmt.Errorf("invalid policy %q (valid policies: %s)", policy, strings.Join(Policies, ", "))
+}
+
+// Detect tells whether a hook manager owns the hooks of the repository whose
+// worktree is root and whose hooks run from hooksDir, returning "" if none
+// does
+func Detect(root, hooksDir string) string {
+	// husky 9 sets core.hooksPath to .husky/_, older versions to .husky
+	if filepath.Base(hooksDir) == ".husky" || filepath.Base(filepath.Dir(hooksDir)) == ".husky" {
+		return ManagerHusky
+	}
+	if info, err := os.Stat(filepath.Join(root, ".husky")); err == nil && info.IsDir() {
+		return ManagerHusky
+	}
+	for _, name := range lefthookConfigs {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return ManagerLefthook
+		}
+	}
+	return ""
+}
+
+// guard returns the shell test that decides whether axe runs for event, or
+// "" if it always does. arg formats the hook's nth argument, and input is a
+// command printing the hook's stdin, or "" to read stdin itself.
+func guard(event string, arg func(int) string, input string) string {
+	switch event {
+	case EventPostCheckout:
+		// Branch checkouts only, not checkouts of single files
+		return fmt.Sprintf(`[ "%s" = 1 ]`, arg(3))
+	case EventReferenceTransaction:
+		// Committed transactions that moved a remote-tracking branch only
+		refs := `grep -q " refs/remotes/"`
+		if input != "" {
+			refs = input + " | " + refs
+		}
+		return fmt.Sprintf(`[ "%s" = committed ] && %s`, arg(1), refs)
+	}
+	return ""
+}
+
+// run returns the line that runs command. AXE_HOOK stops the refs axe
+// changes from running the hook again, and a failure never fails git.
+func run(command string) string {
+	return fmt.Sprintf("AXE_HOOK=1 %s || true", command)
+}
+
+// script returns the shell lines that run command from the event hook. A
+// hook whose guard reads stdin keeps a copy and hands it on, so the lines
+// after it in the same script still see it.
+func script(event, command string) string {
+	arg := func(n int) string { return fmt.Sprintf("$%d", n) }
+	cond := `[ -z "$AXE_HOOK" ] && command -v axe >/dev/null 2>&1`
+	if event != EventReferenceTransaction {
+		if g := guard(event, arg, ""); g != "" {
+			cond += " && " + g
+		}
+		return fmt.Sprintf("if %s; then\n\t%s\nfi\n", cond, run(command))
+	}
+	cond += " && " + guard(event, arg, `printf '%s\n' "$axe_stdin"`)
+	return fmt.Sprintf("axe_stdin=$(cat)\nif %s; then\n\t%s\nfi\n[ -z \"$axe_stdin\" ] || exec <<axe_stdin\n$axe_stdin\naxe_stdin\n", cond, run(command))
+}
+
+// block returns the marked block that Install adds to a hook script
+func block(event, command string) string {
+	return fmt.Sprintf("%s\n# Added by axe hook install, removed by axe hook uninstall\n%s%s\n", beginMarker, script(event, command), endMarker)
+}
+
+// Snippet returns the file that hooks are configured in for manager and what
+// to add to it so the event hook runs command
+func Snippet(manager, event, command string) (file, snippet string) {
+	switch manager {
+	case ManagerHusky:
+		return filepath.Join(".husky", event), script(event, command)
+	case ManagerLefthook:
+		cmd := run(command)
+		if g := guard(event, func(n int) string { return fmt.Sprintf("{%d}", n) }, ""); g != "" {
+			cmd = g + " && " + cmd
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s:\n  commands:\n    axe:\n", event)
+		if event == EventReferenceTransaction {
+			b.WriteString("      use_stdin: true\n")
+		}
+		fmt.Fprintf(&b, "      run: '%s'\n", cmd)
+		return "lefthook.yml", b.String()
+	}
+	return "", ""
+}
+
+// Install adds the axe block for event to the hook script in dir, creating
+// the script if needed and keeping whatever it already runs. The block goes
+// right after the shebang, so it runs even if the script ends with exit or
+// exec. A block that was installed before is replaced, so installing again
+// changes nothing. It reports whether the script changed.
+func Install(dir, event, command string) (bool, error) {
+	if err := ValidateEvent(event); err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, event)
+	existing, mode, err := readScript(path)
+	if err != nil {
+		return false, err
+	}
+	if existing != "" && !isShellScript(existing) {
+		return false, fmt.Errorf("failed to install %s hook: %s isn't a shell script, add this to it yourself:\n%s", event, path, script(event, command))
+	}
+
+	content, _ := withoutBlock(existing)
+	content = withBlock(content, block(event, command))
+	if content == existing && mode&0o111 == 0o111 {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, fmt.Errorf("failed to install %s hook: %w", event, err)
+	}
+	if err := writeScript(path, content, mode|0o111); err != nil {
+		return false, fmt.Errorf("failed to install %s hook: %w", event, err)
+	}
+	return true, nil
+}
+
+// Uninstall removes the axe block from the event hook script in dir. A script
+// left with nothing to run is deleted. It reports whether the script changed.
+func Uninstall(dir, event string) (bool, error) {
+	if err := ValidateEvent(event); err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, event)
+	existing, mode, err := readScript(path)
+	if err != nil {
+		return false, err
+	}
+	content, ok := withoutBlock(existing)
+	if !ok {
+		return false, nil
+	}
+	if isEmptyScript(content) {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to uninstall %s hook: %w", event, err)
+		}
+		return true, nil
+	}
+	if err := writeScript(path, content, mode); err != nil {
+		return false, fmt.Errorf("failed to uninstall %s hook: %w", event, err)
+	}
+	return true, nil
+}
+
+// readScript returns a hook script and its permissions, or "" if it doesn't
+// exist
+func readScript(path string) (string, fs.FileMode, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", 0o644, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read hook: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read hook: %w", err)
+	}
+	return string(data), info.Mode().Perm(), nil
+}
+
+// writeScript replaces the hook script at path, so git never runs one that is
+// half written
+func writeScript(path, content string, mode fs.FileMode) error {
+	tmp := path + ".axe.tmp"
+	if err := os.WriteFile(tmp, []byte(content), mode); err != nil {
+		return err
+	}
+	// WriteFile doesn't change the mode of a file left behind before
+	if err := os.Chmod(tmp, mode); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// withBlock adds block to a hook script right after its shebang, giving a
+// script without one the shebang git would run it with
+func withBlock(content, block string) string {
+	if content == "" {
+		return "#!/bin/sh\n\n" + block
+	}
+	shebang, rest := "", content
+	if strings.HasPrefix(content, "#!") {
+		line, after, _ := strings.Cut(content, "\n")
+		shebang, rest = line+"\n\n", strings.TrimLeft(after, "\n")
+	}
+	if rest == "" {
+		return shebang + block
+	}
+	return shebang + block + "\n" + rest
+}
+
+// withoutBlock removes the axe block from a hook script, along with the
+// blank lines withBlock put around it. It reports whether there was one.
+func withoutBlock(content string) (string, bool) {
+	before, after, ok := cutBlock(content)
+	if !ok {
+		return content, false
+	}
+	content = strings.TrimRight(before, "\n")
+	if rest := strings.TrimLeft(after, "\n"); rest != "" {
+		switch {
+		case content == "":
+		case isEmptyScript(content):
+			// The block sat right after the shebang
+			content += "\n"
+		default:
+			// The block was appended, as older versions did
+			content += "\n\n"
+		}
+		content += rest
+	} else if content != "" {
+		content += "\n"
+	}
+	return content, true
+}
+
+// cutBlock splits a hook script around its axe block
+func cutBlock(content string) (before, after string, ok bool) {
+	start := strings.Index(content, beginMarker+"\n")
+	if start < 0 {
+		return content, "", false
+	}
+	end := strings.Index(content[start:], endMarker)
+	if end < 0 {
+		return content, "", false
+	}
+	end += start + len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start], content[end:], true
+}
+
+// isShellScript reports whether the axe block can be added to a script,
+// which needs a POSIX shell to run it
+func isShellScript(content string) bool {
+	first, _, _ := strings.Cut(content, "\n")
+	if !strings.HasPrefix(first, "#!") {
+		// git runs scripts without a shebang with sh
+		return true
+	}
+	fields := strings.Fields(strings.TrimPrefix(first, "#!"))
+	if len(fields) == 0 {
+		return true
+	}
+	shell := filepath.Base(fields[0])
+	if shell == "env" && len(fields) > 1 {
+		shell = fields[1]
+	}
+	return slices.Contains([]string{"sh", "bash", "dash", "zsh", "ksh"}, shell)
+}
+
+// isEmptyScript reports whether a hook script runs nothing but a shebang
+func isEmptyScript(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#!") {
+			return false
+		}
+	}
+	return true
+}
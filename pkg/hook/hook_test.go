@@ -0,0 +1,256 @@
+package hook
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     []string
+	}{
+		{
+			name: "creates the script",
+			want: []string{"#!/bin/sh\n\n" + beginMarker, "AXE_HOOK=1 axe branches --quiet || true"},
+		},
+		{
+			name:     "goes right after the shebang of an existing script",
+			existing: "#!/bin/bash\nnpm install\n",
+			want:     []string{"#!/bin/bash\n\n" + beginMarker, endMarker + "\n\nnpm install\n"},
+		},
+		{
+			name:     "moves a block appended by older versions",
+			existing: "#!/bin/sh\nnpm install\n\n" + block(EventPostMerge, "axe branches --quiet"),
+			want:     []string{"#!/bin/sh\n\n" + beginMarker, endMarker + "\n\nnpm install\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, EventPostMerge)
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			changed, err := Install(dir, EventPostMerge, "axe branches --quiet")
+			if err != nil || !changed {
+				t.Fatalf("Install() = %v, %v, want changed", changed, err)
+			}
+			data, _ := os.ReadFile(path)
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("hook = %q, want it to contain %q", data, want)
+				}
+			}
+			if info, _ := os.Stat(path); info.Mode().Perm()&0o111 != 0o111 {
+				t.Errorf("hook mode = %v, want executable", info.Mode())
+			}
+
+			// Installing again changes nothing
+			if changed, err := Install(dir, EventPostMerge, "axe branches --quiet"); err != nil || changed {
+				t.Errorf("Install() again = %v, %v, want unchanged", changed, err)
+			}
+			again, _ := os.ReadFile(path)
+			if string(again) != string(data) {
+				t.Errorf("Install() again rewrote the hook to %q", again)
+			}
+		})
+	}
+}
+
+func TestInstall_ReplacesPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, EventPostMerge)
+	os.WriteFile(path, []byte("#!/bin/sh\necho before\n"), 0o755)
+
+	if _, err := Install(dir, EventPostMerge, "axe branches --quiet"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(path, append(mustRead(t, path), "echo after\n"...), 0o755)
+	if _, err := Install(dir, EventPostMerge, "axe chop --force --no-color"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(mustRead(t, path))
+	if strings.Count(got, beginMarker) != 1 || strings.Contains(got, "--quiet") || !strings.Contains(got, "axe chop") {
+		t.Errorf("hook = %q, want one block running axe chop", got)
+	}
+	if !strings.HasSuffix(got, endMarker+"\n\necho before\necho after\n") {
+		t.Errorf("hook = %q, want the script's own lines kept", got)
+	}
+}
+
+func TestInstall_ScriptEndsWithExec(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	// A fake axe records that it ran
+	bin := t.TempDir()
+	marker := filepath.Join(bin, "ran")
+	os.WriteFile(filepath.Join(bin, "axe"), []byte("#!/bin/sh\ntouch "+marker+"\n"), 0o755)
+
+	// The script hands its stdin to another program and never returns
+	dir := t.TempDir()
+	path := filepath.Join(dir, EventReferenceTransaction)
+	seen := filepath.Join(dir, "seen")
+	os.WriteFile(path, []byte("#!/bin/sh\nexec cat >"+seen+"\n"), 0o755)
+	if _, err := Install(dir, EventReferenceTransaction, "axe branches --quiet"); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := "0 1 refs/remotes/origin/main\n"
+	cmd := exec.Command("sh", path, "committed")
+	cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"), "AXE_HOOK=")
+	cmd.Stdin = strings.NewReader(refs)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("hook failed: %v: %s", err, output)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("axe didn't run before the script's exec")
+	}
+	if got := string(mustRead(t, seen)); got != refs {
+		t.Errorf("script read %q from stdin, want %q", got, refs)
+	}
+}
+
+func TestInstall_NotShell(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, EventPostCheckout)
+	os.WriteFile(path, []byte("#!/usr/bin/env python3\nprint('hi')\n"), 0o755)
+
+	if _, err := Install(dir, EventPostCheckout, "axe branches --quiet"); err == nil {
+		t.Error("Install() into a python hook = nil, want error")
+	}
+	if got := string(mustRead(t, path)); got != "#!/usr/bin/env python3\nprint('hi')\n" {
+		t.Errorf("Install() changed a python hook to %q", got)
+	}
+}
+
+func TestUninstall(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string // "" means the script is removed
+	}{
+		{name: "removes a script axe created"},
+		{name: "keeps an existing script", existing: "#!/bin/sh\nnpm install\n", want: "#!/bin/sh\nnpm install\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, EventPostMerge)
+			if tt.existing != "" {
+				os.WriteFile(path, []byte(tt.existing), 0o755)
+			}
+			if _, err := Install(dir, EventPostMerge, "axe branches --quiet"); err != nil {
+				t.Fatal(err)
+			}
+
+			if changed, err := Uninstall(dir, EventPostMerge); err != nil || !changed {
+				t.Fatalf("Uninstall() = %v, %v, want changed", changed, err)
+			}
+			data, err := os.ReadFile(path)
+			if tt.want == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("Uninstall() left %q behind", data)
+				}
+			} else if string(data) != tt.want {
+				t.Errorf("hook = %q, want %q", data, tt.want)
+			}
+
+			if changed, err := Uninstall(dir, EventPostMerge); err != nil || changed {
+				t.Errorf("Uninstall() again = %v, %v, want unchanged", changed, err)
+			}
+		})
+	}
+}
+
+func TestScript_Guards(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	// A fake axe records that it ran
+	bin := t.TempDir()
+	marker := filepath.Join(bin, "ran")
+	os.WriteFile(filepath.Join(bin, "axe"), []byte("#!/bin/sh\ntouch "+marker+"\n"), 0o755)
+
+	tests := []struct {
+		name  string
+		event string
+		args  []string
+		stdin string
+		env   string
+		want  bool
+	}{
+		{name: "post-merge", event: EventPostMerge, args: []string{"0"}, want: true},
+		{name: "branch checkout", event: EventPostCheckout, args: []string{"a", "b", "1"}, want: true},
+		{name: "file checkout", event: EventPostCheckout, args: []string{"a", "b", "0"}},
+		{name: "fetch", event: EventReferenceTransaction, args: []string{"committed"}, stdin: "0 1 refs/remotes/origin/main\n", want: true},
+		{name: "local commit", event: EventReferenceTransaction, args: []string{"committed"}, stdin: "0 1 refs/heads/main\n"},
+		{name: "prepared fetch", event: EventReferenceTransaction, args: []string{"prepared"}, stdin: "0 1 refs/remotes/origin/main\n"},
+		{name: "run by axe", event: EventPostMerge, args: []string{"0"}, env: "AXE_HOOK=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(marker)
+			cmd := exec.Command("sh", append([]string{"-c", block(tt.event, "axe branches --quiet"), "hook"}, tt.args...)...)
+			cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"), "AXE_HOOK=")
+			if tt.env != "" {
+				cmd.Env = append(cmd.Env, tt.env)
+			}
+			cmd.Stdin = strings.NewReader(tt.stdin)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("hook failed: %v: %s", err, output)
+			}
+			if _, err := os.Stat(marker); (err == nil) != tt.want {
+				t.Errorf("axe ran = %v, want %v", err == nil, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	hooks := filepath.Join(root, ".git", "hooks")
+	if got := Detect(root, hooks); got != "" {
+		t.Errorf("Detect() = %q, want none", got)
+	}
+	if got := Detect(root, filepath.Join(root, ".husky", "_")); got != ManagerHusky {
+		t.Errorf("Detect() with core.hooksPath .husky/_ = %q, want husky", got)
+	}
+
+	os.WriteFile(filepath.Join(root, "lefthook.yml"), nil, 0o644)
+	if got := Detect(root, hooks); got != ManagerLefthook {
+		t.Errorf("Detect() with lefthook.yml = %q, want lefthook", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	file, snippet := Snippet(ManagerLefthook, EventPostCheckout, "axe branches --quiet")
+	if file != "lefthook.yml" || !strings.Contains(snippet, `run: '[ "{3}" = 1 ] && AXE_HOOK=1 axe branches --quiet || true'`) {
+		t.Errorf("Snippet(lefthook) = %q, %q", file, snippet)
+	}
+	file, snippet = Snippet(ManagerHusky, EventPostMerge, "axe branches --quiet")
+	if file != filepath.Join(".husky", "post-merge") || strings.Contains(snippet, beginMarker) {
+		t.Errorf("Snippet(husky) = %q, %q", file, snippet)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -3,9 +3,11 @@ package output
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/git"
 	"github.com/nikzadkhani/axe/pkg/github"
 )
 
@@ -23,10 +25,15 @@ type Formatter interface {
 	PrintBranch(branch string)
 	// PrintBranchWithPR prints a branch with PR info
 	PrintBranchWithPR(branch string, pr *github.PRInfo)
+	// PrintMergedBranch prints a merged branch with its PR, if any, and how
+	// it was merged
+	PrintMergedBranch(mb branch.MergedBranch)
 	// PrintHeader prints a header message
 	PrintHeader(msg string)
 	// PrintBranchStatuses prints branches grouped by status
 	PrintBranchStatuses(statusMap map[string][]branch.BranchStatus)
+	// PrintStaleBranch prints a stale branch with its last commit details
+	PrintStaleBranch(sb branch.StaleBranch)
 }
 
 // ColoredFormatter implements Formatter with colored output
@@ -75,6 +82,22 @@ func (f *ColoredFormatter) PrintBranchWithPR(branch string, pr *github.PRInfo) {
 		cyan(pr.Title))
 }
 
+func (f *ColoredFormatter) PrintMergedBranch(mb branch.MergedBranch) {
+	green := color.New(color.FgGreen, color.Bold).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	fmt.Fprintf(f.writer, "  %s", green(mb.Name))
+	if marker := checkoutMarker(mb.Branch); marker != "" {
+		fmt.Fprintf(f.writer, " %s", dim(marker))
+	}
+	if mb.PR != nil {
+		fmt.Fprintf(f.writer, " %s %s", yellow(fmt.Sprintf("(PR #%d)", mb.PR.Number)), cyan(mb.PR.Title))
+	}
+	fmt.Fprintf(f.writer, " %s\n", dim(mergedDetails(mb)))
+}
+
 func (f *ColoredFormatter) PrintHeader(msg string) {
 	bold := color.New(color.Bold).SprintFunc()
 	fmt.Fprintf(f.writer, "\n%s\n", bold(msg))
@@ -83,16 +106,22 @@ func (f *ColoredFormatter) PrintHeader(msg string) {
 func (f *ColoredFormatter) PrintBranchStatuses(statusMap map[string][]branch.BranchStatus) {
 	// Define status order and formatting
 	statusInfo := []struct {
-		key    string
-		emoji  string
-		color  func(a ...interface{}) string
-		label  string
+		key   string
+		emoji string
+		color func(a ...interface{}) string
+		label string
 	}{
 		{"merged", "🪓", color.New(color.FgGreen, color.Bold).SprintFunc(), "Merged (ready to axe)"},
+		{"merged-into-other", "🔀", color.New(color.FgBlue).SprintFunc(), "Merged into another branch (kept)"},
+		{"merged-not-pulled", "⬇️", color.New(color.FgBlue).SprintFunc(), "Merged but not pulled (kept)"},
+		{"merged-reverted", "↩️", color.New(color.FgYellow, color.Bold).SprintFunc(), "Merged, then REVERTED (kept)"},
+		{"queued", "⏳", color.New(color.FgCyan, color.Bold).SprintFunc(), "Queued to merge (kept)"},
 		{"open", "📂", color.New(color.FgCyan).SprintFunc(), "Open PR"},
 		{"draft", "✏️", color.New(color.FgMagenta).SprintFunc(), "Draft PR"},
 		{"closed", "❌", color.New(color.FgRed).SprintFunc(), "Closed (not merged)"},
+		{"gone", "👻", color.New(color.FgBlue).SprintFunc(), "Upstream gone (no PR found)"},
 		{"no-pr", "🔍", color.New(color.FgYellow).SprintFunc(), "No PR"},
+		{"kept", "🛡️", color.New(color.FgGreen).SprintFunc(), "Kept (never chopped)"},
 	}
 
 	for _, info := range statusInfo {
@@ -109,21 +138,65 @@ func (f *ColoredFormatter) PrintBranchStatuses(statusMap map[string][]branch.Bra
 			len(branches))
 
 		// Print branches
+		dim := color.New(color.Faint).SprintFunc()
 		for _, b := range branches {
+			name := info.color(b.Name)
+			if marker := checkoutMarker(b.Branch); marker != "" {
+				name += " " + dim(marker)
+			}
+			if b.Kept != "" {
+				name += " " + dim("["+b.Kept+"]")
+			}
 			if b.PR != nil {
 				yellow := color.New(color.FgYellow).SprintFunc()
-				dim := color.New(color.Faint).SprintFunc()
-				fmt.Fprintf(f.writer, "  %s %s %s\n",
-					info.color(b.Name),
-					yellow(fmt.Sprintf("(#%d)", b.PR.Number)),
+				fmt.Fprintf(f.writer, "  %s %s %s",
+					name,
+					yellow(fmt.Sprintf("(%s)", prRef(b.PR))),
 					dim(b.PR.Title))
+				if activity := prActivity(info.key, b.PR); len(activity) > 0 {
+					fmt.Fprintf(f.writer, " %s", f.colorActivity(activity))
+				}
+				fmt.Fprintln(f.writer)
 			} else {
-				fmt.Fprintf(f.writer, "  %s\n", info.color(b.Name))
+				fmt.Fprintf(f.writer, "  %s\n", name)
 			}
 		}
 	}
 }
 
+// colorActivity highlights what a PR is waiting on: green when it can be
+// merged, red when it's blocked
+func (f *ColoredFormatter) colorActivity(activity []string) string {
+	parts := make([]string, len(activity))
+	for i, a := range activity {
+		switch a {
+		case "approved", "checks " + github.ChecksPassing:
+			parts[i] = color.New(color.FgGreen).Sprint(a)
+		case "changes requested", "checks " + github.ChecksFailing, "conflicts":
+			parts[i] = color.New(color.FgRed).Sprint(a)
+		default:
+			parts[i] = color.New(color.Faint).Sprint(a)
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func (f *ColoredFormatter) PrintStaleBranch(sb branch.StaleBranch) {
+	yellow := color.New(color.FgYellow, color.Bold).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fmt.Fprintf(f.writer, "  %s", yellow(sb.Name))
+	if marker := checkoutMarker(sb.Branch); marker != "" {
+		fmt.Fprintf(f.writer, " %s", dim(marker))
+	}
+	fmt.Fprintf(f.writer, " %s", dim(staleDetails(sb)))
+	if sb.HasUnpushedWork() {
+		fmt.Fprintf(f.writer, " %s", red(fmt.Sprintf("[%d unpushed]", sb.UnpushedCommits)))
+	}
+	fmt.Fprintln(f.writer)
+}
+
 // PlainFormatter implements Formatter with plain text output
 type PlainFormatter struct {
 	writer io.Writer
@@ -158,6 +231,17 @@ func (f *PlainFormatter) PrintBranchWithPR(branch string, pr *github.PRInfo) {
 	fmt.Fprintf(f.writer, "  %s (PR #%d: %s)\n", branch, pr.Number, pr.Title)
 }
 
+func (f *PlainFormatter) PrintMergedBranch(mb branch.MergedBranch) {
+	fmt.Fprintf(f.writer, "  %s", mb.Name)
+	if marker := checkoutMarker(mb.Branch); marker != "" {
+		fmt.Fprintf(f.writer, " %s", marker)
+	}
+	if mb.PR != nil {
+		fmt.Fprintf(f.writer, " (PR #%d: %s)", mb.PR.Number, mb.PR.Title)
+	}
+	fmt.Fprintf(f.writer, " %s\n", mergedDetails(mb))
+}
+
 func (f *PlainFormatter) PrintHeader(msg string) {
 	fmt.Fprintf(f.writer, "\n%s\n", msg)
 }
@@ -170,10 +254,16 @@ func (f *PlainFormatter) PrintBranchStatuses(statusMap map[string][]branch.Branc
 		label string
 	}{
 		{"merged", "🪓", "Merged (ready to axe)"},
+		{"merged-into-other", "🔀", "Merged into another branch (kept)"},
+		{"merged-not-pulled", "⬇️", "Merged but not pulled (kept)"},
+		{"merged-reverted", "↩️", "Merged, then REVERTED (kept)"},
+		{"queued", "⏳", "Queued to merge (kept)"},
 		{"open", "📂", "Open PR"},
 		{"draft", "✏️", "Draft PR"},
 		{"closed", "❌", "Closed (not merged)"},
+		{"gone", "👻", "Upstream gone (no PR found)"},
 		{"no-pr", "🔍", "No PR"},
+		{"kept", "🛡️", "Kept (never chopped)"},
 	}
 
 	for _, info := range statusInfo {
@@ -190,15 +280,116 @@ func (f *PlainFormatter) PrintBranchStatuses(statusMap map[string][]branch.Branc
 
 		// Print branches
 		for _, b := range branches {
+			name := b.Name
+			if marker := checkoutMarker(b.Branch); marker != "" {
+				name += " " + marker
+			}
+			if b.Kept != "" {
+				name += " [" + b.Kept + "]"
+			}
 			if b.PR != nil {
-				fmt.Fprintf(f.writer, "  %s (#%d: %s)\n",
-					b.Name,
-					b.PR.Number,
+				fmt.Fprintf(f.writer, "  %s (%s: %s)",
+					name,
+					prRef(b.PR),
 					b.PR.Title)
+				if activity := prActivity(info.key, b.PR); len(activity) > 0 {
+					fmt.Fprintf(f.writer, " [%s]", strings.Join(activity, ", "))
+				}
+				fmt.Fprintln(f.writer)
 			} else {
-				fmt.Fprintf(f.writer, "  %s\n", b.Name)
+				fmt.Fprintf(f.writer, "  %s\n", name)
 			}
 		}
 	}
 }
 
+func (f *PlainFormatter) PrintStaleBranch(sb branch.StaleBranch) {
+	fmt.Fprintf(f.writer, "  %s", sb.Name)
+	if marker := checkoutMarker(sb.Branch); marker != "" {
+		fmt.Fprintf(f.writer, " %s", marker)
+	}
+	fmt.Fprintf(f.writer, " %s", staleDetails(sb))
+	if sb.HasUnpushedWork() {
+		fmt.Fprintf(f.writer, " [%d unpushed]", sb.UnpushedCommits)
+	}
+	fmt.Fprintln(f.writer)
+}
+
+// checkoutMarker notes where a branch is checked out, since git refuses to
+// delete it there. It is empty for branches that aren't checked out.
+func checkoutMarker(b git.Branch) string {
+	switch {
+	case b.IsHead:
+		return "(checked out)"
+	case b.WorktreePath != "":
+		return fmt.Sprintf("(checked out in %s)", b.WorktreePath)
+	default:
+		return ""
+	}
+}
+
+// prRef refers to a PR by number, naming its base branch when it's known and
+// how it's queued to merge
+func prRef(pr *github.PRInfo) string {
+	ref := fmt.Sprintf("#%d", pr.Number)
+	if pr.BaseRefName != "" {
+		ref += " into " + pr.BaseRefName
+	}
+	switch {
+	case !pr.Queued():
+	case pr.InMergeQueue:
+		ref += ", in merge queue"
+	case pr.AutoMergeRequest.MergeMethod != "":
+		ref += fmt.Sprintf(", auto-merge (%s)", strings.ToLower(pr.AutoMergeRequest.MergeMethod))
+	default:
+		ref += ", auto-merge"
+	}
+	return ref
+}
+
+// prActivity describes the review, checks, mergeability and last update of
+// PRs that are still open, for the sections listing them
+func prActivity(status string, pr *github.PRInfo) []string {
+	if status != "open" && status != "draft" && status != "queued" {
+		return nil
+	}
+	var activity []string
+	switch pr.ReviewDecision {
+	case "APPROVED":
+		activity = append(activity, "approved")
+	case "CHANGES_REQUESTED":
+		activity = append(activity, "changes requested")
+	case "REVIEW_REQUIRED":
+		activity = append(activity, "review required")
+	}
+	if checks := pr.CheckState(); checks != "" {
+		activity = append(activity, "checks "+checks)
+	}
+	switch pr.Mergeable {
+	case "MERGEABLE":
+		activity = append(activity, "mergeable")
+	case "CONFLICTING":
+		activity = append(activity, "conflicts")
+	}
+	if !pr.UpdatedAt.IsZero() {
+		activity = append(activity, "updated "+pr.UpdatedAt.Format("2006-01-02"))
+	}
+	return activity
+}
+
+// staleDetails describes a stale branch's last commit and unique commits
+func staleDetails(sb branch.StaleBranch) string {
+	return fmt.Sprintf("(last commit %s by %s, %d unique commit(s))",
+		sb.CommitDate.Format("2006-01-02"),
+		sb.Author,
+		sb.UniqueCommits)
+}
+
+// mergedDetails describes how a branch was merged and the evidence for it
+func mergedDetails(mb branch.MergedBranch) string {
+	var evidence []string
+	for _, e := range mb.Evidence {
+		evidence = append(evidence, e.Detail)
+	}
+	return fmt.Sprintf("[merged_by: %s; %s]", mb.MergedBy, strings.Join(evidence, ", "))
+}
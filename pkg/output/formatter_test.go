@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/git"
 	"github.com/nikzadkhani/axe/pkg/github"
 )
 
@@ -145,19 +147,19 @@ func TestColoredFormatter_PrintBranchStatuses(t *testing.T) {
 
 	statusMap := map[string][]branch.BranchStatus{
 		"merged": {
-			{Name: "merged-branch", Status: "merged", PR: &github.PRInfo{Number: 1, Title: "Merged PR"}},
+			{Branch: git.Branch{Name: "merged-branch"}, Status: "merged", PR: &github.PRInfo{Number: 1, Title: "Merged PR"}},
 		},
 		"open": {
-			{Name: "open-branch", Status: "open", PR: &github.PRInfo{Number: 2, Title: "Open PR"}},
+			{Branch: git.Branch{Name: "open-branch"}, Status: "open", PR: &github.PRInfo{Number: 2, Title: "Open PR"}},
 		},
 		"draft": {
-			{Name: "draft-branch", Status: "draft", PR: &github.PRInfo{Number: 3, Title: "Draft PR"}},
+			{Branch: git.Branch{Name: "draft-branch"}, Status: "draft", PR: &github.PRInfo{Number: 3, Title: "Draft PR"}},
 		},
 		"closed": {
-			{Name: "closed-branch", Status: "closed", PR: &github.PRInfo{Number: 4, Title: "Closed PR"}},
+			{Branch: git.Branch{Name: "closed-branch"}, Status: "closed", PR: &github.PRInfo{Number: 4, Title: "Closed PR"}},
 		},
 		"no-pr": {
-			{Name: "no-pr-branch", Status: "no-pr", PR: nil},
+			{Branch: git.Branch{Name: "no-pr-branch"}, Status: "no-pr", PR: nil},
 		},
 	}
 
@@ -191,16 +193,35 @@ func TestColoredFormatter_PrintBranchStatuses(t *testing.T) {
 	}
 }
 
+func TestPlainFormatter_PrintBranchStatuses_MergedIntoOther(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewPlainFormatter(buf)
+
+	formatter.PrintBranchStatuses(map[string][]branch.BranchStatus{
+		"merged-into-other": {
+			{Branch: git.Branch{Name: "stacked"}, Status: "merged-into-other", PR: &github.PRInfo{Number: 7, Title: "Stacked PR", BaseRefName: "parent"}},
+		},
+	})
+
+	output := buf.String()
+	if !strings.Contains(output, "Merged into another branch (kept): 1 branch(es)") {
+		t.Errorf("PrintBranchStatuses() output is missing the merged-into-other section:\n%s", output)
+	}
+	if !strings.Contains(output, "stacked (#7 into parent: Stacked PR)") {
+		t.Errorf("PrintBranchStatuses() output should name the base branch:\n%s", output)
+	}
+}
+
 func TestPlainFormatter_PrintBranchStatuses(t *testing.T) {
 	buf := &bytes.Buffer{}
 	formatter := NewPlainFormatter(buf)
 
 	statusMap := map[string][]branch.BranchStatus{
 		"merged": {
-			{Name: "merged-branch", Status: "merged", PR: &github.PRInfo{Number: 1, Title: "Merged PR"}},
+			{Branch: git.Branch{Name: "merged-branch"}, Status: "merged", PR: &github.PRInfo{Number: 1, Title: "Merged PR"}},
 		},
 		"no-pr": {
-			{Name: "no-pr-branch", Status: "no-pr", PR: nil},
+			{Branch: git.Branch{Name: "no-pr-branch"}, Status: "no-pr", PR: nil},
 		},
 	}
 
@@ -232,3 +253,116 @@ func TestPlainFormatter_PrintBranchStatuses(t *testing.T) {
 		t.Errorf("PrintBranchStatuses() output should contain PR title 'Merged PR'")
 	}
 }
+
+func TestPlainFormatter_PrintStaleBranch(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewPlainFormatter(buf)
+
+	formatter.PrintStaleBranch(branch.StaleBranch{
+		Branch:          git.Branch{Name: "experiment", Author: "Dana", CommitDate: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), WorktreePath: "/src/experiment"},
+		UniqueCommits:   4,
+		UnpushedCommits: 2,
+	})
+
+	output := buf.String()
+	for _, want := range []string{"experiment", "2024-03-01", "Dana", "4 unique commit(s)", "[2 unpushed]", "(checked out in /src/experiment)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("PrintStaleBranch() output = %q, want it to contain %q", output, want)
+		}
+	}
+}
+
+func TestPlainFormatter_PrintMergedBranch(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewPlainFormatter(buf)
+
+	formatter.PrintMergedBranch(branch.MergedBranch{
+		Branch:   git.Branch{Name: "feature/rebased", IsHead: true},
+		Evidence: []branch.Evidence{{Kind: branch.StrategyRebase, Detail: "all commits applied to main"}},
+		MergedBy: "rebase",
+	})
+
+	output := buf.String()
+	for _, want := range []string{"feature/rebased", "merged_by: rebase", "all commits applied to main", "(checked out)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("PrintMergedBranch() output = %q, want it to contain %q", output, want)
+		}
+	}
+	if strings.Contains(output, "PR #") {
+		t.Errorf("PrintMergedBranch() output = %q, want no PR for a locally detected merge", output)
+	}
+}
+
+func TestPlainFormatter_PrintBranchStatuses_Queued(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewPlainFormatter(buf)
+
+	formatter.PrintBranchStatuses(map[string][]branch.BranchStatus{
+		"queued": {
+			{Branch: git.Branch{Name: "in-queue"}, Status: "queued", PR: &github.PRInfo{Number: 7, State: "OPEN", Title: "Queued", BaseRefName: "main", InMergeQueue: true}},
+			{Branch: git.Branch{Name: "auto"}, Status: "queued", PR: &github.PRInfo{Number: 8, State: "OPEN", Title: "Auto", AutoMergeRequest: &github.AutoMergeRequest{MergeMethod: "SQUASH"}}},
+		},
+	})
+
+	output := buf.String()
+	if !strings.Contains(output, "Queued to merge (kept): 2 branch(es)") {
+		t.Errorf("PrintBranchStatuses() output is missing the queued section:\n%s", output)
+	}
+	if !strings.Contains(output, "in-queue (#7 into main, in merge queue: Queued)") {
+		t.Errorf("PrintBranchStatuses() output should mention the merge queue:\n%s", output)
+	}
+	if !strings.Contains(output, "auto (#8, auto-merge (squash): Auto)") {
+		t.Errorf("PrintBranchStatuses() output should mention auto-merge:\n%s", output)
+	}
+}
+
+func TestPlainFormatter_PrintBranchStatuses_OpenActivity(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewPlainFormatter(buf)
+
+	formatter.PrintBranchStatuses(map[string][]branch.BranchStatus{
+		"open": {
+			{Branch: git.Branch{Name: "ready"}, Status: "open", PR: &github.PRInfo{
+				Number:         9,
+				State:          "OPEN",
+				Title:          "Ready",
+				ReviewDecision: "APPROVED",
+				Checks:         []github.Check{{Status: "COMPLETED", Conclusion: "SUCCESS"}},
+				Mergeable:      "MERGEABLE",
+				UpdatedAt:      time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+			}},
+			{Branch: git.Branch{Name: "bare"}, Status: "open", PR: &github.PRInfo{Number: 10, State: "OPEN", Title: "Bare"}},
+		},
+	})
+
+	output := buf.String()
+	if !strings.Contains(output, "ready (#9: Ready) [approved, checks passing, mergeable, updated 2026-10-16]") {
+		t.Errorf("PrintBranchStatuses() output should describe the open PR:\n%s", output)
+	}
+	if !strings.Contains(output, "bare (#10: Bare)\n") {
+		t.Errorf("PrintBranchStatuses() output should leave out empty details:\n%s", output)
+	}
+}
+
+func TestPlainFormatter_PrintBranchStatuses_Kept(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewPlainFormatter(buf)
+
+	formatter.PrintBranchStatuses(map[string][]branch.BranchStatus{
+		"kept": {
+			{Branch: git.Branch{Name: "backport"}, Status: "kept", Kept: "PR #4 is labeled keep-branch", PR: &github.PRInfo{Number: 4, State: "MERGED", Title: "Backport base"}},
+			{Branch: git.Branch{Name: "marked"}, Status: "kept", Kept: "kept with axe keep"},
+		},
+	})
+
+	output := buf.String()
+	if !strings.Contains(output, "Kept (never chopped): 2 branch(es)") {
+		t.Errorf("PrintBranchStatuses() output is missing the kept section:\n%s", output)
+	}
+	if !strings.Contains(output, "backport [PR #4 is labeled keep-branch] (#4: Backport base)") {
+		t.Errorf("PrintBranchStatuses() output should give the reason:\n%s", output)
+	}
+	if !strings.Contains(output, "marked [kept with axe keep]\n") {
+		t.Errorf("PrintBranchStatuses() output should give the reason without a PR:\n%s", output)
+	}
+}
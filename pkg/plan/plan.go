@@ -0,0 +1,187 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// fileVersion is bumped whenever the plan format changes incompatibly
+const fileVersion = 1
+
+// Actions say what applying a plan does to a branch
+const (
+	// ActionDelete deletes the local branch
+	ActionDelete = "delete"
+	// ActionKeep leaves the branch alone. Reviewers can set it to take a
+	// branch out of a plan without regenerating it.
+	ActionKeep = "keep"
+)
+
+// Plan is a reviewed list of branches to chop. It records the state every
+// branch was in when the plan was made, so applying it later only acts on
+// branches that haven't changed since.
+type Plan struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Remote is the URL of the origin remote, identifying the repository
+	Remote   string  `json:"remote,omitempty"`
+	Branches []Entry `json:"branches"`
+}
+
+// Entry is a branch in a plan and what to do with it
+type Entry struct {
+	Branch   string            `json:"branch"`
+	SHA      string            `json:"sha"`
+	Action   string            `json:"action"`
+	MergedBy string            `json:"merged_by,omitempty"`
+	PR       *github.PRInfo    `json:"pr,omitempty"`
+	Evidence []branch.Evidence `json:"evidence,omitempty"`
+}
+
+// New creates a plan that deletes the merged branches
+func New(remote string, merged []branch.MergedBranch, now time.Time) *Plan {
+	p := &Plan{Version: fileVersion, CreatedAt: now, Remote: remote, Branches: []Entry{}}
+	for _, mb := range merged {
+		p.Branches = append(p.Branches, Entry{
+			Branch:   mb.Name,
+			SHA:      mb.SHA,
+			Action:   ActionDelete,
+			MergedBy: mb.MergedBy,
+			PR:       mb.PR,
+			Evidence: mb.Evidence,
+		})
+	}
+	return p
+}
+
+// Load reads the plan at path
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if p.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported plan version %d in %s (expected %d)", p.Version, path, fileVersion)
+	}
+	return &p, nil
+}
+
+// Save writes the plan to path
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// CheckRemote fails if the plan was made for another repository. Plans
+// without a remote, or repositories without one, can't be checked.
+func (p *Plan) CheckRemote(remote string) error {
+	if p.Remote == "" || remote == "" || p.Remote == remote {
+		return nil
+	}
+	return fmt.Errorf("plan was made for %s, not %s", p.Remote, remote)
+}
+
+// Verify checks the plan against the repository as it is now and returns
+// the branches that are safe to delete, carrying their recorded SHAs so the
+// deletion is guarded by them. A branch is rejected if it moved or is gone,
+// or if its PR changed: a different PR, a different state, or a new PR on a
+// branch that was only detected locally. PRs are not checked when prs is
+// nil; lookupErrs holds the branches whose PR couldn't be looked up.
+func (p *Plan) Verify(current []git.Branch, prs map[string]*github.PRInfo, lookupErrs map[string]error) (ready []git.Branch, rejected []branch.DeleteFailure) {
+	byName := make(map[string]git.Branch, len(current))
+	for _, b := range current {
+		byName[b.Name] = b
+	}
+
+	reject := func(e Entry, reason string) {
+		rejected = append(rejected, branch.DeleteFailure{Branch: e.Branch, Reason: reason})
+	}
+	for _, e := range p.Branches {
+		if e.Action == ActionKeep {
+			continue
+		}
+		if e.Action != ActionDelete {
+			reject(e, fmt.Sprintf("unknown action %q", e.Action))
+			continue
+		}
+
+		b, ok := byName[e.Branch]
+		switch {
+		case !ok:
+			reject(e, "no longer exists")
+			continue
+		case b.SHA != e.SHA:
+			reject(e, fmt.Sprintf("moved from %s to %s since the plan was made", git.ShortSHA(e.SHA), git.ShortSHA(b.SHA)))
+			continue
+		}
+
+		if prs != nil {
+			if err, failed := lookupErrs[e.Branch]; failed {
+				reject(e, fmt.Sprintf("couldn't check its PR: %v", err))
+				continue
+			}
+			if reason := prChange(e.PR, prs[e.Branch]); reason != "" {
+				reject(e, reason)
+				continue
+			}
+		}
+
+		ready = append(ready, b)
+	}
+	return ready, rejected
+}
+
+// prChange describes how a branch's PR changed since the plan was made, or
+// returns "" if it didn't
+func prChange(planned, current *github.PRInfo) string {
+	switch {
+	case planned == nil && current == nil:
+		return ""
+	case planned == nil:
+		// Branches merged without a PR only matter if work reopened there
+		if current.State == "MERGED" {
+			return ""
+		}
+		return fmt.Sprintf("now has %s PR #%d", prState(current), current.Number)
+	case current == nil:
+		return fmt.Sprintf("PR #%d can no longer be found", planned.Number)
+	case current.Number != planned.Number:
+		return fmt.Sprintf("PR is now #%d (%s), was #%d (%s)", current.Number, prState(current), planned.Number, prState(planned))
+	case current.State != planned.State || current.IsDraft != planned.IsDraft:
+		return fmt.Sprintf("PR #%d is now %s, was %s", current.Number, prState(current), prState(planned))
+	}
+	return ""
+}
+
+// prState names a PR's state the way GitHub shows it
+func prState(pr *github.PRInfo) string {
+	if pr.IsDraft {
+		return "draft"
+	}
+	switch pr.State {
+	case "MERGED":
+		return "merged"
+	case "OPEN":
+		return "open"
+	case "CLOSED":
+		return "closed"
+	}
+	return pr.State
+}
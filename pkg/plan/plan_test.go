@@ -0,0 +1,123 @@
+package plan
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/branch"
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+func TestPlan_SaveLoad(t *testing.T) {
+	merged := []branch.MergedBranch{
+		{
+			Branch:   git.Branch{Name: "feature/done", SHA: "abc123"},
+			PR:       &github.PRInfo{Number: 7, State: "MERGED", Title: "Done"},
+			Evidence: []branch.Evidence{{Kind: branch.StrategyPR, Detail: "PR #7 merged"}},
+			MergedBy: "squash",
+		},
+	}
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	p := New("git@github.com:org/repo.git", merged, created)
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := p.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, p) {
+		t.Errorf("Load() = %+v, want %+v", loaded, p)
+	}
+	if loaded.Branches[0].Action != ActionDelete {
+		t.Errorf("Action = %q, want %q", loaded.Branches[0].Action, ActionDelete)
+	}
+}
+
+func TestLoad_RejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	p := New("", nil, time.Now())
+	p.Version = fileVersion + 1
+	if err := p.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of a newer plan = nil, want error")
+	}
+}
+
+func TestPlan_CheckRemote(t *testing.T) {
+	p := &Plan{Remote: "git@github.com:org/repo.git"}
+	if err := p.CheckRemote("git@github.com:org/repo.git"); err != nil {
+		t.Errorf("CheckRemote(same) = %v, want nil", err)
+	}
+	if err := p.CheckRemote(""); err != nil {
+		t.Errorf("CheckRemote(none) = %v, want nil", err)
+	}
+	if err := p.CheckRemote("git@github.com:org/other.git"); err == nil {
+		t.Error("CheckRemote(other) = nil, want error")
+	}
+}
+
+func TestPlan_Verify(t *testing.T) {
+	merged := &github.PRInfo{Number: 1, State: "MERGED"}
+	p := &Plan{Branches: []Entry{
+		{Branch: "ready", SHA: "aaa", Action: ActionDelete, PR: merged},
+		{Branch: "kept", SHA: "bbb", Action: ActionKeep},
+		{Branch: "moved", SHA: "ccc", Action: ActionDelete},
+		{Branch: "gone", SHA: "ddd", Action: ActionDelete},
+		{Branch: "reopened", SHA: "eee", Action: ActionDelete, PR: &github.PRInfo{Number: 2, State: "MERGED"}},
+		{Branch: "new-pr", SHA: "fff", Action: ActionDelete},
+		{Branch: "unchecked", SHA: "ggg", Action: ActionDelete, PR: &github.PRInfo{Number: 3, State: "MERGED"}},
+		{Branch: "odd", SHA: "hhh", Action: "rename"},
+	}}
+	current := []git.Branch{
+		{Name: "ready", SHA: "aaa"},
+		{Name: "kept", SHA: "bbb"},
+		{Name: "moved", SHA: "ccc999999"},
+		{Name: "reopened", SHA: "eee"},
+		{Name: "new-pr", SHA: "fff"},
+		{Name: "unchecked", SHA: "ggg"},
+		{Name: "odd", SHA: "hhh"},
+	}
+	prs := map[string]*github.PRInfo{
+		"ready":    merged,
+		"reopened": {Number: 2, State: "OPEN"},
+		"new-pr":   {Number: 4, State: "OPEN"},
+	}
+	lookupErrs := map[string]error{"unchecked": errors.New("rate limited")}
+
+	ready, rejected := p.Verify(current, prs, lookupErrs)
+
+	wantReady := []git.Branch{{Name: "ready", SHA: "aaa"}}
+	if !reflect.DeepEqual(ready, wantReady) {
+		t.Errorf("Verify() ready = %+v, want %+v", ready, wantReady)
+	}
+	wantRejected := []branch.DeleteFailure{
+		{Branch: "moved", Reason: "moved from ccc to ccc9999 since the plan was made"},
+		{Branch: "gone", Reason: "no longer exists"},
+		{Branch: "reopened", Reason: "PR #2 is now open, was merged"},
+		{Branch: "new-pr", Reason: "now has open PR #4"},
+		{Branch: "unchecked", Reason: "couldn't check its PR: rate limited"},
+		{Branch: "odd", Reason: `unknown action "rename"`},
+	}
+	if !reflect.DeepEqual(rejected, wantRejected) {
+		t.Errorf("Verify() rejected = %+v, want %+v", rejected, wantRejected)
+	}
+}
+
+func TestPlan_VerifyOffline(t *testing.T) {
+	p := &Plan{Branches: []Entry{
+		{Branch: "ready", SHA: "aaa", Action: ActionDelete, PR: &github.PRInfo{Number: 1, State: "MERGED"}},
+	}}
+	ready, rejected := p.Verify([]git.Branch{{Name: "ready", SHA: "aaa"}}, nil, nil)
+	if len(ready) != 1 || len(rejected) != 0 {
+		t.Errorf("Verify() without PRs = %+v, %+v, want the branch ready", ready, rejected)
+	}
+}
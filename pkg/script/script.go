@@ -0,0 +1,172 @@
+package script
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// Shells that scripts can be written for
+const (
+	ShellBash       = "bash"
+	ShellFish       = "fish"
+	ShellPowerShell = "powershell"
+)
+
+// Shells lists every supported shell
+var Shells = []string{ShellBash, ShellFish, ShellPowerShell}
+
+// zeroSHA as the old value makes update-ref refuse to overwrite a ref
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// Deletion is a branch the script deletes
+type Deletion struct {
+	git.Branch
+	// PR is the branch's PR, shown in a comment
+	PR *github.PRInfo
+	// MergedBy names how the branch was merged, or is empty for unmerged
+	// branches
+	MergedBy string
+	// RecoveryRef, when set, is created at the branch tip before the branch
+	// is deleted
+	RecoveryRef string
+}
+
+// ValidateShell checks that scripts can be written for shell
+func ValidateShell(shell string) error {
+	if slices.Contains(Shells, shell) {
+		return nil
+	}
+	return fmt.Errorf("invalid shell %q (valid shells: %s)", shell, strings.Join(Shells, ", "))
+}
+
+// Write writes a script of the git commands that delete the branches in
+// repoPath. Every deletion is guarded by the SHA the branch was scanned at,
+// and the script stops at the first command that fails. Branches that are
+// checked out are listed in a comment but not deleted.
+func Write(w io.Writer, shell, repoPath string, deletions []Deletion, now time.Time) error {
+	s, err := newWriter(w, shell)
+	if err != nil {
+		return err
+	}
+
+	s.line(s.shebang)
+	s.comment(fmt.Sprintf("Written by axe on %s. Review it before running it.", now.Format(time.RFC1123)))
+	s.comment("Each branch is only deleted if it still points at the commit axe saw.")
+	if s.prelude != "" {
+		s.line(s.prelude)
+	}
+	s.line("")
+	s.chdir(repoPath)
+
+	for _, d := range deletions {
+		s.line("")
+		s.comment(describe(d))
+		if d.WorktreePath != "" || d.IsHead {
+			s.comment("Skipped: the branch is checked out")
+			continue
+		}
+		if d.RecoveryRef != "" {
+			s.command("git", "update-ref", d.RecoveryRef, d.SHA, zeroSHA)
+		}
+		s.command("git", "update-ref", "-d", "refs/heads/"+d.Name, d.SHA)
+	}
+	return s.err
+}
+
+// describe summarizes a deletion for its comment
+func describe(d Deletion) string {
+	var b strings.Builder
+	b.WriteString(d.Name)
+	if d.PR != nil {
+		fmt.Fprintf(&b, ": PR #%d %s", d.PR.Number, d.PR.Title)
+	}
+	switch {
+	case d.MergedBy != "":
+		fmt.Fprintf(&b, " (merged by %s)", d.MergedBy)
+	case d.RecoveryRef != "":
+		b.WriteString(" (not merged, saved to " + d.RecoveryRef + ")")
+	}
+	return b.String()
+}
+
+// writer writes the lines of a script in one shell's syntax
+type writer struct {
+	w       io.Writer
+	err     error
+	shebang string
+	prelude string
+	// quote quotes an argument that isn't a plain word
+	quote func(string) string
+	// check follows a git command to stop the script when it fails
+	check string
+	// chdirCheck does the same for cd, which isn't a native command in
+	// PowerShell and fails through $ErrorActionPreference instead
+	chdirCheck string
+}
+
+func newWriter(w io.Writer, shell string) (*writer, error) {
+	switch shell {
+	case ShellBash:
+		return &writer{w: w, shebang: "#!/usr/bin/env bash", prelude: "set -euo pipefail", quote: quoteSingle}, nil
+	case ShellFish:
+		return &writer{w: w, shebang: "#!/usr/bin/env fish", quote: quoteFish, check: "; or exit 1", chdirCheck: "; or exit 1"}, nil
+	case ShellPowerShell:
+		return &writer{w: w, shebang: "#!/usr/bin/env pwsh", prelude: "$ErrorActionPreference = 'Stop'", quote: quotePowerShell, check: "; if ($LASTEXITCODE -ne 0) { exit 1 }"}, nil
+	}
+	return nil, ValidateShell(shell)
+}
+
+func (s *writer) line(text string) {
+	if s.err == nil {
+		_, s.err = fmt.Fprintln(s.w, text)
+	}
+}
+
+// comment writes text as a single-line comment, which starts with # in
+// every supported shell
+func (s *writer) comment(text string) {
+	s.line("# " + strings.Join(strings.Fields(text), " "))
+}
+
+func (s *writer) command(args ...string) {
+	s.line(s.join(args) + s.check)
+}
+
+func (s *writer) chdir(dir string) {
+	s.line(s.join([]string{"cd", dir}) + s.chdirCheck)
+}
+
+// join quotes the arguments that need it and joins them into a command line
+func (s *writer) join(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if !plainWord.MatchString(arg) {
+			quoted[i] = s.quote(arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// plainWord matches arguments that mean the same unquoted in every shell
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_./-]+$`)
+
+func quoteSingle(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteFish(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func quotePowerShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
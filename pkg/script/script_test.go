@@ -0,0 +1,161 @@
+package script
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+var scriptTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestWrite(t *testing.T) {
+	deletions := []Deletion{
+		{
+			Branch:   git.Branch{Name: "feature/login", SHA: "1111111111111111111111111111111111111111"},
+			PR:       &github.PRInfo{Number: 12, Title: "Fix\nlogin"},
+			MergedBy: "squash",
+		},
+		{
+			Branch:      git.Branch{Name: "it's", SHA: "2222222222222222222222222222222222222222"},
+			RecoveryRef: "refs/axe/recovery/20240301-120000/it's",
+		},
+		{
+			Branch:   git.Branch{Name: "here", SHA: "3333333333333333333333333333333333333333", WorktreePath: "/src/wt"},
+			MergedBy: "merge",
+		},
+	}
+
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{
+			shell: ShellBash,
+			want: []string{
+				"#!/usr/bin/env bash",
+				"set -euo pipefail",
+				"cd '/src/my repo'",
+				"# feature/login: PR #12 Fix login (merged by squash)",
+				"git update-ref -d refs/heads/feature/login 1111111111111111111111111111111111111111",
+				`git update-ref 'refs/axe/recovery/20240301-120000/it'\''s' 2222222222222222222222222222222222222222 0000000000000000000000000000000000000000`,
+				`git update-ref -d 'refs/heads/it'\''s' 2222222222222222222222222222222222222222`,
+				"# Skipped: the branch is checked out",
+			},
+		},
+		{
+			shell: ShellFish,
+			want: []string{
+				"#!/usr/bin/env fish",
+				"cd '/src/my repo'; or exit 1",
+				"git update-ref -d refs/heads/feature/login 1111111111111111111111111111111111111111; or exit 1",
+				`git update-ref -d 'refs/heads/it\'s' 2222222222222222222222222222222222222222; or exit 1`,
+			},
+		},
+		{
+			shell: ShellPowerShell,
+			want: []string{
+				"$ErrorActionPreference = 'Stop'",
+				"cd '/src/my repo'\n",
+				"git update-ref -d refs/heads/feature/login 1111111111111111111111111111111111111111; if ($LASTEXITCODE -ne 0) { exit 1 }",
+				"git update-ref -d 'refs/heads/it''s' 2222222222222222222222222222222222222222; if ($LASTEXITCODE -ne 0) { exit 1 }",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.shell, "/src/my repo", deletions, scriptTime); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Write() script is missing %q:\n%s", want, got)
+				}
+			}
+			if strings.Contains(got, "refs/heads/here") {
+				t.Errorf("Write() deletes a checked out branch:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestWrite_InvalidShell(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "tcsh", ".", nil, scriptTime); err == nil {
+		t.Error("Write(tcsh) = nil, want error")
+	}
+}
+
+func TestWrite_BashScriptRuns(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(name string, args ...string) (string, error) {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL="+os.DevNull,
+			"GIT_AUTHOR_NAME=axe", "GIT_AUTHOR_EMAIL=axe@example.com",
+			"GIT_COMMITTER_NAME=axe", "GIT_COMMITTER_EMAIL=axe@example.com")
+		output, err := cmd.CombinedOutput()
+		return strings.TrimSpace(string(output)), err
+	}
+	mustRun := func(name string, args ...string) string {
+		t.Helper()
+		output, err := run(name, args...)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n%s", name, strings.Join(args, " "), err, output)
+		}
+		return output
+	}
+
+	mustRun("git", "init", "--quiet", "-b", "main")
+	mustRun("git", "commit", "--quiet", "--allow-empty", "-m", "init")
+	mustRun("git", "branch", "done")
+	mustRun("git", "branch", "moved")
+	sha := mustRun("git", "rev-parse", "main")
+
+	write := func(deletions []Deletion) string {
+		var buf bytes.Buffer
+		if err := Write(&buf, ShellBash, dir, deletions, scriptTime); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "chop.sh")
+		if err := os.WriteFile(path, buf.Bytes(), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// A branch that moved since the scan stops the script
+	mustRun("git", "commit", "--quiet", "--allow-empty", "-m", "more")
+	mustRun("git", "branch", "-f", "moved", "HEAD")
+	stale := write([]Deletion{{Branch: git.Branch{Name: "moved", SHA: sha}}, {Branch: git.Branch{Name: "done", SHA: sha}}})
+	if _, err := run("bash", stale); err == nil {
+		t.Error("script deleting a moved branch succeeded, want failure")
+	}
+	if got := mustRun("git", "branch", "--list", "done", "moved"); !strings.Contains(got, "done") || !strings.Contains(got, "moved") {
+		t.Errorf("failed script deleted branches, left %q", got)
+	}
+
+	ok := write([]Deletion{{Branch: git.Branch{Name: "done", SHA: sha}, RecoveryRef: "refs/axe/recovery/20240301-120000/done"}})
+	mustRun("bash", ok)
+	if got := mustRun("git", "branch", "--list", "done"); got != "" {
+		t.Errorf("script left the branch behind: %q", got)
+	}
+	if got := mustRun("git", "rev-parse", "refs/axe/recovery/20240301-120000/done"); got != sha {
+		t.Errorf("recovery ref = %s, want %s", got, sha)
+	}
+}
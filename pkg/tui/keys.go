@@ -0,0 +1,78 @@
+package tui
+
+import "unicode/utf8"
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keySpace
+	keyEnter
+	keyBackspace
+	keyEscape
+	keyCtrlA
+	keyCtrlC
+	keyUnknown
+)
+
+// key is a decoded key press
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// parseKeys decodes raw terminal input into key presses. A lone escape byte
+// is the Escape key, while escape sequences encode arrow keys.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch {
+		case b[0] == 0x1b:
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				switch b[2] {
+				case 'A':
+					keys = append(keys, key{kind: keyUp})
+				case 'B':
+					keys = append(keys, key{kind: keyDown})
+				default:
+					keys = append(keys, key{kind: keyUnknown})
+				}
+				b = b[3:]
+				continue
+			}
+			keys = append(keys, key{kind: keyEscape})
+			b = b[1:]
+		case b[0] == 0x01:
+			keys = append(keys, key{kind: keyCtrlA})
+			b = b[1:]
+		case b[0] == 0x03:
+			keys = append(keys, key{kind: keyCtrlC})
+			b = b[1:]
+		case b[0] == 0x0e: // Ctrl-N
+			keys = append(keys, key{kind: keyDown})
+			b = b[1:]
+		case b[0] == 0x10: // Ctrl-P
+			keys = append(keys, key{kind: keyUp})
+			b = b[1:]
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, key{kind: keyEnter})
+			b = b[1:]
+		case b[0] == 0x7f || b[0] == 0x08:
+			keys = append(keys, key{kind: keyBackspace})
+			b = b[1:]
+		case b[0] == ' ':
+			keys = append(keys, key{kind: keySpace})
+			b = b[1:]
+		case b[0] < 0x20:
+			keys = append(keys, key{kind: keyUnknown})
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, key{kind: keyRune, r: r})
+			b = b[size:]
+		}
+	}
+	return keys
+}
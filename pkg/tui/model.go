@@ -0,0 +1,353 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+// Item is a branch offered for selection
+type Item struct {
+	Name       string
+	Status     string
+	PR         *github.PRInfo
+	LastCommit time.Time
+	// Selected is the initial selection state
+	Selected bool
+}
+
+// CommitLoader returns the commits shown in the details pane for a branch
+type CommitLoader func(branch string) ([]git.Commit, error)
+
+type screen int
+
+const (
+	screenList screen = iota
+	screenConfirm
+)
+
+// detailsHeight is the number of lines reserved for the details pane,
+// including its title
+const detailsHeight = 8
+
+// model holds the picker state independently of the terminal
+type model struct {
+	items    []Item
+	selected []bool
+	filter   string
+	visible  []int // indexes into items that match the filter
+	cursor   int   // index into visible
+	offset   int   // first visible row shown in the list
+	screen   screen
+
+	done      bool
+	confirmed bool
+
+	now     time.Time
+	load    CommitLoader
+	commits map[string][]git.Commit
+	errs    map[string]error
+}
+
+// newModel creates a model with each item's initial selection
+func newModel(items []Item, load CommitLoader, now time.Time) *model {
+	m := &model{
+		items:    items,
+		selected: make([]bool, len(items)),
+		now:      now,
+		load:     load,
+		commits:  make(map[string][]git.Commit),
+		errs:     make(map[string]error),
+	}
+	for i, item := range items {
+		m.selected[i] = item.Selected
+	}
+	m.applyFilter()
+	return m
+}
+
+// selectedNames returns the names of selected items in their original order
+func (m *model) selectedNames() []string {
+	var names []string
+	for i, item := range m.items {
+		if m.selected[i] {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// current returns the item under the cursor, if any
+func (m *model) current() (Item, bool) {
+	if len(m.visible) == 0 {
+		return Item{}, false
+	}
+	return m.items[m.visible[m.cursor]], true
+}
+
+// applyFilter recomputes the visible items after the filter changed
+func (m *model) applyFilter() {
+	m.visible = m.visible[:0]
+	needle := strings.ToLower(m.filter)
+	for i, item := range m.items {
+		haystack := strings.ToLower(item.Name)
+		if item.PR != nil {
+			haystack += " " + strings.ToLower(item.PR.Title) + fmt.Sprintf(" #%d", item.PR.Number)
+		}
+		if strings.Contains(haystack, needle) {
+			m.visible = append(m.visible, i)
+		}
+	}
+	m.cursor = min(m.cursor, max(0, len(m.visible)-1))
+	m.offset = 0
+}
+
+// handleKey updates the state for a single key press
+func (m *model) handleKey(k key) {
+	if k.kind == keyCtrlC {
+		m.done = true
+		return
+	}
+
+	if m.screen == screenConfirm {
+		switch {
+		case k.kind == keyRune && (k.r == 'y' || k.r == 'Y'), k.kind == keyEnter:
+			m.done = true
+			m.confirmed = true
+		case k.kind == keyRune && (k.r == 'n' || k.r == 'N'), k.kind == keyEscape:
+			m.screen = screenList
+		}
+		return
+	}
+
+	switch k.kind {
+	case keyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case keyDown:
+		if m.cursor < len(m.visible)-1 {
+			m.cursor++
+		}
+	case keySpace:
+		if len(m.visible) > 0 {
+			idx := m.visible[m.cursor]
+			m.selected[idx] = !m.selected[idx]
+		}
+	case keyCtrlA:
+		m.toggleAllVisible()
+	case keyBackspace:
+		if m.filter != "" {
+			r := []rune(m.filter)
+			m.filter = string(r[:len(r)-1])
+			m.applyFilter()
+		}
+	case keyRune:
+		m.filter += string(k.r)
+		m.applyFilter()
+	case keyEnter:
+		if len(m.selectedNames()) > 0 {
+			m.screen = screenConfirm
+		}
+	case keyEscape:
+		if m.filter != "" {
+			m.filter = ""
+			m.applyFilter()
+		} else {
+			m.done = true
+		}
+	}
+}
+
+// toggleAllVisible selects every visible item, or deselects them all if they
+// are already selected
+func (m *model) toggleAllVisible() {
+	allSelected := true
+	for _, idx := range m.visible {
+		if !m.selected[idx] {
+			allSelected = false
+			break
+		}
+	}
+	for _, idx := range m.visible {
+		m.selected[idx] = !allSelected
+	}
+}
+
+// loadCurrent fetches commits for the item under the cursor once
+func (m *model) loadCurrent() {
+	item, ok := m.current()
+	if !ok || m.load == nil {
+		return
+	}
+	if _, loaded := m.commits[item.Name]; loaded {
+		return
+	}
+	if _, failed := m.errs[item.Name]; failed {
+		return
+	}
+	commits, err := m.load(item.Name)
+	if err != nil {
+		m.errs[item.Name] = err
+		return
+	}
+	m.commits[item.Name] = commits
+}
+
+// render draws the current screen for a terminal of the given size
+func (m *model) render(width, height int) string {
+	var lines []string
+	if m.screen == screenConfirm {
+		lines = m.renderConfirm(height)
+	} else {
+		lines = m.renderList(height)
+	}
+
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(truncate(line, width))
+	}
+	return b.String()
+}
+
+func (m *model) renderList(height int) []string {
+	selected := len(m.selectedNames())
+	lines := []string{
+		fmt.Sprintf("🪓 Select branches to chop (%d of %d selected)", selected, len(m.items)),
+		fmt.Sprintf("Filter: %s", m.filter),
+		"",
+	}
+
+	// Keep the cursor inside the scrolled window
+	listHeight := max(1, height-len(lines)-detailsHeight-2)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+
+	for row := 0; row < listHeight; row++ {
+		pos := m.offset + row
+		if pos >= len(m.visible) {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, m.renderItem(pos))
+	}
+	if len(m.visible) == 0 {
+		lines[3] = "  (no branches match the filter)"
+	}
+
+	lines = append(lines, m.renderDetails()...)
+	lines = append(lines, "", "↑/↓ move · space toggle · ctrl-a toggle all · type to filter · enter chop · esc cancel")
+	return lines
+}
+
+func (m *model) renderItem(pos int) string {
+	idx := m.visible[pos]
+	item := m.items[idx]
+
+	pointer := "  "
+	if pos == m.cursor {
+		pointer = "> "
+	}
+	check := "[ ]"
+	if m.selected[idx] {
+		check = "[x]"
+	}
+
+	line := fmt.Sprintf("%s%s %s", pointer, check, item.Name)
+	if item.PR != nil {
+		line += fmt.Sprintf("  #%d %s", item.PR.Number, item.PR.Title)
+	}
+	if !item.LastCommit.IsZero() {
+		line += fmt.Sprintf("  %s ago", FormatAge(m.now.Sub(item.LastCommit)))
+	}
+	if item.Status != "" {
+		line += fmt.Sprintf("  [%s]", item.Status)
+	}
+	return line
+}
+
+func (m *model) renderDetails() []string {
+	lines := make([]string, 0, detailsHeight)
+	item, ok := m.current()
+	if !ok {
+		lines = append(lines, "")
+	} else {
+		lines = append(lines, fmt.Sprintf("── Commits on %s ──", item.Name))
+		switch {
+		case m.errs[item.Name] != nil:
+			lines = append(lines, fmt.Sprintf("  failed to load commits: %v", m.errs[item.Name]))
+		case len(m.commits[item.Name]) == 0:
+			lines = append(lines, "  (no commits outside the default branch)")
+		default:
+			for _, c := range m.commits[item.Name] {
+				if len(lines) == detailsHeight {
+					break
+				}
+				lines = append(lines, fmt.Sprintf("  %s %s (%s, %s ago)", git.ShortSHA(c.SHA), c.Subject, c.Author, FormatAge(m.now.Sub(c.Date))))
+			}
+		}
+	}
+	for len(lines) < detailsHeight {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func (m *model) renderConfirm(height int) []string {
+	names := m.selectedNames()
+	lines := []string{
+		fmt.Sprintf("🪓 Chop these %d branch(es)?", len(names)),
+		"",
+	}
+	limit := max(1, height-len(lines)-2)
+	for i, name := range names {
+		if i == limit-1 && len(names) > limit {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(names)-i))
+			break
+		}
+		lines = append(lines, "  "+name)
+	}
+	lines = append(lines, "", "y/enter chop · n/esc go back · ctrl-c cancel")
+	return lines
+}
+
+// FormatAge renders a duration compactly, e.g. 45m, 3h, 12d, 4mo, 2y
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 60*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo", int(d.Hours()/24/30))
+	default:
+		return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+	}
+}
+
+// truncate cuts a line to at most width runes
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width == 1 {
+		return string(r[:1])
+	}
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikzadkhani/axe/pkg/git"
+	"github.com/nikzadkhani/axe/pkg/github"
+)
+
+var testNow = time.Unix(1700000000, 0)
+
+func testItems() []Item {
+	return []Item{
+		{Name: "feature/login", Status: "merged", PR: &github.PRInfo{Number: 1, Title: "Add login"}, LastCommit: testNow.Add(-3 * 24 * time.Hour), Selected: true},
+		{Name: "bugfix/typo", Status: "merged", PR: &github.PRInfo{Number: 2, Title: "Fix typo"}, Selected: true},
+		{Name: "feature/logout", Status: "merged", PR: &github.PRInfo{Number: 3, Title: "Add logout"}, Selected: true},
+		{Name: "feature/abandoned", Status: "closed", PR: &github.PRInfo{Number: 4, Title: "Abandoned idea"}},
+	}
+}
+
+func press(m *model, keys ...key) {
+	for _, k := range keys {
+		m.handleKey(k)
+	}
+}
+
+func typeText(m *model, text string) {
+	for _, r := range text {
+		m.handleKey(key{kind: keyRune, r: r})
+	}
+}
+
+func TestModel_StartsWithInitialSelection(t *testing.T) {
+	m := newModel(testItems(), nil, testNow)
+
+	want := []string{"feature/login", "bugfix/typo", "feature/logout"}
+	if got := m.selectedNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("selectedNames() = %v, want %v", got, want)
+	}
+}
+
+func TestModel_ToggleAndNavigate(t *testing.T) {
+	m := newModel(testItems(), nil, testNow)
+
+	press(m, key{kind: keySpace}, key{kind: keyDown}, key{kind: keyDown}, key{kind: keySpace}, key{kind: keyDown}, key{kind: keySpace}, key{kind: keyDown})
+
+	want := []string{"bugfix/typo", "feature/abandoned"}
+	if got := m.selectedNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("selectedNames() = %v, want %v", got, want)
+	}
+	if m.cursor != 3 {
+		t.Errorf("cursor = %d, want 3 (clamped at last item)", m.cursor)
+	}
+}
+
+func TestModel_FilterByTyping(t *testing.T) {
+	m := newModel(testItems(), nil, testNow)
+
+	typeText(m, "log")
+	if len(m.visible) != 2 {
+		t.Fatalf("visible = %d items after filter, want 2", len(m.visible))
+	}
+
+	// Select-all toggles only the visible items
+	press(m, key{kind: keyCtrlA})
+	if got := m.selectedNames(); !reflect.DeepEqual(got, []string{"bugfix/typo"}) {
+		t.Errorf("selectedNames() = %v, want [bugfix/typo]", got)
+	}
+
+	// Filters also match PR titles and numbers
+	press(m, key{kind: keyBackspace}, key{kind: keyBackspace}, key{kind: keyBackspace})
+	typeText(m, "#2")
+	if item, _ := m.current(); len(m.visible) != 1 || item.Name != "bugfix/typo" {
+		t.Errorf("filter #2 matched %d items, want only bugfix/typo", len(m.visible))
+	}
+
+	// Escape clears the filter before cancelling
+	press(m, key{kind: keyEscape})
+	if m.filter != "" || len(m.visible) != 4 || m.done {
+		t.Errorf("escape with filter: filter = %q, visible = %d, done = %v", m.filter, len(m.visible), m.done)
+	}
+}
+
+func TestModel_ConfirmFlow(t *testing.T) {
+	m := newModel(testItems(), nil, testNow)
+
+	press(m, key{kind: keyEnter})
+	if m.screen != screenConfirm {
+		t.Fatal("enter did not open the confirmation screen")
+	}
+
+	press(m, key{kind: keyRune, r: 'n'})
+	if m.screen != screenList || m.done {
+		t.Fatal("n did not return to the list")
+	}
+
+	press(m, key{kind: keyEnter}, key{kind: keyRune, r: 'y'})
+	if !m.done || !m.confirmed {
+		t.Errorf("y on confirmation: done = %v, confirmed = %v", m.done, m.confirmed)
+	}
+}
+
+func TestModel_EnterRequiresSelection(t *testing.T) {
+	m := newModel(testItems(), nil, testNow)
+
+	press(m, key{kind: keyCtrlA}, key{kind: keyCtrlA}, key{kind: keyEnter})
+	if m.screen != screenList {
+		t.Error("enter with nothing selected opened the confirmation screen")
+	}
+}
+
+func TestModel_CancelWithEscape(t *testing.T) {
+	m := newModel(testItems(), nil, testNow)
+
+	press(m, key{kind: keyEscape})
+	if !m.done || m.confirmed {
+		t.Errorf("escape: done = %v, confirmed = %v, want cancelled", m.done, m.confirmed)
+	}
+}
+
+func TestModel_RenderShowsDetails(t *testing.T) {
+	loads := 0
+	load := func(branch string) ([]git.Commit, error) {
+		loads++
+		return []git.Commit{{SHA: "0123456789", Subject: "Wire up login form", Author: "Dana", Date: testNow.Add(-time.Hour)}}, nil
+	}
+	m := newModel(testItems(), load, testNow)
+
+	m.loadCurrent()
+	m.loadCurrent()
+	out := m.render(120, 30)
+
+	for _, want := range []string{"3 of 4 selected", "[x] feature/login", "#1 Add login", "3d ago", "0123456 Wire up login form", "Commits on feature/login"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("render() missing %q in:\n%s", want, out)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("commits loaded %d times, want 1", loads)
+	}
+}
+
+func TestModel_RenderLoadError(t *testing.T) {
+	load := func(branch string) ([]git.Commit, error) {
+		return nil, errors.New("bad revision")
+	}
+	m := newModel(testItems(), load, testNow)
+
+	m.loadCurrent()
+	if out := m.render(120, 30); !strings.Contains(out, "failed to load commits: bad revision") {
+		t.Errorf("render() = %q, want load error", out)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("\x1b[A\x1b[Bx \r\x7f\x1b\x01\x03é"))
+	want := []key{
+		{kind: keyUp}, {kind: keyDown}, {kind: keyRune, r: 'x'}, {kind: keySpace},
+		{kind: keyEnter}, {kind: keyBackspace}, {kind: keyEscape}, {kind: keyCtrlA},
+		{kind: keyCtrlC}, {kind: keyRune, r: 'é'},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Minute, "30m"},
+		{5 * time.Hour, "5h"},
+		{12 * 24 * time.Hour, "12d"},
+		{120 * 24 * time.Hour, "4mo"},
+		{800 * 24 * time.Hour, "2y"},
+	}
+	for _, tt := range tests {
+		if got := FormatAge(tt.d); got != tt.want {
+			t.Errorf("FormatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Terminal control sequences
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// Picker is a full-screen terminal picker for choosing branches to chop
+type Picker struct {
+	items []Item
+	load  CommitLoader
+}
+
+// NewPicker creates a new Picker. load is called lazily for the branch under
+// the cursor to fill the details pane.
+func NewPicker(items []Item, load CommitLoader) *Picker {
+	return &Picker{items: items, load: load}
+}
+
+// IsTerminal reports whether f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Run shows the picker until the user confirms or cancels. It returns the
+// selected branch names and whether the user confirmed the selection.
+func (p *Picker) Run(in *os.File, out io.Writer) ([]string, bool, error) {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to start interactive mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Fprint(out, enterAltScreen+hideCursor)
+	defer fmt.Fprint(out, showCursor+exitAltScreen)
+
+	m := newModel(p.items, p.load, time.Now())
+	buf := make([]byte, 64)
+	for !m.done {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+
+		m.loadCurrent()
+		fmt.Fprint(out, clearScreen+m.render(width, height))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read input: %w", err)
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			m.handleKey(k)
+			if m.done {
+				break
+			}
+		}
+	}
+
+	if !m.confirmed {
+		return nil, false, nil
+	}
+	return m.selectedNames(), true, nil
+}
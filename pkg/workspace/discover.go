@@ -0,0 +1,142 @@
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Options controls repository discovery
+type Options struct {
+	// MaxDepth is how many directory levels below the root are searched.
+	// The root itself is depth 0.
+	MaxDepth int
+	// Ignore holds glob patterns matched against directory names and paths
+	// relative to the root. Matching directories are not searched.
+	Ignore []string
+	// IncludeSubmodules also returns submodules nested inside repositories
+	IncludeSubmodules bool
+}
+
+// DefaultOptions returns the discovery settings used by the CLI
+func DefaultOptions() Options {
+	return Options{
+		MaxDepth: 3,
+		Ignore:   []string{"node_modules"},
+	}
+}
+
+// Discover returns the paths of git repositories at or beneath root, sorted.
+// Repositories are not searched for further nested repositories unless
+// submodules are requested, and linked worktrees are skipped since they share
+// branches with their main repository.
+func Discover(root string, opts Options) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+
+	var repos []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than aborting the scan
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if d.Name() == ".git" || (path != root && isIgnored(d.Name(), rel, opts.Ignore)) {
+			return fs.SkipDir
+		}
+
+		// Submodules and worktrees are only returned when asked for, or when
+		// the search starts at one
+		kind := repoKind(path)
+		switch {
+		case kind == repository,
+			kind == submodule && opts.IncludeSubmodules,
+			kind != notRepo && path == root:
+			repos = append(repos, path)
+		}
+
+		if kind != notRepo && !opts.IncludeSubmodules {
+			return fs.SkipDir
+		}
+		if depth(rel) >= opts.MaxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+
+	sort.Strings(repos)
+	return repos, nil
+}
+
+type kind int
+
+const (
+	notRepo kind = iota
+	repository
+	submodule
+	worktree
+)
+
+// repoKind classifies a directory by its .git entry. A .git directory marks a
+// repository, while a .git file points at the real git directory of a
+// submodule or linked worktree.
+func repoKind(dir string) kind {
+	gitPath := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return notRepo
+	}
+	if info.IsDir() {
+		return repository
+	}
+
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return notRepo
+	}
+	gitDir := filepath.ToSlash(strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:")))
+	switch {
+	case strings.Contains(gitDir, "/modules/"):
+		return submodule
+	case strings.Contains(gitDir, "/worktrees/"):
+		return worktree
+	default:
+		return repository
+	}
+}
+
+// isIgnored reports whether a directory matches any ignore pattern
+func isIgnored(name, rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// depth returns the number of path elements in a path relative to the root
+func depth(rel string) int {
+	if rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
@@ -0,0 +1,117 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// layout creates directories (trailing slash) and files beneath root
+func layout(t *testing.T, root string, entries map[string]string) {
+	t.Helper()
+	for path, content := range entries {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if content == "" && path[len(path)-1] == '/' {
+			if err := os.MkdirAll(full, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func relPaths(t *testing.T, root string, paths []string) []string {
+	t.Helper()
+	var rel []string
+	for _, p := range paths {
+		r, err := filepath.Rel(root, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return rel
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	layout(t, root, map[string]string{
+		"api/.git/":                  "",
+		"api/vendor/lib/.git":        "gitdir: ../../.git/modules/lib\n",
+		"web/.git/":                  "",
+		"web/node_modules/pkg/.git/": "",
+		"org/tools/.git/":            "",
+		"org/deep/er/still/.git/":    "",
+		"api-wt/.git":                "gitdir: /src/api/.git/worktrees/api-wt\n",
+		"notes/readme.txt":           "not a repo",
+		"archive/old/.git/":          "",
+	})
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "default options",
+			opts: DefaultOptions(),
+			want: []string{"api", "archive/old", "org/tools", "web"},
+		},
+		{
+			name: "max depth limits search",
+			opts: Options{MaxDepth: 1},
+			want: []string{"api", "web"},
+		},
+		{
+			name: "deeper search",
+			opts: Options{MaxDepth: 4, Ignore: []string{"node_modules"}},
+			want: []string{"api", "archive/old", "org/deep/er/still", "org/tools", "web"},
+		},
+		{
+			name: "ignore patterns",
+			opts: Options{MaxDepth: 3, Ignore: []string{"node_modules", "archive"}},
+			want: []string{"api", "org/tools", "web"},
+		},
+		{
+			name: "submodules when asked",
+			opts: Options{MaxDepth: 3, Ignore: []string{"node_modules"}, IncludeSubmodules: true},
+			want: []string{"api", "api/vendor/lib", "archive/old", "org/tools", "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, err := Discover(root, tt.opts)
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			if got := relPaths(t, root, repos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Discover() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscover_RootIsRepository(t *testing.T) {
+	root := t.TempDir()
+	layout(t, root, map[string]string{
+		".git/":        "",
+		"nested/.git/": "",
+		"src/main.go":  "package main",
+	})
+
+	repos, err := Discover(root, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if got := relPaths(t, root, repos); !reflect.DeepEqual(got, []string{"."}) {
+		t.Errorf("Discover() = %v, want [.]", got)
+	}
+}